@@ -26,6 +26,72 @@ const (
 	PhaseRunning = "RUNNING"
 )
 
+const (
+	// Set to "true" to pause a running upgrade, remove it or set it to "false" to resume
+	UpgradePauseAnnotation = "opster.io/pause-upgrade"
+	// Set to the version being upgraded to in order to approve the canary step of an upgrade
+	UpgradeCanaryApprovalAnnotation = "opster.io/approve-canary"
+	// Set to "true" to pause all reconciliation of the cluster or to a comma separated list of components
+	PauseReconcileAnnotation = "opster.io/pause-reconcile"
+	// Set to a new value, e.g. the current date, to rotate the passwords generated by the operator
+	RotatePasswordsAnnotation = "opster.io/rotate-passwords"
+)
+
+// Components of the cluster whose reconciliation can be paused
+const (
+	PauseAll            = "all"
+	PauseTLS            = "tls"
+	PauseSecurityconfig = "securityconfig"
+	PauseConfiguration  = "configuration"
+	PauseCluster        = "cluster"
+	PauseScaler         = "scaler"
+	PauseDashboards     = "dashboards"
+	PauseUpgrade        = "upgrade"
+	PauseRestart        = "restart"
+	// Users, roles and role bindings of the cluster
+	PauseSecurity = "security"
+	// Cluster operations of the cluster
+	PauseOperations = "operations"
+	// Replication rules of the cluster
+	PauseReplication = "replication"
+	// Remote cluster connections of the cluster
+	PauseRemoteClusters = "remoteclusters"
+	// Scheduled backups of the security config
+	PauseSecurityconfigBackup = "securityconfigbackup"
+)
+
+const (
+	DeferredRestart        = "Restart"
+	DeferredUpgrade        = "Upgrade"
+	DeferredScaleDown      = "ScaleDown"
+	DeferredRemoveNodePool = "RemoveNodePool"
+)
+
+const (
+	UpgradeInProgress = "InProgress"
+	UpgradeSucceeded  = "Succeeded"
+	UpgradeFailed     = "Failed"
+	UpgradeRolledBack = "RolledBack"
+)
+
+const (
+	ConditionUpgrading         = "Upgrading"
+	ConditionUpgradeCanary     = "UpgradeCanary"
+	ConditionUpgradePreflight  = "UpgradePreflight"
+	ConditionVersionUpdate     = "VersionUpdate"
+	ConditionPaused            = "Paused"
+	ConditionPluginsCompatible = "PluginsCompatible"
+	// The securityconfig of the spec was applied successfully
+	ConditionSecurityconfigApplied = "SecurityConfigApplied"
+	// The last scheduled backup of the security config succeeded
+	ConditionSecurityconfigBackup = "SecurityConfigBackedUp"
+	// The statefulsets of all node pools could be built from the spec
+	ConditionNodePoolsValid = "NodePoolsValid"
+)
+
+// Vendors the operator can deploy, an empty vendor defaults to OpenSearch
+var SupportedVendors = []string{"", "Opensearch", "Op", "OP", "os", "opensearch"}
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -34,35 +100,69 @@ type GeneralConfig struct {
 	//+kubebuilder:default=9200
 	HttpPort int32 `json:"httpPort,omitempty"`
 	//+kubebuilder:validation:Enum=Opensearch;Op;OP;os;opensearch
-	Vendor           string  `json:"vendor,omitempty"`
-	Version          string  `json:"version,omitempty"`
-	ServiceAccount   string  `json:"serviceAccount,omitempty"`
-	ServiceName      string  `json:"serviceName"`
-	SetVMMaxMapCount bool    `json:"setVMMaxMapCount,omitempty"`
-	DefaultRepo      *string `json:"defaultRepo,omitempty"`
+	Vendor           string `json:"vendor,omitempty"`
+	Version          string `json:"version,omitempty"`
+	ServiceAccount   string `json:"serviceAccount,omitempty"`
+	ServiceName      string `json:"serviceName"`
+	SetVMMaxMapCount bool   `json:"setVMMaxMapCount,omitempty"`
+	//+kubebuilder:default="docker.io/opensearchproject"
+	DefaultRepo *string `json:"defaultRepo,omitempty"`
 	// Extra items to add to the opensearch.yml
 	AdditionalConfig map[string]string `json:"additionalConfig,omitempty"`
 	// Drain data nodes controls whether to drain data notes on rolling restart operations
 	DrainDataNodes bool     `json:"drainDataNodes,omitempty"`
 	PluginsList    []string `json:"pluginsList,omitempty"`
+	// Plugins to install on all nodes of the cluster
+	Plugins []Plugin `json:"plugins,omitempty"`
+	// Plugins bundled with the image to remove from all nodes of the cluster
+	RemovePlugins []string `json:"removePlugins,omitempty"`
+	// Populate opensearch keystore before startup
+	Keystore []KeystoreValue `json:"keystore,omitempty"`
 	// Additional volumes to mount to all pods in the cluster
 	AdditionalVolumes []AdditionalVolume `json:"additionalVolumes,omitempty"`
 }
 
 type NodePool struct {
-	Component        string                      `json:"component"`
-	Replicas         int32                       `json:"replicas"`
-	DiskSize         string                      `json:"diskSize,omitempty"`
-	Resources        corev1.ResourceRequirements `json:"resources,omitempty"`
-	Jvm              string                      `json:"jvm,omitempty"`
-	Roles            []string                    `json:"roles"`
-	Tolerations      []corev1.Toleration         `json:"tolerations,omitempty"`
-	NodeSelector     map[string]string           `json:"nodeSelector,omitempty"`
-	Affinity         *corev1.Affinity            `json:"affinity,omitempty"`
-	Persistence      *PersistenceConfig          `json:"persistence,omitempty"`
-	AdditionalConfig map[string]string           `json:"additionalConfig,omitempty"`
-	Labels           map[string]string           `json:"labels,omitempty"`
-	Env              []corev1.EnvVar             `json:"env,omitempty"`
+	Component string `json:"component"`
+	Replicas  int32  `json:"replicas"`
+	//+kubebuilder:default="30Gi"
+	DiskSize  string                      `json:"diskSize,omitempty"`
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	//+kubebuilder:default="-Xmx512M -Xms512M"
+	Jvm              string              `json:"jvm,omitempty"`
+	Roles            []string            `json:"roles"`
+	Tolerations      []corev1.Toleration `json:"tolerations,omitempty"`
+	NodeSelector     map[string]string   `json:"nodeSelector,omitempty"`
+	Affinity         *corev1.Affinity    `json:"affinity,omitempty"`
+	Persistence      *PersistenceConfig  `json:"persistence,omitempty"`
+	AdditionalConfig map[string]string   `json:"additionalConfig,omitempty"`
+	Labels           map[string]string   `json:"labels,omitempty"`
+	Env              []corev1.EnvVar     `json:"env,omitempty"`
+	// Plugins to install on the nodes of the node pool in addition to the plugins of the cluster
+	Plugins []Plugin `json:"plugins,omitempty"`
+	// Plugins to remove from the nodes of the node pool, including plugins of the cluster
+	RemovePlugins []string `json:"removePlugins,omitempty"`
+}
+
+// KeystoreValue adds the keys of a secret to the opensearch keystore
+type KeystoreValue struct {
+	// Secret containing key value pairs
+	Secret corev1.LocalObjectReference `json:"secret"`
+	// Key mappings from secret keys to keystore keys, all keys of the secret are added with their name if empty
+	KeyMappings map[string]string `json:"keyMappings,omitempty"`
+	// Reload the settings with _nodes/reload_secure_settings instead of restarting the nodes when the secret changes.
+	// Only reloadable settings like repository credentials can be reloaded.
+	Reloadable bool `json:"reloadable,omitempty"`
+}
+
+// Plugin is an OpenSearch plugin installed by the init container of the nodes
+type Plugin struct {
+	// Name of an official plugin, e.g. repository-s3
+	Name string `json:"name,omitempty"`
+	// Pin the version of an official plugin, it has to match the version of the cluster
+	Version string `json:"version,omitempty"`
+	// URL to install the plugin from, the name is not used to install the plugin if set
+	URL string `json:"url,omitempty"`
 }
 
 // PersistencConfig defines options for data persistence
@@ -87,6 +187,87 @@ type ConfMgmt struct {
 	Monitoring  bool `json:"monitoring,omitempty"`
 	VerUpdate   bool `json:"VerUpdate,omitempty"`
 	SmartScaler bool `json:"smartScaler,omitempty"`
+	// Versions the cluster is automatically upgraded to if VerUpdate is enabled
+	VersionChannel *VersionChannel `json:"versionChannel,omitempty"`
+}
+
+// VersionChannel defines the versions available for automatic upgrades
+type VersionChannel struct {
+	// Semver constraint for the versions to follow, e.g. 2.x for minor and patch versions or 2.1.x for patch versions only
+	Channel string `json:"channel"`
+	// Key of a ConfigMap listing the available versions, separated by whitespace
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+	// URL of a container registry tag list (/v2/<name>/tags/list) with the available versions
+	TagsURL string `json:"tagsURL,omitempty"`
+}
+
+// RemoteCluster is a connection to another cluster for cross-cluster search
+type RemoteCluster struct {
+	// Name of the remote cluster connection
+	//+kubebuilder:validation:MinLength=1
+	Alias string `json:"alias"`
+	// Remote cluster managed by the operator, the seeds and the transport trust are configured automatically
+	OpensearchRef *ClusterReference `json:"opensearchCluster,omitempty"`
+	// Transport addresses of a remote cluster not managed by the operator, e.g. remote.example.com:9300
+	Seeds []string `json:"seeds,omitempty"`
+}
+
+// ClusterReference references an OpenSearchCluster in any namespace
+type ClusterReference struct {
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Namespace of the cluster, defaults to the namespace of the referencing object
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// CrossNamespaceReferences allows objects in other namespaces to reference the cluster.
+// A namespace is allowed if it is listed in namespaces or matches the namespaceSelector.
+type CrossNamespaceReferences struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	// Selects the allowed namespaces by their labels
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring time window for disruptive operations
+type MaintenanceWindow struct {
+	// Start of the window as a cron expression, e.g. "0 2 * * 6" for every Saturday at 2am
+	Schedule string `json:"schedule"`
+	// Length of the window
+	Duration metav1.Duration `json:"duration"`
+	// Time zone of the schedule, e.g. Europe/Berlin, defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// UpgradeStrategy defines how the operator performs rolling upgrades
+type UpgradeStrategy struct {
+	// Names of the node pools in the order they should be upgraded. Node pools not listed are upgraded afterwards in the default order
+	PoolOrder []string `json:"poolOrder,omitempty"`
+	// Upgrade a single pod first and wait for a soak time or a manual approval before continuing
+	Canary *UpgradeCanary `json:"canary,omitempty"`
+	// Maximum number of pods of a node pool upgraded at the same time. Only used if shard allocation awareness is configured, cluster manager pools are always upgraded one pod at a time
+	//+kubebuilder:validation:Minimum=1
+	MaxConcurrentPods int32 `json:"maxConcurrentPods,omitempty"`
+	// Pre-flight checks that should not block an upgrade
+	SkipPreflightChecks []PreflightCheck `json:"skipPreflightChecks,omitempty"`
+	// Time an upgraded pod has to become ready and join the cluster before the upgrade is marked as failed, defaults to 15 minutes
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PreflightCheck is a check run against the cluster before an upgrade is started
+//+kubebuilder:validation:Enum=IndexCompatibility;Plugins;DeprecatedSettings;ClusterHealth
+type PreflightCheck string
+
+const (
+	PreflightCheckIndexCompatibility PreflightCheck = "IndexCompatibility"
+	PreflightCheckPlugins            PreflightCheck = "Plugins"
+	PreflightCheckDeprecatedSettings PreflightCheck = "DeprecatedSettings"
+	PreflightCheckClusterHealth      PreflightCheck = "ClusterHealth"
+)
+
+type UpgradeCanary struct {
+	Enable bool `json:"enable,omitempty"`
+	// Time to wait after the canary pod is upgraded before continuing. If not set the upgrade waits until the opster.io/approve-canary annotation is set to the new version
+	SoakTime *metav1.Duration `json:"soakTime,omitempty"`
 }
 
 type BootstrapConfig struct {
@@ -94,7 +275,8 @@ type BootstrapConfig struct {
 	Tolerations  []corev1.Toleration         `json:"tolerations,omitempty"`
 	NodeSelector map[string]string           `json:"nodeSelector,omitempty"`
 	Affinity     *corev1.Affinity            `json:"affinity,omitempty"`
-	Jvm          string                      `json:"jvm,omitempty"`
+	//+kubebuilder:default="-Xmx512M -Xms512M"
+	Jvm string `json:"jvm,omitempty"`
 }
 
 type DashboardsConfig struct {
@@ -104,6 +286,9 @@ type DashboardsConfig struct {
 	Replicas   int32                       `json:"replicas"`
 	Tls        *DashboardsTlsConfig        `json:"tls,omitempty"`
 	Version    string                      `json:"version"`
+	// Port of the dashboards http interface
+	//+kubebuilder:default=5601
+	Port int32 `json:"port,omitempty"`
 	// Additional properties for opensearch_dashboards.yaml
 	AdditionalConfig map[string]string `json:"additionalConfig,omitempty"`
 	// Secret that contains fields username and password for dashboards to use to login to opensearch, must only be supplied if a custom securityconfig is provided
@@ -128,6 +313,10 @@ type DashboardsTlsConfig struct {
 type Security struct {
 	Tls    *TlsConfig      `json:"tls,omitempty"`
 	Config *SecurityConfig `json:"config,omitempty"`
+	// Authentication backends the operator renders into the config.yml of the securityconfig
+	Authentication *AuthenticationConfig `json:"authentication,omitempty"`
+	// Audit logging of the security plugin, rendered into opensearch.yml and the audit.yml of the securityconfig
+	Audit *AuditConfig `json:"audit,omitempty"`
 }
 
 // Configure tls usage for transport and http interface
@@ -174,6 +363,226 @@ type SecurityConfig struct {
 	AdminSecret corev1.LocalObjectReference `json:"adminSecret,omitempty"`
 	// Secret that contains fields username and password to be used by the operator to access the opensearch cluster for node draining. Must be set if custom securityconfig is provided.
 	AdminCredentialsSecret corev1.LocalObjectReference `json:"adminCredentialsSecret,omitempty"`
+	// How changes of the securityconfig are applied. Full replaces the whole security config.
+	// Incremental applies only the entries that changed since the last update through the security REST API and keeps
+	// all other entries, e.g. users and roles created by OpensearchUser and OpensearchRole or through dashboards
+	//+kubebuilder:validation:Enum=Full;Incremental
+	//+kubebuilder:default=Full
+	UpdateMode SecurityconfigUpdateMode `json:"updateMode,omitempty"`
+	// Periodically export the live security config of the cluster into secrets, e.g. to keep changes made through the REST API or dashboards
+	Backup *SecurityconfigBackup `json:"backup,omitempty"`
+	// Generate random passwords for the built-in admin and kibanaserver users instead of using the demo credentials.
+	// Their hashes are rendered into the securityconfig, the passwords are stored in the <cluster>-admin-credentials
+	// and <cluster>-kibanaserver-credentials secrets. Can't be used together with adminCredentialsSecret
+	GeneratePasswords bool `json:"generatePasswords,omitempty"`
+}
+
+type SecurityconfigUpdateMode string
+
+const (
+	SecurityconfigUpdateFull        SecurityconfigUpdateMode = "Full"
+	SecurityconfigUpdateIncremental SecurityconfigUpdateMode = "Incremental"
+)
+
+// SecurityconfigBackup defines when the security config of the cluster is exported. Every backup is stored in its own
+// secret that can be used as securityConfigSecret to restore it
+type SecurityconfigBackup struct {
+	// When to export the security config as a cron expression, e.g. "0 3 * * *" for every day at 3am
+	Schedule string `json:"schedule"`
+	// Time zone of the schedule, e.g. Europe/Berlin, defaults to UTC
+	Timezone string `json:"timezone,omitempty"`
+	// Number of backups to keep, older backups are deleted
+	//+kubebuilder:validation:Minimum=1
+	//+kubebuilder:default=7
+	KeepVersions int32 `json:"keepVersions,omitempty"`
+}
+
+// AuthenticationConfig defines the authentication backends of the security plugin. The operator renders them
+// into config.yml, merges it with the config.yml of the securityConfigSecret and configures dashboards for them
+type AuthenticationConfig struct {
+	// Login with the users of the internal user database, enabled if not configured otherwise
+	Basic *BasicAuthConfig `json:"basic,omitempty"`
+	// Login via an OpenID Connect identity provider
+	OIDC *OIDCAuthConfig `json:"oidc,omitempty"`
+	// Login via a SAML identity provider
+	SAML *SAMLAuthConfig `json:"saml,omitempty"`
+	// Authenticate users against an LDAP server or Active Directory
+	LDAP *LDAPAuthConfig `json:"ldap,omitempty"`
+}
+
+type BasicAuthConfig struct {
+	//+kubebuilder:default=true
+	Enabled bool `json:"enabled"`
+}
+
+type OIDCAuthConfig struct {
+	// URL of the OpenID Connect metadata, e.g. https://idp.example.com/.well-known/openid-configuration
+	ConnectURL string `json:"connectUrl"`
+	// Client ID dashboards uses to authenticate with the identity provider
+	ClientID string `json:"clientId"`
+	// Key of a secret that contains the client secret of dashboards
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+	// Claim of the token that contains the username
+	SubjectKey string `json:"subjectKey,omitempty"`
+	// Claim of the token that contains the backend roles of the user
+	RolesKey string `json:"rolesKey,omitempty"`
+	// Scope requested from the identity provider, defaults to openid profile email address phone
+	Scope string `json:"scope,omitempty"`
+	// External URL of dashboards the identity provider redirects to after login
+	BaseRedirectURL string `json:"baseRedirectUrl,omitempty"`
+}
+
+type SAMLAuthConfig struct {
+	// URL of the SAML metadata of the identity provider
+	IdpMetadataURL string `json:"idpMetadataUrl"`
+	// Entity ID of the identity provider
+	IdpEntityID string `json:"idpEntityId"`
+	// Entity ID of the service provider, must match the client configured in the identity provider
+	SpEntityID string `json:"spEntityId"`
+	// External URL of dashboards
+	DashboardsURL string `json:"dashboardsUrl"`
+	// Attribute of the SAML response that contains the backend roles of the user
+	RolesKey string `json:"rolesKey,omitempty"`
+	// Key of a secret that contains the key used to sign the tokens issued after login, must be at least 32 characters
+	ExchangeKey corev1.SecretKeySelector `json:"exchangeKey"`
+}
+
+type LDAPAuthConfig struct {
+	// Hosts of the LDAP servers in the form host:port
+	Hosts []string `json:"hosts"`
+	// Connect to the servers with TLS
+	EnableSSL bool `json:"enableSsl,omitempty"`
+	// Secret that contains fields username and password with the DN and password used to bind to the servers, anonymous bind is used if not set
+	BindSecret *corev1.LocalObjectReference `json:"bindSecret,omitempty"`
+	// DN of the subtree that contains the users
+	UserBase string `json:"userBase"`
+	// Filter to find the user, {0} is replaced by the username
+	//+kubebuilder:default="(sAMAccountName={0})"
+	UserSearch string `json:"userSearch,omitempty"`
+	// Attribute of the user entry used as username
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+	// DN of the subtree that contains the groups, backend roles are only read from LDAP if set
+	RoleBase string `json:"roleBase,omitempty"`
+	// Filter to find the groups of the user, {0} is replaced by the DN of the user
+	//+kubebuilder:default="(member={0})"
+	RoleSearch string `json:"roleSearch,omitempty"`
+	// Attribute of the group entry used as backend role
+	//+kubebuilder:default=cn
+	RoleName string `json:"roleName,omitempty"`
+}
+
+// AuditSinkType is the storage the audit events are sent to
+//+kubebuilder:validation:Enum=internal;external;webhook;log4j
+type AuditSinkType string
+
+const (
+	// Store the audit events in an index of the cluster itself
+	AuditSinkInternal AuditSinkType = "internal"
+	// Store the audit events in an index of another OpenSearch cluster
+	AuditSinkExternal AuditSinkType = "external"
+	// Send the audit events to a webhook
+	AuditSinkWebhook AuditSinkType = "webhook"
+	// Write the audit events to a log4j logger
+	AuditSinkLog4j AuditSinkType = "log4j"
+)
+
+// AuditCategory is a category of audit events, NONE disables no category
+//+kubebuilder:validation:Enum=NONE;AUTHENTICATED;FAILED_LOGIN;GRANTED_PRIVILEGES;MISSING_PRIVILEGES;SSL_EXCEPTION;BAD_HEADERS;INDEX_EVENT;OPENDISTRO_SECURITY_INDEX_ATTEMPT
+type AuditCategory string
+
+type AuditConfig struct {
+	// Log audit events, enabled if not set
+	Enabled *bool `json:"enabled,omitempty"`
+	// Storage the audit events are sent to
+	//+kubebuilder:default=internal
+	Type AuditSinkType `json:"type,omitempty"`
+	// Index the internal and external sinks write to, defaults to 'security-auditlog-'YYYY.MM.dd
+	Index string `json:"index,omitempty"`
+	// OpenSearch cluster the events are stored in, required for the external sink
+	External *AuditExternalSink `json:"external,omitempty"`
+	// Webhook the events are sent to, required for the webhook sink
+	Webhook *AuditWebhookSink `json:"webhook,omitempty"`
+	// Logger the events are written to by the log4j sink
+	Log4j *AuditLog4jSink `json:"log4j,omitempty"`
+	// Log events of the REST layer, enabled if not set
+	EnableRest *bool `json:"enableRest,omitempty"`
+	// Categories not logged on the REST layer, defaults to AUTHENTICATED and GRANTED_PRIVILEGES
+	DisabledRestCategories []AuditCategory `json:"disabledRestCategories,omitempty"`
+	// Log events of the transport layer, enabled if not set
+	EnableTransport *bool `json:"enableTransport,omitempty"`
+	// Categories not logged on the transport layer, defaults to AUTHENTICATED and GRANTED_PRIVILEGES
+	DisabledTransportCategories []AuditCategory `json:"disabledTransportCategories,omitempty"`
+	// Users whose requests are not logged, defaults to kibanaserver
+	IgnoreUsers []string `json:"ignoreUsers,omitempty"`
+	// Requests that are not logged, e.g. indices:data/read/* or SearchRequest
+	IgnoreRequests []string `json:"ignoreRequests,omitempty"`
+	// Log the body of the requests, enabled if not set
+	LogRequestBody *bool `json:"logRequestBody,omitempty"`
+	// Resolve the index patterns and aliases of the requests, enabled if not set
+	ResolveIndices *bool `json:"resolveIndices,omitempty"`
+	// Log every request of a bulk request as a separate event
+	ResolveBulkRequests bool `json:"resolveBulkRequests,omitempty"`
+	// Remove the authorization headers from the events, enabled if not set
+	ExcludeSensitiveHeaders *bool `json:"excludeSensitiveHeaders,omitempty"`
+	// Compliance logging of reads and writes
+	Compliance *AuditComplianceConfig `json:"compliance,omitempty"`
+}
+
+type AuditExternalSink struct {
+	// Cluster managed by the operator, the endpoint and the TLS trust are configured automatically
+	OpensearchRef *ClusterReference `json:"opensearchCluster,omitempty"`
+	// HTTP addresses of a cluster not managed by the operator, e.g. audit.example.com:9200
+	Hosts []string `json:"hosts,omitempty"`
+	// Connect to the hosts with TLS, always enabled for operator managed clusters
+	EnableSSL bool `json:"enableSsl,omitempty"`
+	// Secret that contains the CA certificate of the hosts as ca.crt
+	CaSecret *corev1.LocalObjectReference `json:"caSecret,omitempty"`
+	// Secret that contains fields username and password of a user allowed to write to the index
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+}
+
+type AuditWebhookSink struct {
+	// URL the events are sent to
+	URL string `json:"url"`
+	// Format of the events
+	//+kubebuilder:validation:Enum=URL_PARAMETER_GET;URL_PARAMETER_POST;TEXT;JSON;SLACK
+	//+kubebuilder:default=JSON
+	Format string `json:"format,omitempty"`
+	// Verify the TLS certificate of the webhook, enabled if not set
+	SSLVerify *bool `json:"sslVerify,omitempty"`
+}
+
+type AuditLog4jSink struct {
+	// Name of the logger
+	//+kubebuilder:default=audit
+	LoggerName string `json:"loggerName,omitempty"`
+	// Level the events are logged with
+	//+kubebuilder:validation:Enum=TRACE;DEBUG;INFO;WARN;ERROR
+	//+kubebuilder:default=INFO
+	Level string `json:"level,omitempty"`
+}
+
+type AuditComplianceConfig struct {
+	// Log compliance events, enabled if not set
+	Enabled *bool `json:"enabled,omitempty"`
+	// Log changes of the security config, enabled if not set
+	InternalConfig *bool `json:"internalConfig,omitempty"`
+	// Log the configuration of the nodes on startup
+	ExternalConfig bool `json:"externalConfig,omitempty"`
+	// Only log the names of read fields without their content, enabled if not set
+	ReadMetadataOnly *bool `json:"readMetadataOnly,omitempty"`
+	// Fields whose reads are logged by index pattern
+	ReadWatchedFields map[string][]string `json:"readWatchedFields,omitempty"`
+	// Users whose reads are not logged, defaults to kibanaserver
+	ReadIgnoreUsers []string `json:"readIgnoreUsers,omitempty"`
+	// Only log the IDs of written documents without their content, enabled if not set
+	WriteMetadataOnly *bool `json:"writeMetadataOnly,omitempty"`
+	// Log the differences of updated documents
+	WriteLogDiffs bool `json:"writeLogDiffs,omitempty"`
+	// Index patterns whose writes are logged
+	WriteWatchedIndices []string `json:"writeWatchedIndices,omitempty"`
+	// Users whose writes are not logged, defaults to kibanaserver
+	WriteIgnoreUsers []string `json:"writeIgnoreUsers,omitempty"`
 }
 
 type ImageSpec struct {
@@ -205,6 +614,14 @@ type ClusterSpec struct {
 	Dashboards DashboardsConfig `json:"dashboards,omitempty"`
 	Security   *Security        `json:"security,omitempty"`
 	NodePools  []NodePool       `json:"nodePools"`
+	// Controls how rolling upgrades are performed
+	UpgradeStrategy *UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// Time windows for disruptive operations like restarts, upgrades and scale downs, if empty they are done at any time
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Remote clusters that can be used in cross-cluster searches
+	RemoteClusters []RemoteCluster `json:"remoteClusters,omitempty"`
+	// Namespaces whose users, roles and role bindings may reference the cluster, by default only the namespace of the cluster
+	CrossNamespaceReferences *CrossNamespaceReferences `json:"crossNamespaceReferences,omitempty"`
 }
 
 // ClusterStatus defines the observed state of Es
@@ -215,11 +632,73 @@ type ClusterStatus struct {
 	ComponentsStatus []ComponentStatus `json:"componentsStatus"`
 	Version          string            `json:"version,omitempty"`
 	Initialized      bool              `json:"initialized,omitempty"`
+	// Conditions of the cluster, e.g. the progress of an upgrade
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// The most recent upgrades of the cluster
+	UpgradeHistory []UpgradeHistoryEntry `json:"upgradeHistory,omitempty"`
+	// State of the automatic version updates
+	VersionUpdate *VersionUpdateStatus `json:"versionUpdate,omitempty"`
+	// Next maintenance window and the actions waiting for it
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+	// Connection state of the remote clusters
+	RemoteClusters []RemoteClusterStatus `json:"remoteClusters,omitempty"`
+}
+
+type MaintenanceStatus struct {
+	// Start of the next maintenance window
+	NextWindow *metav1.Time `json:"nextWindow,omitempty"`
+	// Disruptive actions waiting for the next maintenance window
+	DeferredActions []DeferredAction `json:"deferredActions,omitempty"`
+}
+
+type DeferredAction struct {
+	// One of Restart, Upgrade, ScaleDown or RemoveNodePool
+	Action   string `json:"action"`
+	NodePool string `json:"nodePool,omitempty"`
+	// Time the action was first deferred
+	Since metav1.Time `json:"since,omitempty"`
+}
+
+type RemoteClusterStatus struct {
+	Alias string `json:"alias"`
+	// Seeds the connection was configured with
+	Seeds []string `json:"seeds,omitempty"`
+	// True if the cluster is connected to at least one node of the remote cluster
+	Connected         bool  `json:"connected"`
+	NumNodesConnected int32 `json:"numNodesConnected,omitempty"`
+	// Why the connection could not be configured, e.g. because the referenced cluster does not exist, or why a
+	// connection removed from the spec is kept
+	Message string `json:"message,omitempty"`
+}
+
+type VersionUpdateStatus struct {
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+	// Newest version of the channel that is newer than the current version
+	AvailableVersion string `json:"availableVersion,omitempty"`
+	// The most recent automatic version updates
+	Updates []VersionUpdateEntry `json:"updates,omitempty"`
+}
+
+type VersionUpdateEntry struct {
+	FromVersion string      `json:"fromVersion"`
+	ToVersion   string      `json:"toVersion"`
+	Time        metav1.Time `json:"time"`
+}
+
+type UpgradeHistoryEntry struct {
+	FromVersion string       `json:"fromVersion"`
+	ToVersion   string       `json:"toVersion"`
+	StartTime   metav1.Time  `json:"startTime"`
+	EndTime     *metav1.Time `json:"endTime,omitempty"`
+	// Result of the upgrade, one of InProgress, Succeeded, Failed or RolledBack
+	Result string `json:"result"`
+	Reason string `json:"reason,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:shortName=os;opensearch
+//+kubebuilder:storageversion
 // Es is the Schema for the es API
 type OpenSearchCluster struct {
 	metav1.TypeMeta   `json:",inline"`
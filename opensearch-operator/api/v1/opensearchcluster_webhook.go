@@ -0,0 +1,352 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// log is for logging in this package.
+var opensearchclusterlog = logf.Log.WithName("opensearchcluster-resource")
+
+func (r *OpenSearchCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-opensearch-opster-io-v1-opensearchcluster,mutating=true,failurePolicy=fail,sideEffects=None,groups=opensearch.opster.io,resources=opensearchclusters,verbs=create;update,versions=v1,name=mopensearchcluster.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Defaulter = &OpenSearchCluster{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type.
+// It writes the values the operator uses for unset fields into the spec so they are visible to users.
+func (r *OpenSearchCluster) Default() {
+	opensearchclusterlog.Info("default", "name", r.Name)
+
+	if r.Spec.General.HttpPort == 0 {
+		r.Spec.General.HttpPort = 9200
+	}
+	if r.Spec.General.DefaultRepo == nil {
+		r.Spec.General.DefaultRepo = pointer.String(DefaultImageRepo)
+	}
+	for i := range r.Spec.NodePools {
+		nodePool := &r.Spec.NodePools[i]
+		if nodePool.DiskSize == "" {
+			nodePool.DiskSize = DefaultDiskSize
+		}
+		if nodePool.Jvm == "" {
+			nodePool.Jvm = DefaultJvm
+		}
+	}
+	if r.Spec.Bootstrap.Jvm == "" {
+		r.Spec.Bootstrap.Jvm = DefaultJvm
+	}
+	if r.Spec.Dashboards.Port == 0 {
+		r.Spec.Dashboards.Port = DefaultDashboardsPort
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-opensearch-opster-io-v1-opensearchcluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=opensearch.opster.io,resources=opensearchclusters,verbs=create;update,versions=v1,name=vopensearchcluster.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &OpenSearchCluster{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenSearchCluster) ValidateCreate() error {
+	opensearchclusterlog.Info("validate create", "name", r.Name)
+	return r.toAggregate(r.validateSpec())
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenSearchCluster) ValidateUpdate(old runtime.Object) error {
+	opensearchclusterlog.Info("validate update", "name", r.Name)
+	oldCluster, ok := old.(*OpenSearchCluster)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected an OpenSearchCluster but got a %T", old))
+	}
+	allErrs := newErrors(r.validateSpec(), oldCluster.validateSpec())
+	allErrs = append(allErrs, r.validateChanges(oldCluster)...)
+	return r.toAggregate(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *OpenSearchCluster) ValidateDelete() error {
+	return nil
+}
+
+func (r *OpenSearchCluster) toAggregate(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(schema.GroupKind{Group: GroupVersion.Group, Kind: "OpenSearchCluster"}, r.Name, allErrs)
+}
+
+// validateSpec checks the spec for mistakes that would otherwise only be noticed during reconciliation
+func (r *OpenSearchCluster) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	generalPath := field.NewPath("spec", "general")
+
+	if !containsString(SupportedVendors, r.Spec.General.Vendor) {
+		allErrs = append(allErrs, field.NotSupported(generalPath.Child("vendor"), r.Spec.General.Vendor, SupportedVendors[1:]))
+	}
+	if r.Spec.General.Version != "" {
+		if _, err := semver.NewVersion(r.Spec.General.Version); err != nil {
+			allErrs = append(allErrs, field.Invalid(generalPath.Child("version"), r.Spec.General.Version, "must be a valid version"))
+		}
+	}
+
+	masters := int32(0)
+	components := map[string]bool{}
+	for i, nodePool := range r.Spec.NodePools {
+		poolPath := field.NewPath("spec", "nodePools").Index(i)
+		if components[nodePool.Component] {
+			allErrs = append(allErrs, field.Duplicate(poolPath.Child("component"), nodePool.Component))
+		}
+		components[nodePool.Component] = true
+
+		for j, role := range nodePool.Roles {
+			if !containsString(AvailableRoles, role) {
+				allErrs = append(allErrs, field.NotSupported(poolPath.Child("roles").Index(j), role, AvailableRoles))
+			}
+		}
+		if containsString(nodePool.Roles, "master") || containsString(nodePool.Roles, "cluster_manager") {
+			masters += nodePool.Replicas
+		}
+		if nodePool.DiskSize != "" {
+			if _, err := resource.ParseQuantity(nodePool.DiskSize); err != nil {
+				allErrs = append(allErrs, field.Invalid(poolPath.Child("diskSize"), nodePool.DiskSize, "must be a valid quantity, e.g. 30Gi"))
+			}
+		}
+	}
+	if len(r.Spec.NodePools) > 0 {
+		if masters == 0 {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec", "nodePools"), "at least one node pool with the master or cluster_manager role and replicas is required"))
+		} else if masters%2 == 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "nodePools"), masters, "the number of master nodes must be odd to be able to elect a master after losing a node"))
+		}
+	}
+
+	allErrs = append(allErrs, r.validateRemoteClusters()...)
+	if refs := r.Spec.CrossNamespaceReferences; refs != nil && refs.NamespaceSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(refs.NamespaceSelector); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "crossNamespaceReferences", "namespaceSelector"), refs.NamespaceSelector, err.Error()))
+		}
+	}
+	return append(allErrs, r.validateSecurity()...)
+}
+
+// newErrors returns the errors the old spec didn't have. A check is only applied on update if the fields it covers
+// were changed, so clusters created before the check was added can still be updated.
+func newErrors(allErrs field.ErrorList, oldErrs field.ErrorList) field.ErrorList {
+	existing := map[string]bool{}
+	for _, err := range oldErrs {
+		existing[err.Error()] = true
+	}
+	var errs field.ErrorList
+	for _, err := range allErrs {
+		if !existing[err.Error()] {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateRemoteClusters checks that every remote cluster has a unique alias and either a cluster reference or seeds
+func (r *OpenSearchCluster) validateRemoteClusters() field.ErrorList {
+	var allErrs field.ErrorList
+	aliases := map[string]bool{}
+	for i, remote := range r.Spec.RemoteClusters {
+		remotePath := field.NewPath("spec", "remoteClusters").Index(i)
+		if aliases[remote.Alias] {
+			allErrs = append(allErrs, field.Duplicate(remotePath.Child("alias"), remote.Alias))
+		}
+		aliases[remote.Alias] = true
+
+		if (remote.OpensearchRef == nil) == (len(remote.Seeds) == 0) {
+			allErrs = append(allErrs, field.Invalid(remotePath, remote.Alias, "exactly one of opensearchCluster and seeds must be set"))
+		}
+	}
+	return allErrs
+}
+
+// validateSecurity checks that the secrets needed for user provided certificates and securityconfig are referenced
+func (r *OpenSearchCluster) validateSecurity() field.ErrorList {
+	var allErrs field.ErrorList
+	if r.Spec.Security == nil {
+		return allErrs
+	}
+	securityPath := field.NewPath("spec", "security")
+
+	transportGenerated := false
+	if tls := r.Spec.Security.Tls; tls != nil {
+		if transport := tls.Transport; transport != nil {
+			transportPath := securityPath.Child("tls", "transport")
+			transportGenerated = transport.Generate
+			if !transport.Generate {
+				if transport.Secret.Name == "" {
+					allErrs = append(allErrs, field.Required(transportPath.Child("secret", "name"), "a secret with the transport certificates is required if generate is false"))
+				}
+				if len(transport.NodesDn) == 0 {
+					allErrs = append(allErrs, field.Required(transportPath.Child("nodesDn"), "the DNs of the node certificates are required if generate is false"))
+				}
+			}
+		}
+		if http := tls.Http; http != nil && !http.Generate && http.Secret.Name == "" {
+			allErrs = append(allErrs, field.Required(securityPath.Child("tls", "http", "secret", "name"), "a secret with the http certificates is required if generate is false"))
+		}
+	}
+
+	// Features that change the security index with the admin certificate
+	var adminCertPurposes []string
+	if config := r.Spec.Security.Config; config != nil && config.SecurityconfigSecret.Name != "" {
+		if config.AdminCredentialsSecret.Name == "" && !config.GeneratePasswords {
+			allErrs = append(allErrs, field.Required(securityPath.Child("config", "adminCredentialsSecret", "name"), "the admin credentials are required if a securityconfig is provided"))
+		}
+		adminCertPurposes = append(adminCertPurposes, "apply the securityconfig")
+	}
+
+	if auth := r.Spec.Security.Authentication; auth != nil {
+		authPath := securityPath.Child("authentication")
+		if auth.OIDC != nil && auth.SAML != nil {
+			allErrs = append(allErrs, field.Forbidden(authPath.Child("saml"), "dashboards supports either oidc or saml, not both"))
+		}
+		if auth.Basic != nil && !auth.Basic.Enabled && auth.OIDC == nil && auth.SAML == nil && auth.LDAP == nil {
+			allErrs = append(allErrs, field.Required(authPath, "at least one authentication backend must be enabled"))
+		}
+		adminCertPurposes = append(adminCertPurposes, "apply the authentication config")
+	}
+
+	if config := r.Spec.Security.Config; config != nil && config.GeneratePasswords {
+		if config.AdminCredentialsSecret.Name != "" {
+			allErrs = append(allErrs, field.Forbidden(securityPath.Child("config", "adminCredentialsSecret", "name"), "the admin credentials are generated if generatePasswords is enabled"))
+		}
+		adminCertPurposes = append(adminCertPurposes, "apply the generated passwords")
+	}
+
+	if config := r.Spec.Security.Config; config != nil && config.Backup != nil {
+		adminCertPurposes = append(adminCertPurposes, "read the security config for backups")
+	}
+
+	if audit := r.Spec.Security.Audit; audit != nil {
+		allErrs = append(allErrs, validateAudit(audit, securityPath.Child("audit"))...)
+		adminCertPurposes = append(adminCertPurposes, "apply the audit config")
+	}
+
+	adminCertSet := r.Spec.Security.Config != nil && r.Spec.Security.Config.AdminSecret.Name != ""
+	if len(adminCertPurposes) > 0 && !adminCertSet && !transportGenerated {
+		allErrs = append(allErrs, field.Required(securityPath.Child("config", "adminSecret", "name"), fmt.Sprintf("an admin certificate is required to %s if the transport certificates are not generated", strings.Join(adminCertPurposes, ", "))))
+	}
+	return allErrs
+}
+
+// validateAudit checks that the sink selected by the audit type is configured
+func validateAudit(audit *AuditConfig, auditPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch audit.Type {
+	case AuditSinkExternal:
+		if audit.External == nil {
+			allErrs = append(allErrs, field.Required(auditPath.Child("external"), "the external cluster is required for the external sink"))
+		} else if (audit.External.OpensearchRef == nil) == (len(audit.External.Hosts) == 0) {
+			allErrs = append(allErrs, field.Invalid(auditPath.Child("external"), audit.External.Hosts, "exactly one of opensearchCluster and hosts must be set"))
+		}
+	case AuditSinkWebhook:
+		if audit.Webhook == nil || audit.Webhook.URL == "" {
+			allErrs = append(allErrs, field.Required(auditPath.Child("webhook", "url"), "the webhook url is required for the webhook sink"))
+		}
+	}
+	return allErrs
+}
+
+// validateChanges rejects changes the operator can't apply to an existing cluster
+func (r *OpenSearchCluster) validateChanges(old *OpenSearchCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	generalPath := field.NewPath("spec", "general")
+
+	if r.Spec.General.ServiceName != old.Spec.General.ServiceName {
+		allErrs = append(allErrs, field.Forbidden(generalPath.Child("serviceName"), "the service name can't be changed"))
+	}
+
+	// Same rules as the upgrade reconciler, compared to the version the cluster is running
+	current := old.Status.Version
+	if current == "" {
+		current = old.Spec.General.Version
+	}
+	if current != "" && r.Spec.General.Version != current {
+		currentVersion, currentErr := semver.NewVersion(current)
+		requestedVersion, requestedErr := semver.NewVersion(r.Spec.General.Version)
+		if currentErr == nil && requestedErr == nil {
+			if requestedVersion.LessThan(currentVersion) {
+				allErrs = append(allErrs, field.Forbidden(generalPath.Child("version"), fmt.Sprintf("downgrading from %s to %s is not supported", current, r.Spec.General.Version)))
+			} else if requestedVersion.Major() > currentVersion.Major()+1 {
+				allErrs = append(allErrs, field.Forbidden(generalPath.Child("version"), fmt.Sprintf("upgrading from %s to %s is more than one major version", current, r.Spec.General.Version)))
+			}
+		}
+	}
+
+	oldPools := map[string]NodePool{}
+	for _, nodePool := range old.Spec.NodePools {
+		oldPools[nodePool.Component] = nodePool
+	}
+	for i, nodePool := range r.Spec.NodePools {
+		oldPool, ok := oldPools[nodePool.Component]
+		if !ok {
+			continue
+		}
+		poolPath := field.NewPath("spec", "nodePools").Index(i)
+
+		if nodePool.DiskSize != "" && oldPool.DiskSize != "" {
+			newSize, newErr := resource.ParseQuantity(nodePool.DiskSize)
+			oldSize, oldErr := resource.ParseQuantity(oldPool.DiskSize)
+			if newErr == nil && oldErr == nil && newSize.Cmp(oldSize) < 0 {
+				allErrs = append(allErrs, field.Forbidden(poolPath.Child("diskSize"), fmt.Sprintf("the disk size can't be reduced from %s to %s", oldPool.DiskSize, nodePool.DiskSize)))
+			}
+		}
+		if storageClass(nodePool) != storageClass(oldPool) {
+			allErrs = append(allErrs, field.Forbidden(poolPath.Child("persistence", "pvc", "storageClass"), "the storage class of an existing node pool can't be changed"))
+		}
+	}
+	return allErrs
+}
+
+func storageClass(nodePool NodePool) string {
+	if nodePool.Persistence == nil || nodePool.Persistence.PVC == nil {
+		return ""
+	}
+	return nodePool.Persistence.PVC.StorageClassName
+}
+
+func containsString(slice []string, s string) bool {
+	for _, item := range slice {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
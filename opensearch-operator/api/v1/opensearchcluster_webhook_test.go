@@ -0,0 +1,262 @@
+package v1
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("OpenSearchCluster webhook", func() {
+	var cluster *OpenSearchCluster
+
+	BeforeEach(func() {
+		cluster = &OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "default",
+			},
+			Spec: ClusterSpec{
+				General: GeneralConfig{
+					ServiceName: "test-cluster",
+					Version:     "1.3.0",
+				},
+				NodePools: []NodePool{
+					{
+						Component: "masters",
+						Replicas:  3,
+						DiskSize:  "30Gi",
+						Roles:     []string{"master", "data"},
+					},
+				},
+			},
+		}
+	})
+
+	Context("When defaulting a cluster", func() {
+		It("should set the defaults used by the operator", func() {
+			cluster.Spec.NodePools[0].DiskSize = ""
+			cluster.Default()
+			Expect(cluster.Spec.General.HttpPort).To(Equal(int32(9200)))
+			Expect(*cluster.Spec.General.DefaultRepo).To(Equal(DefaultImageRepo))
+			Expect(cluster.Spec.NodePools[0].DiskSize).To(Equal(DefaultDiskSize))
+			Expect(cluster.Spec.NodePools[0].Jvm).To(Equal(DefaultJvm))
+			Expect(cluster.Spec.Bootstrap.Jvm).To(Equal(DefaultJvm))
+			Expect(cluster.Spec.Dashboards.Port).To(Equal(DefaultDashboardsPort))
+		})
+
+		It("should keep values set by the user", func() {
+			cluster.Spec.NodePools[0].Jvm = "-Xmx1024M -Xms1024M"
+			cluster.Spec.Dashboards.Port = 8080
+			cluster.Default()
+			Expect(cluster.Spec.NodePools[0].DiskSize).To(Equal("30Gi"))
+			Expect(cluster.Spec.NodePools[0].Jvm).To(Equal("-Xmx1024M -Xms1024M"))
+			Expect(cluster.Spec.Dashboards.Port).To(Equal(int32(8080)))
+		})
+	})
+
+	Context("When creating a cluster", func() {
+		It("should accept a valid cluster", func() {
+			Expect(cluster.ValidateCreate()).To(Succeed())
+		})
+
+		It("should reject unknown vendors", func() {
+			cluster.Spec.General.Vendor = "elasticsearch"
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.general.vendor")))
+		})
+
+		It("should reject unknown roles", func() {
+			cluster.Spec.NodePools[0].Roles = append(cluster.Spec.NodePools[0].Roles, "coordinating")
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.nodePools[0].roles[2]")))
+		})
+
+		It("should reject an even number of masters", func() {
+			cluster.Spec.NodePools[0].Replicas = 2
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("must be odd")))
+		})
+
+		It("should reject invalid remote clusters", func() {
+			cluster.Spec.RemoteClusters = []RemoteCluster{
+				{Alias: "remote", Seeds: []string{"remote.example.com:9300"}},
+				{Alias: "remote", OpensearchRef: &ClusterReference{Name: "other"}},
+				{Alias: "both", OpensearchRef: &ClusterReference{Name: "other"}, Seeds: []string{"remote.example.com:9300"}},
+			}
+			err := cluster.ValidateCreate()
+			Expect(err).To(MatchError(ContainSubstring("spec.remoteClusters[1].alias")))
+			Expect(err).To(MatchError(ContainSubstring("spec.remoteClusters[2]: Invalid value")))
+			Expect(err).NotTo(MatchError(ContainSubstring("spec.remoteClusters[0]")))
+		})
+
+		It("should reject an invalid namespace selector", func() {
+			cluster.Spec.CrossNamespaceReferences = &CrossNamespaceReferences{
+				NamespaceSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{
+						{Key: "team", Operator: "Unknown"},
+					},
+				},
+			}
+			err := cluster.ValidateCreate()
+			Expect(err).To(MatchError(ContainSubstring("spec.crossNamespaceReferences.namespaceSelector")))
+		})
+
+		It("should reject user provided certificates without secrets", func() {
+			cluster.Spec.Security = &Security{
+				Tls: &TlsConfig{
+					Transport: &TlsConfigTransport{},
+					Http:      &TlsConfigHttp{Generate: true},
+				},
+				Config: &SecurityConfig{
+					SecurityconfigSecret: corev1.LocalObjectReference{Name: "securityconfig"},
+				},
+			}
+			err := cluster.ValidateCreate()
+			Expect(err).To(MatchError(ContainSubstring("spec.security.tls.transport.secret.name")))
+			Expect(err).To(MatchError(ContainSubstring("spec.security.config.adminCredentialsSecret.name")))
+			Expect(err).To(MatchError(ContainSubstring("spec.security.config.adminSecret.name")))
+		})
+
+		It("should reject oidc and saml together", func() {
+			cluster.Spec.Security = &Security{
+				Tls: &TlsConfig{
+					Transport: &TlsConfigTransport{Generate: true},
+				},
+				Authentication: &AuthenticationConfig{
+					OIDC: &OIDCAuthConfig{ConnectURL: "https://idp.example.com/.well-known/openid-configuration", ClientID: "dashboards"},
+					SAML: &SAMLAuthConfig{IdpMetadataURL: "https://idp.example.com/saml/metadata"},
+				},
+			}
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.security.authentication.saml")))
+		})
+
+		It("should report all features that need the admin certificate in one error", func() {
+			cluster.Spec.Security = &Security{
+				Config: &SecurityConfig{
+					SecurityconfigSecret:   corev1.LocalObjectReference{Name: "securityconfig"},
+					AdminCredentialsSecret: corev1.LocalObjectReference{Name: "admin-credentials"},
+				},
+				Authentication: &AuthenticationConfig{},
+			}
+			err := cluster.ValidateCreate()
+			Expect(err).To(MatchError(ContainSubstring("an admin certificate is required to apply the securityconfig, apply the authentication config if")))
+			Expect(strings.Count(err.Error(), "spec.security.config.adminSecret.name")).To(Equal(1))
+
+			cluster.Spec.Security.Tls = &TlsConfig{Transport: &TlsConfigTransport{Generate: true}}
+			Expect(cluster.ValidateCreate()).To(Succeed())
+		})
+
+		It("should list the security features in the admin certificate error", func() {
+			cluster.Spec.Security = &Security{
+				Config: &SecurityConfig{
+					GeneratePasswords: true,
+					Backup:            &SecurityconfigBackup{Schedule: "0 3 * * *"},
+				},
+				Audit: &AuditConfig{},
+			}
+			err := cluster.ValidateCreate()
+			Expect(err).To(MatchError(ContainSubstring("an admin certificate is required to apply the generated passwords, read the security config for backups, apply the audit config if")))
+			Expect(strings.Count(err.Error(), "spec.security.config.adminSecret.name")).To(Equal(1))
+		})
+
+		It("should require an admin certificate for securityconfig backups", func() {
+			cluster.Spec.Security = &Security{
+				Config: &SecurityConfig{
+					Backup: &SecurityconfigBackup{Schedule: "0 3 * * *"},
+				},
+			}
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.security.config.adminSecret.name")))
+		})
+
+		It("should reject admin credentials together with generated passwords", func() {
+			cluster.Spec.Security = &Security{
+				Tls: &TlsConfig{
+					Transport: &TlsConfigTransport{Generate: true},
+				},
+				Config: &SecurityConfig{
+					AdminCredentialsSecret: corev1.LocalObjectReference{Name: "admin-credentials"},
+					GeneratePasswords:      true,
+				},
+			}
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.security.config.adminCredentialsSecret.name")))
+		})
+
+		It("should reject an audit sink without its configuration", func() {
+			cluster.Spec.Security = &Security{
+				Tls: &TlsConfig{
+					Transport: &TlsConfigTransport{Generate: true},
+				},
+				Audit: &AuditConfig{
+					Type:     AuditSinkExternal,
+					External: &AuditExternalSink{OpensearchRef: &ClusterReference{Name: "audit"}, Hosts: []string{"audit.example.com:9200"}},
+				},
+			}
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.security.audit.external")))
+
+			cluster.Spec.Security.Audit = &AuditConfig{Type: AuditSinkWebhook}
+			Expect(cluster.ValidateCreate()).To(MatchError(ContainSubstring("spec.security.audit.webhook.url")))
+		})
+	})
+
+	Context("When updating a cluster", func() {
+		var old *OpenSearchCluster
+
+		BeforeEach(func() {
+			old = cluster.DeepCopy()
+			old.Status.Version = "1.3.0"
+		})
+
+		It("should reject a changed service name", func() {
+			cluster.Spec.General.ServiceName = "other"
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("spec.general.serviceName")))
+		})
+
+		It("should reject a smaller disk", func() {
+			cluster.Spec.NodePools[0].DiskSize = "20Gi"
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("can't be reduced")))
+			cluster.Spec.NodePools[0].DiskSize = "50Gi"
+			Expect(cluster.ValidateUpdate(old)).To(Succeed())
+		})
+
+		It("should reject a changed storage class", func() {
+			cluster.Spec.NodePools[0].Persistence = &PersistenceConfig{
+				PersistenceSource: PersistenceSource{
+					PVC: &PVCSource{StorageClassName: "fast"},
+				},
+			}
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("storage class")))
+		})
+
+		It("should apply the upgrade rules", func() {
+			cluster.Spec.General.Version = "2.3.0"
+			Expect(cluster.ValidateUpdate(old)).To(Succeed())
+			cluster.Spec.General.Version = "1.2.0"
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("downgrading")))
+			cluster.Spec.General.Version = "3.0.0"
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("more than one major version")))
+		})
+
+		It("should allow rolling back a started upgrade", func() {
+			old.Spec.General.Version = "2.0.0"
+			Expect(cluster.ValidateUpdate(old)).To(Succeed())
+		})
+
+		It("should allow updating unrelated fields of a cluster created before a check was added", func() {
+			old.Spec.NodePools[0].Replicas = 2
+			old.Spec.NodePools[0].Roles = append(old.Spec.NodePools[0].Roles, "legacy")
+			cluster = old.DeepCopy()
+			cluster.Spec.General.Version = "1.3.1"
+			Expect(cluster.ValidateUpdate(old)).To(Succeed())
+		})
+
+		It("should apply the checks to changed fields", func() {
+			old.Spec.NodePools[0].Replicas = 2
+			cluster = old.DeepCopy()
+			cluster.Spec.NodePools[0].Replicas = 4
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("must be odd")))
+			cluster.Spec.NodePools[0].Replicas = 3
+			cluster.Spec.NodePools[0].Roles = append(cluster.Spec.NodePools[0].Roles, "legacy")
+			Expect(cluster.ValidateUpdate(old)).To(MatchError(ContainSubstring("spec.nodePools[0].roles[2]")))
+		})
+	})
+})
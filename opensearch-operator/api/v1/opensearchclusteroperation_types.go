@@ -0,0 +1,121 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type OpensearchClusterOperationState string
+
+const (
+	OpensearchClusterOperationStatePending   OpensearchClusterOperationState = "PENDING"
+	OpensearchClusterOperationStateRunning   OpensearchClusterOperationState = "RUNNING"
+	OpensearchClusterOperationStateSucceeded OpensearchClusterOperationState = "SUCCEEDED"
+	OpensearchClusterOperationStateFailed    OpensearchClusterOperationState = "FAILED"
+)
+
+//+kubebuilder:validation:Enum=RestartNodePool;RestartPod;DrainNode;ClearAllocationExcludes;SetShardAllocation;RerouteShards;Flush
+
+// OperationAction is a single action performed against the cluster
+type OperationAction string
+
+const (
+	// Restart all pods of a node pool one at a time
+	OperationRestartNodePool OperationAction = "RestartNodePool"
+	// Restart a single pod
+	OperationRestartPod OperationAction = "RestartPod"
+	// Exclude a node from shard allocation and wait until all shards have moved away
+	OperationDrainNode OperationAction = "DrainNode"
+	// Remove all nodes from the shard allocation excludes
+	OperationClearAllocationExcludes OperationAction = "ClearAllocationExcludes"
+	// Set which shards can be allocated
+	OperationSetShardAllocation OperationAction = "SetShardAllocation"
+	// Retry the allocation of shards that failed to allocate too often
+	OperationRerouteShards OperationAction = "RerouteShards"
+	// Flush all indices
+	OperationFlush OperationAction = "Flush"
+)
+
+// OpensearchClusterOperationSpec defines the desired state of OpensearchClusterOperation
+type OpensearchClusterOperationSpec struct {
+	OpensearchRef corev1.LocalObjectReference `json:"opensearchCluster"`
+	// Steps of the operation, executed in order
+	//+kubebuilder:validation:MinItems=1
+	Steps []OperationStep `json:"steps"`
+	// Fail the operation if it is not finished within the timeout
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+type OperationStep struct {
+	Action OperationAction `json:"action"`
+	// Node pool for RestartNodePool
+	NodePool string `json:"nodePool,omitempty"`
+	// Pod for RestartPod and DrainNode
+	Pod string `json:"pod,omitempty"`
+	// Move all shards away from data nodes before restarting them
+	Drain bool `json:"drain,omitempty"`
+	// Shard allocation for SetShardAllocation
+	//+kubebuilder:validation:Enum=all;primaries;none
+	Allocation string `json:"allocation,omitempty"`
+}
+
+// OpensearchClusterOperationStatus defines the observed state of OpensearchClusterOperation
+type OpensearchClusterOperationStatus struct {
+	State          OpensearchClusterOperationState `json:"state,omitempty"`
+	Reason         string                          `json:"reason,omitempty"`
+	ManagedCluster *types.UID                      `json:"managedCluster,omitempty"`
+	StartTime      *metav1.Time                    `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time                    `json:"completionTime,omitempty"`
+	Steps          []OperationStepStatus           `json:"steps,omitempty"`
+}
+
+type OperationStepStatus struct {
+	Action         OperationAction                 `json:"action"`
+	State          OpensearchClusterOperationState `json:"state"`
+	Message        string                          `json:"message,omitempty"`
+	StartTime      *metav1.Time                    `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time                    `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=opensearchclusteroperation
+//+kubebuilder:subresource:status
+
+// OpensearchClusterOperation is the Schema for the opensearchclusteroperations API
+type OpensearchClusterOperation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpensearchClusterOperationSpec   `json:"spec,omitempty"`
+	Status OpensearchClusterOperationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpensearchClusterOperationList contains a list of OpensearchClusterOperation
+type OpensearchClusterOperationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpensearchClusterOperation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpensearchClusterOperation{}, &OpensearchClusterOperationList{})
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OpensearchConnectionSpec defines how to connect to an opensearch cluster that is not managed by the operator
+type OpensearchConnectionSpec struct {
+	// URL of the REST API of the cluster, e.g. https://opensearch.example.com:9200
+	//+kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+	// Secret with the CA certificate in ca.crt used to verify the certificate of the cluster, the system CAs are used if not set
+	CaSecret *corev1.LocalObjectReference `json:"caSecret,omitempty"`
+	// Secret with the username and password used to authenticate, it needs the permissions of the security REST API
+	CredentialsSecret *corev1.LocalObjectReference `json:"credentialsSecret,omitempty"`
+	// Secret with the client certificate in tls.crt and its key in tls.key used to authenticate instead of credentials
+	ClientCertSecret *corev1.LocalObjectReference `json:"clientCertSecret,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=opensearchconnection
+
+// OpensearchConnection is the Schema for the opensearchconnections API
+type OpensearchConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec OpensearchConnectionSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpensearchConnectionList contains a list of OpensearchConnection
+type OpensearchConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpensearchConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpensearchConnection{}, &OpensearchConnectionList{})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type OpensearchReplicationRuleState string
+
+const (
+	OpensearchReplicationRuleStatePending OpensearchReplicationRuleState = "PENDING"
+	OpensearchReplicationRuleStateRunning OpensearchReplicationRuleState = "RUNNING"
+	OpensearchReplicationRuleStatePaused  OpensearchReplicationRuleState = "PAUSED"
+	OpensearchReplicationRuleStateStopped OpensearchReplicationRuleState = "STOPPED"
+	OpensearchReplicationRuleStateError   OpensearchReplicationRuleState = "ERROR"
+)
+
+//+kubebuilder:validation:Enum=Running;Paused;Stopped
+
+// ReplicationState is the requested state of the replication
+type ReplicationState string
+
+const (
+	// Start the replication or resume it if it is paused
+	ReplicationRunning ReplicationState = "Running"
+	// Pause the replication, it can be resumed later on
+	ReplicationPaused ReplicationState = "Paused"
+	// Stop the replication, the follower indices become regular indices and can't be resumed
+	ReplicationStopped ReplicationState = "Stopped"
+)
+
+// OpensearchReplicationRuleSpec defines the desired state of OpensearchReplicationRule
+type OpensearchReplicationRuleSpec struct {
+	// The follower cluster the indices are replicated into
+	OpensearchRef corev1.LocalObjectReference `json:"opensearchCluster"`
+	Leader        ReplicationLeader           `json:"leader"`
+	// Leader indices to replicate, the follower indices get the same name
+	Indices []string `json:"indices,omitempty"`
+	// Auto-follow rules that replicate all leader indices matching a pattern, including ones created later on
+	AutoFollow []AutoFollowRule `json:"autoFollow,omitempty"`
+	// Roles used by the replication if the security plugin is enabled
+	UseRoles *ReplicationRoles `json:"useRoles,omitempty"`
+	//+kubebuilder:default=Running
+	State ReplicationState `json:"state,omitempty"`
+}
+
+type ReplicationLeader struct {
+	// Name of the remote cluster connection in the follower cluster
+	//+kubebuilder:validation:MinLength=1
+	Alias string `json:"alias"`
+	// Leader cluster managed by the operator, the seeds and the transport trust are configured automatically
+	OpensearchRef *corev1.LocalObjectReference `json:"opensearchCluster,omitempty"`
+	// Transport addresses of a leader cluster not managed by the operator, e.g. leader.example.com:9300
+	Seeds []string `json:"seeds,omitempty"`
+}
+
+type AutoFollowRule struct {
+	// Name of the auto-follow rule in the follower cluster
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Pattern of the leader indices to replicate, e.g. logs-*
+	//+kubebuilder:validation:MinLength=1
+	Pattern string `json:"pattern"`
+}
+
+type ReplicationRoles struct {
+	LeaderClusterRole   string `json:"leaderClusterRole"`
+	FollowerClusterRole string `json:"followerClusterRole"`
+}
+
+// OpensearchReplicationRuleStatus defines the observed state of OpensearchReplicationRule
+type OpensearchReplicationRuleStatus struct {
+	State          OpensearchReplicationRuleState `json:"state,omitempty"`
+	Reason         string                         `json:"reason,omitempty"`
+	ManagedCluster *types.UID                     `json:"managedCluster,omitempty"`
+	// Auto-follow rules created in the follower cluster
+	AutoFollowRules []string                `json:"autoFollowRules,omitempty"`
+	Indices         []ReplicatedIndexStatus `json:"indices,omitempty"`
+}
+
+type ReplicatedIndexStatus struct {
+	Index string `json:"index"`
+	// Replication status reported by opensearch, e.g. SYNCING or PAUSED
+	Status             string `json:"status,omitempty"`
+	LeaderCheckpoint   int64  `json:"leaderCheckpoint,omitempty"`
+	FollowerCheckpoint int64  `json:"followerCheckpoint,omitempty"`
+	// Number of operations the follower index is behind the leader index
+	Lag int64 `json:"lag,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:shortName=opensearchreplicationrule
+//+kubebuilder:subresource:status
+
+// OpensearchReplicationRule is the Schema for the opensearchreplicationrules API
+type OpensearchReplicationRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OpensearchReplicationRuleSpec   `json:"spec,omitempty"`
+	Status OpensearchReplicationRuleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpensearchReplicationRuleList contains a list of OpensearchReplicationRule
+type OpensearchReplicationRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpensearchReplicationRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpensearchReplicationRule{}, &OpensearchReplicationRuleList{})
+}
@@ -33,10 +33,13 @@ const (
 
 // OpensearchRoleSpec defines the desired state of OpensearchRole
 type OpensearchRoleSpec struct {
-	OpensearchRef      corev1.LocalObjectReference `json:"opensearchCluster"`
-	ClusterPermissions []string                    `json:"clusterPermissions,omitempty"`
-	IndexPermissions   []IndexPermissionSpec       `json:"indexPermissions,omitempty"`
-	TenantPermissions  []TenantPermissionsSpec     `json:"tenantPermissions,omitempty"`
+	// The cluster the role is created in, a cluster in another namespace has to allow references from the namespace of the role
+	OpensearchRef *ClusterReference `json:"opensearchCluster,omitempty"`
+	// Connection to a cluster not managed by the operator the role is created in, alternative to opensearchCluster
+	OpensearchConnection *corev1.LocalObjectReference `json:"opensearchConnection,omitempty"`
+	ClusterPermissions   []string                     `json:"clusterPermissions,omitempty"`
+	IndexPermissions     []IndexPermissionSpec        `json:"indexPermissions,omitempty"`
+	TenantPermissions    []TenantPermissionsSpec      `json:"tenantPermissions,omitempty"`
 }
 
 type IndexPermissionSpec struct {
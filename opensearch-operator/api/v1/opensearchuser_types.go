@@ -32,11 +32,14 @@ const (
 
 // OpensearchUserSpec defines the desired state of OpensearchUser
 type OpensearchUserSpec struct {
-	OpensearchRef           corev1.LocalObjectReference `json:"opensearchCluster"`
-	PasswordFrom            corev1.SecretKeySelector    `json:"passwordFrom"`
-	OpendistroSecurityRoles []string                    `json:"opendistroSecurityRoles,omitempty"`
-	BackendRoles            []string                    `json:"backendRoles,omitempty"`
-	Attributes              map[string]string           `json:"attributes,omitempty"`
+	// The cluster the user is created in, a cluster in another namespace has to allow references from the namespace of the user
+	OpensearchRef *ClusterReference `json:"opensearchCluster,omitempty"`
+	// Connection to a cluster not managed by the operator the user is created in, alternative to opensearchCluster
+	OpensearchConnection    *corev1.LocalObjectReference `json:"opensearchConnection,omitempty"`
+	PasswordFrom            corev1.SecretKeySelector     `json:"passwordFrom"`
+	OpendistroSecurityRoles []string                     `json:"opendistroSecurityRoles,omitempty"`
+	BackendRoles            []string                     `json:"backendRoles,omitempty"`
+	Attributes              map[string]string            `json:"attributes,omitempty"`
 }
 
 // OpensearchUserStatus defines the observed state of OpensearchUser
@@ -32,9 +32,12 @@ const (
 
 // OpensearchUserRoleBindingSpec defines the desired state of OpensearchUserRoleBinding
 type OpensearchUserRoleBindingSpec struct {
-	OpensearchRef corev1.LocalObjectReference `json:"opensearchCluster"`
-	Roles         []string                    `json:"roles"`
-	Users         []string                    `json:"users"`
+	// The cluster the role mapping is created in, a cluster in another namespace has to allow references from the namespace of the binding
+	OpensearchRef *ClusterReference `json:"opensearchCluster,omitempty"`
+	// Connection to a cluster not managed by the operator the role mapping is created in, alternative to opensearchCluster
+	OpensearchConnection *corev1.LocalObjectReference `json:"opensearchConnection,omitempty"`
+	Roles                []string                     `json:"roles"`
+	Users                []string                     `json:"users"`
 }
 
 // OpensearchUserRoleBindingStatus defines the observed state of OpensearchUserRoleBinding
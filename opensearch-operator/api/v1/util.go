@@ -1,6 +1,10 @@
 package v1
 
-import "k8s.io/apimachinery/pkg/types"
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
 
 type OpensearchClusterSelector struct {
 	Name      string `json:"name,omitempty"`
@@ -13,3 +17,63 @@ func (o *OpensearchClusterSelector) ObjectKey() types.NamespacedName {
 		Namespace: o.Namespace,
 	}
 }
+
+// NamespacedName returns the name of the referenced cluster, namespace is the namespace of the referencing object
+func (r ClusterReference) NamespacedName(namespace string) types.NamespacedName {
+	if r.Namespace != "" {
+		namespace = r.Namespace
+	}
+	return types.NamespacedName{Name: r.Name, Namespace: namespace}
+}
+
+// PausedComponents returns the components whose reconciliation is paused by the pause annotation
+func (c *OpenSearchCluster) PausedComponents() []string {
+	value := strings.TrimSpace(c.Annotations[PauseReconcileAnnotation])
+	switch value {
+	case "", "false":
+		return nil
+	case "true", PauseAll:
+		return []string{PauseAll}
+	}
+	var components []string
+	for _, component := range strings.Split(value, ",") {
+		if component = strings.TrimSpace(component); component != "" {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// ReconcilePaused returns true if reconciling the given component of the cluster is paused
+func (c *OpenSearchCluster) ReconcilePaused(component string) bool {
+	for _, paused := range c.PausedComponents() {
+		if paused == PauseAll || paused == component {
+			return true
+		}
+	}
+	return false
+}
+
+// AvailableRoles are the node roles that can be assigned to node pools
+var AvailableRoles = []string{
+	"master",
+	"data",
+	"data_content",
+	"data_hot",
+	"data_warm",
+	"data_cold",
+	"data_frozen",
+	"ingest",
+	"ml",
+	"remote_cluster_client",
+	"transform",
+	"cluster_manager",
+}
+
+// Defaults for unset fields, written to the spec by the defaulting webhook
+const (
+	DefaultImageRepo            = "docker.io/opensearchproject"
+	DefaultDiskSize             = "30Gi"
+	DefaultJvm                  = "-Xmx512M -Xms512M"
+	DefaultDashboardsPort int32 = 5601
+)
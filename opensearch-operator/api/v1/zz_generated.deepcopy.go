@@ -23,7 +23,8 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -52,6 +53,291 @@ func (in *AdditionalVolume) DeepCopy() *AdditionalVolume {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditComplianceConfig) DeepCopyInto(out *AuditComplianceConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InternalConfig != nil {
+		in, out := &in.InternalConfig, &out.InternalConfig
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReadMetadataOnly != nil {
+		in, out := &in.ReadMetadataOnly, &out.ReadMetadataOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReadWatchedFields != nil {
+		in, out := &in.ReadWatchedFields, &out.ReadWatchedFields
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ReadIgnoreUsers != nil {
+		in, out := &in.ReadIgnoreUsers, &out.ReadIgnoreUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WriteMetadataOnly != nil {
+		in, out := &in.WriteMetadataOnly, &out.WriteMetadataOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WriteWatchedIndices != nil {
+		in, out := &in.WriteWatchedIndices, &out.WriteWatchedIndices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WriteIgnoreUsers != nil {
+		in, out := &in.WriteIgnoreUsers, &out.WriteIgnoreUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditComplianceConfig.
+func (in *AuditComplianceConfig) DeepCopy() *AuditComplianceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditComplianceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditConfig) DeepCopyInto(out *AuditConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.External != nil {
+		in, out := &in.External, &out.External
+		*out = new(AuditExternalSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(AuditWebhookSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Log4j != nil {
+		in, out := &in.Log4j, &out.Log4j
+		*out = new(AuditLog4jSink)
+		**out = **in
+	}
+	if in.EnableRest != nil {
+		in, out := &in.EnableRest, &out.EnableRest
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisabledRestCategories != nil {
+		in, out := &in.DisabledRestCategories, &out.DisabledRestCategories
+		*out = make([]AuditCategory, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnableTransport != nil {
+		in, out := &in.EnableTransport, &out.EnableTransport
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DisabledTransportCategories != nil {
+		in, out := &in.DisabledTransportCategories, &out.DisabledTransportCategories
+		*out = make([]AuditCategory, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreUsers != nil {
+		in, out := &in.IgnoreUsers, &out.IgnoreUsers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.IgnoreRequests != nil {
+		in, out := &in.IgnoreRequests, &out.IgnoreRequests
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogRequestBody != nil {
+		in, out := &in.LogRequestBody, &out.LogRequestBody
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ResolveIndices != nil {
+		in, out := &in.ResolveIndices, &out.ResolveIndices
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExcludeSensitiveHeaders != nil {
+		in, out := &in.ExcludeSensitiveHeaders, &out.ExcludeSensitiveHeaders
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Compliance != nil {
+		in, out := &in.Compliance, &out.Compliance
+		*out = new(AuditComplianceConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditConfig.
+func (in *AuditConfig) DeepCopy() *AuditConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditExternalSink) DeepCopyInto(out *AuditExternalSink) {
+	*out = *in
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(ClusterReference)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CaSecret != nil {
+		in, out := &in.CaSecret, &out.CaSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditExternalSink.
+func (in *AuditExternalSink) DeepCopy() *AuditExternalSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditExternalSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLog4jSink) DeepCopyInto(out *AuditLog4jSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLog4jSink.
+func (in *AuditLog4jSink) DeepCopy() *AuditLog4jSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLog4jSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditWebhookSink) DeepCopyInto(out *AuditWebhookSink) {
+	*out = *in
+	if in.SSLVerify != nil {
+		in, out := &in.SSLVerify, &out.SSLVerify
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditWebhookSink.
+func (in *AuditWebhookSink) DeepCopy() *AuditWebhookSink {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditWebhookSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthenticationConfig) DeepCopyInto(out *AuthenticationConfig) {
+	*out = *in
+	if in.Basic != nil {
+		in, out := &in.Basic, &out.Basic
+		*out = new(BasicAuthConfig)
+		**out = **in
+	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDCAuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SAML != nil {
+		in, out := &in.SAML, &out.SAML
+		*out = new(SAMLAuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LDAP != nil {
+		in, out := &in.LDAP, &out.LDAP
+		*out = new(LDAPAuthConfig)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthenticationConfig.
+func (in *AuthenticationConfig) DeepCopy() *AuthenticationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthenticationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AutoFollowRule) DeepCopyInto(out *AutoFollowRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutoFollowRule.
+func (in *AutoFollowRule) DeepCopy() *AutoFollowRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AutoFollowRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuthConfig) DeepCopyInto(out *BasicAuthConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuthConfig.
+func (in *BasicAuthConfig) DeepCopy() *BasicAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BootstrapConfig) DeepCopyInto(out *BootstrapConfig) {
 	*out = *in
@@ -87,11 +373,26 @@ func (in *BootstrapConfig) DeepCopy() *BootstrapConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterReference) DeepCopyInto(out *ClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterReference.
+func (in *ClusterReference) DeepCopy() *ClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	*out = *in
 	in.General.DeepCopyInto(&out.General)
-	out.ConfMgmt = in.ConfMgmt
+	in.ConfMgmt.DeepCopyInto(&out.ConfMgmt)
 	in.Bootstrap.DeepCopyInto(&out.Bootstrap)
 	in.Dashboards.DeepCopyInto(&out.Dashboards)
 	if in.Security != nil {
@@ -106,6 +407,28 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(UpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]RemoteCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CrossNamespaceReferences != nil {
+		in, out := &in.CrossNamespaceReferences, &out.CrossNamespaceReferences
+		*out = new(CrossNamespaceReferences)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -126,6 +449,37 @@ func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 		*out = make([]ComponentStatus, len(*in))
 		copy(*out, *in)
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeHistory != nil {
+		in, out := &in.UpgradeHistory, &out.UpgradeHistory
+		*out = make([]UpgradeHistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VersionUpdate != nil {
+		in, out := &in.VersionUpdate, &out.VersionUpdate
+		*out = new(VersionUpdateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RemoteClusters != nil {
+		in, out := &in.RemoteClusters, &out.RemoteClusters
+		*out = make([]RemoteClusterStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
@@ -156,6 +510,11 @@ func (in *ComponentStatus) DeepCopy() *ComponentStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ConfMgmt) DeepCopyInto(out *ConfMgmt) {
 	*out = *in
+	if in.VersionChannel != nil {
+		in, out := &in.VersionChannel, &out.VersionChannel
+		*out = new(VersionChannel)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfMgmt.
@@ -168,6 +527,31 @@ func (in *ConfMgmt) DeepCopy() *ConfMgmt {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrossNamespaceReferences) DeepCopyInto(out *CrossNamespaceReferences) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossNamespaceReferences.
+func (in *CrossNamespaceReferences) DeepCopy() *CrossNamespaceReferences {
+	if in == nil {
+		return nil
+	}
+	out := new(CrossNamespaceReferences)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DashboardsConfig) DeepCopyInto(out *DashboardsConfig) {
 	*out = *in
@@ -251,6 +635,22 @@ func (in *DashboardsTlsConfig) DeepCopy() *DashboardsTlsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeferredAction) DeepCopyInto(out *DeferredAction) {
+	*out = *in
+	in.Since.DeepCopyInto(&out.Since)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeferredAction.
+func (in *DeferredAction) DeepCopy() *DeferredAction {
+	if in == nil {
+		return nil
+	}
+	out := new(DeferredAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GeneralConfig) DeepCopyInto(out *GeneralConfig) {
 	*out = *in
@@ -276,6 +676,23 @@ func (in *GeneralConfig) DeepCopyInto(out *GeneralConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]Plugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovePlugins != nil {
+		in, out := &in.RemovePlugins, &out.RemovePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Keystore != nil {
+		in, out := &in.Keystore, &out.Keystore
+		*out = make([]KeystoreValue, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AdditionalVolumes != nil {
 		in, out := &in.AdditionalVolumes, &out.AdditionalVolumes
 		*out = make([]AdditionalVolume, len(*in))
@@ -356,16 +773,106 @@ func (in *IndexPermissionSpec) DeepCopy() *IndexPermissionSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodePool) DeepCopyInto(out *NodePool) {
+func (in *KeystoreValue) DeepCopyInto(out *KeystoreValue) {
 	*out = *in
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Roles != nil {
-		in, out := &in.Roles, &out.Roles
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.Secret = in.Secret
+	if in.KeyMappings != nil {
+		in, out := &in.KeyMappings, &out.KeyMappings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoreValue.
+func (in *KeystoreValue) DeepCopy() *KeystoreValue {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoreValue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LDAPAuthConfig) DeepCopyInto(out *LDAPAuthConfig) {
+	*out = *in
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BindSecret != nil {
+		in, out := &in.BindSecret, &out.BindSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LDAPAuthConfig.
+func (in *LDAPAuthConfig) DeepCopy() *LDAPAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LDAPAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
+	*out = *in
+	if in.NextWindow != nil {
+		in, out := &in.NextWindow, &out.NextWindow
+		*out = (*in).DeepCopy()
+	}
+	if in.DeferredActions != nil {
+		in, out := &in.DeferredActions, &out.DeferredActions
+		*out = make([]DeferredAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePool) DeepCopyInto(out *NodePool) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
@@ -409,6 +916,16 @@ func (in *NodePool) DeepCopyInto(out *NodePool) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]Plugin, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemovePlugins != nil {
+		in, out := &in.RemovePlugins, &out.RemovePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
@@ -421,6 +938,22 @@ func (in *NodePool) DeepCopy() *NodePool {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDCAuthConfig) DeepCopyInto(out *OIDCAuthConfig) {
+	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDCAuthConfig.
+func (in *OIDCAuthConfig) DeepCopy() *OIDCAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDCAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpenSearchCluster) DeepCopyInto(out *OpenSearchCluster) {
 	*out = *in
@@ -477,20 +1010,349 @@ func (in *OpenSearchClusterList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
-	return nil
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchClusterOperation) DeepCopyInto(out *OpensearchClusterOperation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterOperation.
+func (in *OpensearchClusterOperation) DeepCopy() *OpensearchClusterOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchClusterOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchClusterOperation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchClusterOperationList) DeepCopyInto(out *OpensearchClusterOperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpensearchClusterOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterOperationList.
+func (in *OpensearchClusterOperationList) DeepCopy() *OpensearchClusterOperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchClusterOperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchClusterOperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchClusterOperationSpec) DeepCopyInto(out *OpensearchClusterOperationSpec) {
+	*out = *in
+	out.OpensearchRef = in.OpensearchRef
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]OperationStep, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterOperationSpec.
+func (in *OpensearchClusterOperationSpec) DeepCopy() *OpensearchClusterOperationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchClusterOperationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchClusterOperationStatus) DeepCopyInto(out *OpensearchClusterOperationStatus) {
+	*out = *in
+	if in.ManagedCluster != nil {
+		in, out := &in.ManagedCluster, &out.ManagedCluster
+		*out = new(types.UID)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]OperationStepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterOperationStatus.
+func (in *OpensearchClusterOperationStatus) DeepCopy() *OpensearchClusterOperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchClusterOperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchClusterSelector) DeepCopyInto(out *OpensearchClusterSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterSelector.
+func (in *OpensearchClusterSelector) DeepCopy() *OpensearchClusterSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchClusterSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchConnection) DeepCopyInto(out *OpensearchConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchConnection.
+func (in *OpensearchConnection) DeepCopy() *OpensearchConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchConnectionList) DeepCopyInto(out *OpensearchConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpensearchConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchConnectionList.
+func (in *OpensearchConnectionList) DeepCopy() *OpensearchConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchConnectionSpec) DeepCopyInto(out *OpensearchConnectionSpec) {
+	*out = *in
+	if in.CaSecret != nil {
+		in, out := &in.CaSecret, &out.CaSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.CredentialsSecret != nil {
+		in, out := &in.CredentialsSecret, &out.CredentialsSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.ClientCertSecret != nil {
+		in, out := &in.ClientCertSecret, &out.ClientCertSecret
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchConnectionSpec.
+func (in *OpensearchConnectionSpec) DeepCopy() *OpensearchConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchReplicationRule) DeepCopyInto(out *OpensearchReplicationRule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchReplicationRule.
+func (in *OpensearchReplicationRule) DeepCopy() *OpensearchReplicationRule {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchReplicationRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchReplicationRule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchReplicationRuleList) DeepCopyInto(out *OpensearchReplicationRuleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OpensearchReplicationRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchReplicationRuleList.
+func (in *OpensearchReplicationRuleList) DeepCopy() *OpensearchReplicationRuleList {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchReplicationRuleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OpensearchReplicationRuleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OpensearchReplicationRuleSpec) DeepCopyInto(out *OpensearchReplicationRuleSpec) {
+	*out = *in
+	out.OpensearchRef = in.OpensearchRef
+	in.Leader.DeepCopyInto(&out.Leader)
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoFollow != nil {
+		in, out := &in.AutoFollow, &out.AutoFollow
+		*out = make([]AutoFollowRule, len(*in))
+		copy(*out, *in)
+	}
+	if in.UseRoles != nil {
+		in, out := &in.UseRoles, &out.UseRoles
+		*out = new(ReplicationRoles)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchReplicationRuleSpec.
+func (in *OpensearchReplicationRuleSpec) DeepCopy() *OpensearchReplicationRuleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OpensearchReplicationRuleSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OpensearchClusterSelector) DeepCopyInto(out *OpensearchClusterSelector) {
+func (in *OpensearchReplicationRuleStatus) DeepCopyInto(out *OpensearchReplicationRuleStatus) {
 	*out = *in
+	if in.ManagedCluster != nil {
+		in, out := &in.ManagedCluster, &out.ManagedCluster
+		*out = new(types.UID)
+		**out = **in
+	}
+	if in.AutoFollowRules != nil {
+		in, out := &in.AutoFollowRules, &out.AutoFollowRules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Indices != nil {
+		in, out := &in.Indices, &out.Indices
+		*out = make([]ReplicatedIndexStatus, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchClusterSelector.
-func (in *OpensearchClusterSelector) DeepCopy() *OpensearchClusterSelector {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OpensearchReplicationRuleStatus.
+func (in *OpensearchReplicationRuleStatus) DeepCopy() *OpensearchReplicationRuleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(OpensearchClusterSelector)
+	out := new(OpensearchReplicationRuleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -557,7 +1419,16 @@ func (in *OpensearchRoleList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpensearchRoleSpec) DeepCopyInto(out *OpensearchRoleSpec) {
 	*out = *in
-	out.OpensearchRef = in.OpensearchRef
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(ClusterReference)
+		**out = **in
+	}
+	if in.OpensearchConnection != nil {
+		in, out := &in.OpensearchConnection, &out.OpensearchConnection
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	if in.ClusterPermissions != nil {
 		in, out := &in.ClusterPermissions, &out.ClusterPermissions
 		*out = make([]string, len(*in))
@@ -735,7 +1606,16 @@ func (in *OpensearchUserRoleBindingList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpensearchUserRoleBindingSpec) DeepCopyInto(out *OpensearchUserRoleBindingSpec) {
 	*out = *in
-	out.OpensearchRef = in.OpensearchRef
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(ClusterReference)
+		**out = **in
+	}
+	if in.OpensearchConnection != nil {
+		in, out := &in.OpensearchConnection, &out.OpensearchConnection
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	if in.Roles != nil {
 		in, out := &in.Roles, &out.Roles
 		*out = make([]string, len(*in))
@@ -791,7 +1671,16 @@ func (in *OpensearchUserRoleBindingStatus) DeepCopy() *OpensearchUserRoleBinding
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OpensearchUserSpec) DeepCopyInto(out *OpensearchUserSpec) {
 	*out = *in
-	out.OpensearchRef = in.OpensearchRef
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(ClusterReference)
+		**out = **in
+	}
+	if in.OpensearchConnection != nil {
+		in, out := &in.OpensearchConnection, &out.OpensearchConnection
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
 	in.PasswordFrom.DeepCopyInto(&out.PasswordFrom)
 	if in.OpendistroSecurityRoles != nil {
 		in, out := &in.OpendistroSecurityRoles, &out.OpendistroSecurityRoles
@@ -842,6 +1731,44 @@ func (in *OpensearchUserStatus) DeepCopy() *OpensearchUserStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStep) DeepCopyInto(out *OperationStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStep.
+func (in *OperationStep) DeepCopy() *OperationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStepStatus) DeepCopyInto(out *OperationStepStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStepStatus.
+func (in *OperationStepStatus) DeepCopy() *OperationStepStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStepStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PVCSource) DeepCopyInto(out *PVCSource) {
 	*out = *in
@@ -908,6 +1835,137 @@ func (in *PersistenceSource) DeepCopy() *PersistenceSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plugin) DeepCopyInto(out *Plugin) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
+func (in *Plugin) DeepCopy() *Plugin {
+	if in == nil {
+		return nil
+	}
+	out := new(Plugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteCluster) DeepCopyInto(out *RemoteCluster) {
+	*out = *in
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(ClusterReference)
+		**out = **in
+	}
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteCluster.
+func (in *RemoteCluster) DeepCopy() *RemoteCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteClusterStatus) DeepCopyInto(out *RemoteClusterStatus) {
+	*out = *in
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterStatus.
+func (in *RemoteClusterStatus) DeepCopy() *RemoteClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicatedIndexStatus) DeepCopyInto(out *ReplicatedIndexStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicatedIndexStatus.
+func (in *ReplicatedIndexStatus) DeepCopy() *ReplicatedIndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicatedIndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationLeader) DeepCopyInto(out *ReplicationLeader) {
+	*out = *in
+	if in.OpensearchRef != nil {
+		in, out := &in.OpensearchRef, &out.OpensearchRef
+		*out = new(corev1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Seeds != nil {
+		in, out := &in.Seeds, &out.Seeds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationLeader.
+func (in *ReplicationLeader) DeepCopy() *ReplicationLeader {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationLeader)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationRoles) DeepCopyInto(out *ReplicationRoles) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationRoles.
+func (in *ReplicationRoles) DeepCopy() *ReplicationRoles {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationRoles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SAMLAuthConfig) DeepCopyInto(out *SAMLAuthConfig) {
+	*out = *in
+	in.ExchangeKey.DeepCopyInto(&out.ExchangeKey)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SAMLAuthConfig.
+func (in *SAMLAuthConfig) DeepCopy() *SAMLAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SAMLAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Security) DeepCopyInto(out *Security) {
 	*out = *in
@@ -919,7 +1977,17 @@ func (in *Security) DeepCopyInto(out *Security) {
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = new(SecurityConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Authentication != nil {
+		in, out := &in.Authentication, &out.Authentication
+		*out = new(AuthenticationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(AuditConfig)
+		(*in).DeepCopyInto(*out)
 	}
 }
 
@@ -939,6 +2007,11 @@ func (in *SecurityConfig) DeepCopyInto(out *SecurityConfig) {
 	out.SecurityconfigSecret = in.SecurityconfigSecret
 	out.AdminSecret = in.AdminSecret
 	out.AdminCredentialsSecret = in.AdminCredentialsSecret
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		*out = new(SecurityconfigBackup)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityConfig.
@@ -951,6 +2024,21 @@ func (in *SecurityConfig) DeepCopy() *SecurityConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityconfigBackup) DeepCopyInto(out *SecurityconfigBackup) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecurityconfigBackup.
+func (in *SecurityconfigBackup) DeepCopy() *SecurityconfigBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(SecurityconfigBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TenantPermissionsSpec) DeepCopyInto(out *TenantPermissionsSpec) {
 	*out = *in
@@ -1079,3 +2167,140 @@ func (in *TlsSecret) DeepCopy() *TlsSecret {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeCanary) DeepCopyInto(out *UpgradeCanary) {
+	*out = *in
+	if in.SoakTime != nil {
+		in, out := &in.SoakTime, &out.SoakTime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeCanary.
+func (in *UpgradeCanary) DeepCopy() *UpgradeCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeHistoryEntry) DeepCopyInto(out *UpgradeHistoryEntry) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.EndTime != nil {
+		in, out := &in.EndTime, &out.EndTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeHistoryEntry.
+func (in *UpgradeHistoryEntry) DeepCopy() *UpgradeHistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeHistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStrategy) DeepCopyInto(out *UpgradeStrategy) {
+	*out = *in
+	if in.PoolOrder != nil {
+		in, out := &in.PoolOrder, &out.PoolOrder
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(UpgradeCanary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SkipPreflightChecks != nil {
+		in, out := &in.SkipPreflightChecks, &out.SkipPreflightChecks
+		*out = make([]PreflightCheck, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStrategy.
+func (in *UpgradeStrategy) DeepCopy() *UpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionChannel) DeepCopyInto(out *VersionChannel) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionChannel.
+func (in *VersionChannel) DeepCopy() *VersionChannel {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionChannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpdateEntry) DeepCopyInto(out *VersionUpdateEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpdateEntry.
+func (in *VersionUpdateEntry) DeepCopy() *VersionUpdateEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpdateEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VersionUpdateStatus) DeepCopyInto(out *VersionUpdateStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Updates != nil {
+		in, out := &in.Updates, &out.Updates
+		*out = make([]VersionUpdateEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VersionUpdateStatus.
+func (in *VersionUpdateStatus) DeepCopy() *VersionUpdateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VersionUpdateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
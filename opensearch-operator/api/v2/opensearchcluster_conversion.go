@@ -0,0 +1,505 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// The v1 API only has flat string settings, so the structured configs of a v2 cluster are kept in this annotation of the v1 object
+const ConfigAnnotation = "opensearch.opster.io/v2-config"
+
+type storedConfig struct {
+	General    *runtime.RawExtension            `json:"general,omitempty"`
+	Dashboards *runtime.RawExtension            `json:"dashboards,omitempty"`
+	NodePools  map[string]*runtime.RawExtension `json:"nodePools,omitempty"`
+}
+
+var _ conversion.Convertible = &OpenSearchCluster{}
+
+// ConvertTo converts this OpenSearchCluster to the hub version (v1)
+func (src *OpenSearchCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*opsterv1.OpenSearchCluster)
+	if !ok {
+		return fmt.Errorf("unsupported hub type %T", dstRaw)
+	}
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+	stored := storedConfig{}
+	var err error
+
+	general := src.Spec.General
+	dst.Spec.General = opsterv1.GeneralConfig{
+		ImageSpec:         general.ImageSpec,
+		HttpPort:          general.HttpPort,
+		Vendor:            general.Vendor,
+		Version:           general.Version,
+		ServiceAccount:    general.ServiceAccount,
+		ServiceName:       general.ServiceName,
+		SetVMMaxMapCount:  general.SetVMMaxMapCount,
+		DefaultRepo:       general.DefaultRepo,
+		DrainDataNodes:    general.DrainDataNodes,
+		PluginsList:       general.PluginsList,
+		Plugins:           general.Plugins,
+		RemovePlugins:     general.RemovePlugins,
+		Keystore:          general.Keystore,
+		AdditionalVolumes: general.AdditionalVolumes,
+	}
+	if dst.Spec.General.AdditionalConfig, err = flattenConfig(general.Config); err != nil {
+		return fmt.Errorf("invalid spec.general.config: %w", err)
+	}
+	stored.General = configToStore(general.Config, dst.Spec.General.AdditionalConfig)
+
+	management := src.Spec.Management
+	dst.Spec.ConfMgmt = opsterv1.ConfMgmt{
+		AutoScaler:     management.AutoScaler,
+		Monitoring:     management.Monitoring,
+		VerUpdate:      management.VersionUpdates.Enable,
+		SmartScaler:    management.SmartScaler,
+		VersionChannel: management.VersionUpdates.Channel,
+	}
+
+	bootstrap := src.Spec.Bootstrap
+	dst.Spec.Bootstrap = opsterv1.BootstrapConfig{
+		Resources:    bootstrap.Pod.Resources,
+		Tolerations:  bootstrap.Pod.Tolerations,
+		NodeSelector: bootstrap.Pod.NodeSelector,
+		Affinity:     bootstrap.Pod.Affinity,
+		Jvm:          bootstrap.Jvm,
+	}
+
+	dashboards := src.Spec.Dashboards
+	dst.Spec.Dashboards = opsterv1.DashboardsConfig{
+		ImageSpec:                   dashboards.ImageSpec,
+		Enable:                      dashboards.Enable,
+		Resources:                   dashboards.Pod.Resources,
+		Replicas:                    dashboards.Replicas,
+		Version:                     dashboards.Version,
+		Port:                        dashboards.Port,
+		OpensearchCredentialsSecret: secretRefToV1(dashboards.OpensearchCredentialsSecret),
+		Env:                         dashboards.Env,
+		AdditionalVolumes:           dashboards.AdditionalVolumes,
+		Tolerations:                 dashboards.Pod.Tolerations,
+		NodeSelector:                dashboards.Pod.NodeSelector,
+		Affinity:                    dashboards.Pod.Affinity,
+	}
+	if dashboards.Tls != nil {
+		dst.Spec.Dashboards.Tls = &opsterv1.DashboardsTlsConfig{
+			Enable:               dashboards.Tls.Enable,
+			Generate:             dashboards.Tls.Generate,
+			TlsCertificateConfig: certificatesToV1(dashboards.Tls.TlsCertificates),
+		}
+	}
+	if dst.Spec.Dashboards.AdditionalConfig, err = flattenConfig(dashboards.Config); err != nil {
+		return fmt.Errorf("invalid spec.dashboards.config: %w", err)
+	}
+	stored.Dashboards = configToStore(dashboards.Config, dst.Spec.Dashboards.AdditionalConfig)
+
+	dst.Spec.Security = nil
+	if security := src.Spec.Security; security != nil {
+		dst.Spec.Security = &opsterv1.Security{}
+		if tls := security.Tls; tls != nil {
+			dst.Spec.Security.Tls = &opsterv1.TlsConfig{}
+			if transport := tls.Transport; transport != nil {
+				dst.Spec.Security.Tls.Transport = &opsterv1.TlsConfigTransport{
+					Generate:             transport.Generate,
+					PerNode:              transport.PerNode,
+					TlsCertificateConfig: certificatesToV1(transport.TlsCertificates),
+					NodesDn:              transport.NodesDn,
+					AdminDn:              transport.AdminDn,
+				}
+			}
+			if http := tls.Http; http != nil {
+				dst.Spec.Security.Tls.Http = &opsterv1.TlsConfigHttp{
+					Generate:             http.Generate,
+					TlsCertificateConfig: certificatesToV1(*http),
+				}
+			}
+		}
+		if config := security.Config; config != nil {
+			dst.Spec.Security.Config = &opsterv1.SecurityConfig{
+				SecurityconfigSecret:   secretRefToV1(config.SecurityconfigSecret),
+				AdminSecret:            secretRefToV1(config.AdminSecret),
+				AdminCredentialsSecret: secretRefToV1(config.AdminCredentialsSecret),
+				UpdateMode:             config.UpdateMode,
+				Backup:                 config.Backup,
+				GeneratePasswords:      config.GeneratePasswords,
+			}
+		}
+		dst.Spec.Security.Authentication = security.Authentication
+		dst.Spec.Security.Audit = security.Audit
+	}
+
+	dst.Spec.NodePools = nil
+	for _, nodePool := range src.Spec.NodePools {
+		converted := opsterv1.NodePool{
+			Component:     nodePool.Component,
+			Replicas:      nodePool.Replicas,
+			DiskSize:      nodePool.DiskSize,
+			Resources:     nodePool.Pod.Resources,
+			Jvm:           nodePool.Jvm,
+			Roles:         nodePool.Roles,
+			Tolerations:   nodePool.Pod.Tolerations,
+			NodeSelector:  nodePool.Pod.NodeSelector,
+			Affinity:      nodePool.Pod.Affinity,
+			Persistence:   nodePool.Persistence,
+			Labels:        nodePool.Labels,
+			Env:           nodePool.Env,
+			Plugins:       nodePool.Plugins,
+			RemovePlugins: nodePool.RemovePlugins,
+		}
+		if converted.AdditionalConfig, err = flattenConfig(nodePool.Config); err != nil {
+			return fmt.Errorf("invalid config of node pool %s: %w", nodePool.Component, err)
+		}
+		if config := configToStore(nodePool.Config, converted.AdditionalConfig); config != nil {
+			if stored.NodePools == nil {
+				stored.NodePools = map[string]*runtime.RawExtension{}
+			}
+			stored.NodePools[nodePool.Component] = config
+		}
+		dst.Spec.NodePools = append(dst.Spec.NodePools, converted)
+	}
+	dst.Spec.UpgradeStrategy = src.Spec.UpgradeStrategy
+	dst.Spec.MaintenanceWindows = src.Spec.MaintenanceWindows
+	dst.Spec.RemoteClusters = src.Spec.RemoteClusters
+	dst.Spec.CrossNamespaceReferences = src.Spec.CrossNamespaceReferences
+
+	delete(dst.Annotations, ConfigAnnotation)
+	if stored.General != nil || stored.Dashboards != nil || len(stored.NodePools) > 0 {
+		data, err := json.Marshal(stored)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[ConfigAnnotation] = string(data)
+	}
+
+	status := src.Status
+	dst.Status = opsterv1.ClusterStatus{
+		Phase:          string(status.Phase),
+		Version:        status.Version,
+		Initialized:    status.Initialized,
+		Conditions:     status.Conditions,
+		UpgradeHistory: status.UpgradeHistory,
+		VersionUpdate:  status.VersionUpdate,
+		Maintenance:    status.Maintenance,
+		RemoteClusters: status.RemoteClusters,
+	}
+	for _, component := range status.Components {
+		dst.Status.ComponentsStatus = append(dst.Status.ComponentsStatus, opsterv1.ComponentStatus{
+			Component:   component.Component,
+			Status:      string(component.State),
+			Description: component.Target,
+		})
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1) to this version
+func (dst *OpenSearchCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*opsterv1.OpenSearchCluster)
+	if !ok {
+		return fmt.Errorf("unsupported hub type %T", srcRaw)
+	}
+	src = src.DeepCopy()
+	dst.ObjectMeta = src.ObjectMeta
+
+	// A broken annotation only means the structure of the configs is lost, not the settings
+	stored := storedConfig{}
+	if data, ok := src.Annotations[ConfigAnnotation]; ok {
+		_ = json.Unmarshal([]byte(data), &stored)
+		delete(dst.Annotations, ConfigAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	var err error
+
+	general := src.Spec.General
+	dst.Spec.General = GeneralConfig{
+		ImageSpec:         general.ImageSpec,
+		HttpPort:          general.HttpPort,
+		Vendor:            general.Vendor,
+		Version:           general.Version,
+		ServiceAccount:    general.ServiceAccount,
+		ServiceName:       general.ServiceName,
+		SetVMMaxMapCount:  general.SetVMMaxMapCount,
+		DefaultRepo:       general.DefaultRepo,
+		DrainDataNodes:    general.DrainDataNodes,
+		PluginsList:       general.PluginsList,
+		Plugins:           general.Plugins,
+		RemovePlugins:     general.RemovePlugins,
+		Keystore:          general.Keystore,
+		AdditionalVolumes: general.AdditionalVolumes,
+	}
+	if dst.Spec.General.Config, err = restoreConfig(stored.General, general.AdditionalConfig); err != nil {
+		return err
+	}
+
+	confMgmt := src.Spec.ConfMgmt
+	dst.Spec.Management = ManagementConfig{
+		AutoScaler:  confMgmt.AutoScaler,
+		Monitoring:  confMgmt.Monitoring,
+		SmartScaler: confMgmt.SmartScaler,
+		VersionUpdates: VersionUpdates{
+			Enable:  confMgmt.VerUpdate,
+			Channel: confMgmt.VersionChannel,
+		},
+	}
+
+	bootstrap := src.Spec.Bootstrap
+	dst.Spec.Bootstrap = BootstrapConfig{
+		Pod: PodTemplate{
+			Resources:    bootstrap.Resources,
+			NodeSelector: bootstrap.NodeSelector,
+			Tolerations:  bootstrap.Tolerations,
+			Affinity:     bootstrap.Affinity,
+		},
+		Jvm: bootstrap.Jvm,
+	}
+
+	dashboards := src.Spec.Dashboards
+	dst.Spec.Dashboards = DashboardsConfig{
+		ImageSpec: dashboards.ImageSpec,
+		Enable:    dashboards.Enable,
+		Replicas:  dashboards.Replicas,
+		Version:   dashboards.Version,
+		Port:      dashboards.Port,
+		Pod: PodTemplate{
+			Resources:    dashboards.Resources,
+			NodeSelector: dashboards.NodeSelector,
+			Tolerations:  dashboards.Tolerations,
+			Affinity:     dashboards.Affinity,
+		},
+		OpensearchCredentialsSecret: secretRefFromV1(dashboards.OpensearchCredentialsSecret),
+		Env:                         dashboards.Env,
+		AdditionalVolumes:           dashboards.AdditionalVolumes,
+	}
+	if dashboards.Tls != nil {
+		dst.Spec.Dashboards.Tls = &DashboardsTlsConfig{
+			Enable:          dashboards.Tls.Enable,
+			TlsCertificates: certificatesFromV1(dashboards.Tls.Generate, dashboards.Tls.TlsCertificateConfig),
+		}
+	}
+	if dst.Spec.Dashboards.Config, err = restoreConfig(stored.Dashboards, dashboards.AdditionalConfig); err != nil {
+		return err
+	}
+
+	dst.Spec.Security = nil
+	if security := src.Spec.Security; security != nil {
+		dst.Spec.Security = &Security{}
+		if tls := security.Tls; tls != nil {
+			dst.Spec.Security.Tls = &TlsConfig{}
+			if transport := tls.Transport; transport != nil {
+				dst.Spec.Security.Tls.Transport = &TlsConfigTransport{
+					TlsCertificates: certificatesFromV1(transport.Generate, transport.TlsCertificateConfig),
+					PerNode:         transport.PerNode,
+					NodesDn:         transport.NodesDn,
+					AdminDn:         transport.AdminDn,
+				}
+			}
+			if http := tls.Http; http != nil {
+				certificates := certificatesFromV1(http.Generate, http.TlsCertificateConfig)
+				dst.Spec.Security.Tls.Http = &certificates
+			}
+		}
+		if config := security.Config; config != nil {
+			dst.Spec.Security.Config = &SecurityConfig{
+				SecurityconfigSecret:   secretRefFromV1(config.SecurityconfigSecret),
+				AdminSecret:            secretRefFromV1(config.AdminSecret),
+				AdminCredentialsSecret: secretRefFromV1(config.AdminCredentialsSecret),
+				UpdateMode:             config.UpdateMode,
+				Backup:                 config.Backup,
+				GeneratePasswords:      config.GeneratePasswords,
+			}
+		}
+		dst.Spec.Security.Authentication = security.Authentication
+		dst.Spec.Security.Audit = security.Audit
+	}
+
+	dst.Spec.NodePools = nil
+	for _, nodePool := range src.Spec.NodePools {
+		converted := NodePool{
+			Component:   nodePool.Component,
+			Replicas:    nodePool.Replicas,
+			Roles:       nodePool.Roles,
+			DiskSize:    nodePool.DiskSize,
+			Persistence: nodePool.Persistence,
+			Jvm:         nodePool.Jvm,
+			Pod: PodTemplate{
+				Resources:    nodePool.Resources,
+				NodeSelector: nodePool.NodeSelector,
+				Tolerations:  nodePool.Tolerations,
+				Affinity:     nodePool.Affinity,
+			},
+			Labels:        nodePool.Labels,
+			Env:           nodePool.Env,
+			Plugins:       nodePool.Plugins,
+			RemovePlugins: nodePool.RemovePlugins,
+		}
+		if converted.Config, err = restoreConfig(stored.NodePools[nodePool.Component], nodePool.AdditionalConfig); err != nil {
+			return err
+		}
+		dst.Spec.NodePools = append(dst.Spec.NodePools, converted)
+	}
+	dst.Spec.UpgradeStrategy = src.Spec.UpgradeStrategy
+	dst.Spec.MaintenanceWindows = src.Spec.MaintenanceWindows
+	dst.Spec.RemoteClusters = src.Spec.RemoteClusters
+	dst.Spec.CrossNamespaceReferences = src.Spec.CrossNamespaceReferences
+
+	status := src.Status
+	dst.Status = ClusterStatus{
+		Phase:          ClusterPhase(status.Phase),
+		Version:        status.Version,
+		Initialized:    status.Initialized,
+		Conditions:     status.Conditions,
+		UpgradeHistory: status.UpgradeHistory,
+		VersionUpdate:  status.VersionUpdate,
+		Maintenance:    status.Maintenance,
+		RemoteClusters: status.RemoteClusters,
+	}
+	for _, component := range status.ComponentsStatus {
+		dst.Status.Components = append(dst.Status.Components, ComponentStatus{
+			Component: component.Component,
+			State:     ComponentState(component.Status),
+			Target:    component.Description,
+		})
+	}
+	return nil
+}
+
+func secretRefToV1(ref *SecretRef) corev1.LocalObjectReference {
+	if ref == nil {
+		return corev1.LocalObjectReference{}
+	}
+	return corev1.LocalObjectReference{Name: ref.Name}
+}
+
+func secretRefFromV1(ref corev1.LocalObjectReference) *SecretRef {
+	if ref.Name == "" {
+		return nil
+	}
+	return &SecretRef{Name: ref.Name}
+}
+
+func certificatesToV1(certificates TlsCertificates) opsterv1.TlsCertificateConfig {
+	return opsterv1.TlsCertificateConfig{
+		Secret:   secretRefToV1(certificates.Secret),
+		CaSecret: secretRefToV1(certificates.CaSecret),
+	}
+}
+
+func certificatesFromV1(generate bool, certificates opsterv1.TlsCertificateConfig) TlsCertificates {
+	return TlsCertificates{
+		Generate: generate,
+		Secret:   secretRefFromV1(certificates.Secret),
+		CaSecret: secretRefFromV1(certificates.CaSecret),
+	}
+}
+
+// flattenConfig converts a config to the flat settings of v1. Nested keys are joined with dots,
+// values that are not strings are written as json, which is also valid yaml.
+func flattenConfig(config *runtime.RawExtension) (map[string]string, error) {
+	if config == nil || len(config.Raw) == 0 {
+		return nil, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(config.Raw))
+	decoder.UseNumber()
+	values := map[string]interface{}{}
+	if err := decoder.Decode(&values); err != nil {
+		return nil, err
+	}
+	settings := map[string]string{}
+	if err := flattenValues(settings, "", values); err != nil {
+		return nil, err
+	}
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	return settings, nil
+}
+
+func flattenValues(settings map[string]string, prefix string, values map[string]interface{}) error {
+	for key, value := range values {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			if err := flattenValues(settings, key, typed); err != nil {
+				return err
+			}
+		case string:
+			settings[key] = typed
+		case json.Number:
+			settings[key] = typed.String()
+		case bool:
+			settings[key] = strconv.FormatBool(typed)
+		default:
+			data, err := json.Marshal(typed)
+			if err != nil {
+				return err
+			}
+			settings[key] = string(data)
+		}
+	}
+	return nil
+}
+
+// configToStore returns the config if it can't be rebuilt from its flat settings and has to be stored in the annotation
+func configToStore(config *runtime.RawExtension, settings map[string]string) *runtime.RawExtension {
+	if config == nil {
+		return nil
+	}
+	if rebuilt, err := flatConfig(settings); err == nil && rebuilt != nil && bytes.Equal(rebuilt.Raw, config.Raw) {
+		return nil
+	}
+	return config
+}
+
+// restoreConfig returns the config stored by ConvertTo if the settings weren't changed in v1 since,
+// otherwise a config with the flat settings
+func restoreConfig(stored *runtime.RawExtension, settings map[string]string) (*runtime.RawExtension, error) {
+	if stored != nil {
+		if flat, err := flattenConfig(stored); err == nil && equality.Semantic.DeepEqual(flat, settings) {
+			return stored, nil
+		}
+	}
+	return flatConfig(settings)
+}
+
+func flatConfig(settings map[string]string) (*runtime.RawExtension, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.RawExtension{Raw: data}, nil
+}
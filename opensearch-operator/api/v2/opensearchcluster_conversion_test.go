@@ -0,0 +1,337 @@
+package v2
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	opsterv1 "opensearch.opster.io/api/v1"
+)
+
+var _ = Describe("OpenSearchCluster conversion", func() {
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("2Gi"),
+		},
+	}
+	tolerations := []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}}
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}},
+				}},
+			},
+		},
+	}
+	status := func() opsterv1.ClusterStatus {
+		return opsterv1.ClusterStatus{
+			Phase:       opsterv1.PhaseRunning,
+			Version:     "2.0.0",
+			Initialized: true,
+			ComponentsStatus: []opsterv1.ComponentStatus{
+				{Component: "Upgrader", Status: "Upgrading", Description: "data"},
+			},
+			Conditions: []metav1.Condition{
+				{Type: opsterv1.ConditionUpgrading, Status: metav1.ConditionTrue, Reason: "Upgrading", LastTransitionTime: metav1.NewTime(time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC))},
+			},
+			UpgradeHistory: []opsterv1.UpgradeHistoryEntry{
+				{FromVersion: "1.3.0", ToVersion: "2.0.0", StartTime: metav1.NewTime(time.Date(2022, 8, 1, 0, 0, 0, 0, time.UTC)), Result: opsterv1.UpgradeInProgress},
+			},
+			VersionUpdate: &opsterv1.VersionUpdateStatus{AvailableVersion: "2.1.0"},
+			Maintenance: &opsterv1.MaintenanceStatus{
+				DeferredActions: []opsterv1.DeferredAction{{Action: opsterv1.DeferredRestart, NodePool: "data"}},
+			},
+			RemoteClusters: []opsterv1.RemoteClusterStatus{
+				{Alias: "logs", Seeds: []string{"logs-discovery.logs.svc:9300"}, Connected: true, NumNodesConnected: 3},
+			},
+		}
+	}
+
+	newV1Cluster := func() *opsterv1.OpenSearchCluster {
+		return &opsterv1.OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "conversion",
+				Namespace:   "default",
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Spec: opsterv1.ClusterSpec{
+				General: opsterv1.GeneralConfig{
+					ImageSpec:        &opsterv1.ImageSpec{Image: pointer.String("example.com/opensearch:2.0.0")},
+					HttpPort:         9200,
+					Vendor:           "opensearch",
+					Version:          "2.0.0",
+					ServiceName:      "conversion",
+					SetVMMaxMapCount: true,
+					DefaultRepo:      pointer.String("docker.io/opensearchproject"),
+					AdditionalConfig: map[string]string{"cluster.routing.allocation.awareness.attributes": "zone"},
+					DrainDataNodes:   true,
+					Plugins:          []opsterv1.Plugin{{Name: "repository-s3"}},
+					RemovePlugins:    []string{"opensearch-performance-analyzer"},
+					Keystore:         []opsterv1.KeystoreValue{{Secret: corev1.LocalObjectReference{Name: "s3-credentials"}}},
+				},
+				ConfMgmt: opsterv1.ConfMgmt{
+					Monitoring:     true,
+					VerUpdate:      true,
+					VersionChannel: &opsterv1.VersionChannel{Channel: "2.x"},
+				},
+				Bootstrap: opsterv1.BootstrapConfig{
+					Resources:    resources,
+					Tolerations:  tolerations,
+					NodeSelector: map[string]string{"pool": "bootstrap"},
+					Affinity:     affinity,
+					Jvm:          "-Xmx512M -Xms512M",
+				},
+				Dashboards: opsterv1.DashboardsConfig{
+					Enable:   true,
+					Replicas: 1,
+					Version:  "2.0.0",
+					Port:     5601,
+					Tls: &opsterv1.DashboardsTlsConfig{
+						Enable:               true,
+						TlsCertificateConfig: opsterv1.TlsCertificateConfig{Secret: corev1.LocalObjectReference{Name: "dashboards-tls"}},
+					},
+					Resources:                   resources,
+					AdditionalConfig:            map[string]string{"server.basePath": "/dashboards"},
+					OpensearchCredentialsSecret: corev1.LocalObjectReference{Name: "dashboards-credentials"},
+					Env:                         []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+					Tolerations:                 tolerations,
+				},
+				Security: &opsterv1.Security{
+					Tls: &opsterv1.TlsConfig{
+						Transport: &opsterv1.TlsConfigTransport{
+							TlsCertificateConfig: opsterv1.TlsCertificateConfig{
+								Secret:   corev1.LocalObjectReference{Name: "transport-tls"},
+								CaSecret: corev1.LocalObjectReference{Name: "ca"},
+							},
+							NodesDn: []string{"CN=node"},
+							AdminDn: []string{"CN=admin"},
+						},
+						Http: &opsterv1.TlsConfigHttp{Generate: true},
+					},
+					Config: &opsterv1.SecurityConfig{
+						SecurityconfigSecret:   corev1.LocalObjectReference{Name: "securityconfig"},
+						AdminSecret:            corev1.LocalObjectReference{Name: "admin-cert"},
+						AdminCredentialsSecret: corev1.LocalObjectReference{Name: "admin-credentials"},
+						UpdateMode:             opsterv1.SecurityconfigUpdateIncremental,
+						Backup:                 &opsterv1.SecurityconfigBackup{Schedule: "0 3 * * *", KeepVersions: 7},
+						GeneratePasswords:      true,
+					},
+					Authentication: &opsterv1.AuthenticationConfig{
+						Basic: &opsterv1.BasicAuthConfig{Enabled: true},
+						OIDC: &opsterv1.OIDCAuthConfig{
+							ConnectURL: "https://idp.example.com/.well-known/openid-configuration",
+							ClientID:   "dashboards",
+							ClientSecret: corev1.SecretKeySelector{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "oidc"},
+								Key:                  "clientSecret",
+							},
+						},
+					},
+					Audit: &opsterv1.AuditConfig{
+						Type:                   opsterv1.AuditSinkExternal,
+						External:               &opsterv1.AuditExternalSink{OpensearchRef: &opsterv1.ClusterReference{Name: "audit", Namespace: "logging"}},
+						DisabledRestCategories: []opsterv1.AuditCategory{"AUTHENTICATED"},
+						Compliance:             &opsterv1.AuditComplianceConfig{ReadWatchedFields: map[string][]string{"customers": {"email"}}},
+					},
+				},
+				NodePools: []opsterv1.NodePool{
+					{
+						Component:        "masters",
+						Replicas:         3,
+						DiskSize:         "30Gi",
+						Resources:        resources,
+						Jvm:              "-Xmx1024M -Xms1024M",
+						Roles:            []string{"cluster_manager", "data"},
+						Tolerations:      tolerations,
+						NodeSelector:     map[string]string{"pool": "masters"},
+						Affinity:         affinity,
+						Persistence:      &opsterv1.PersistenceConfig{PersistenceSource: opsterv1.PersistenceSource{PVC: &opsterv1.PVCSource{StorageClassName: "fast"}}},
+						AdditionalConfig: map[string]string{"node.attr.zone": "a"},
+						Labels:           map[string]string{"team": "search"},
+						Env:              []corev1.EnvVar{{Name: "FOO", Value: "bar"}},
+					},
+				},
+				UpgradeStrategy: &opsterv1.UpgradeStrategy{PoolOrder: []string{"masters"}},
+				MaintenanceWindows: []opsterv1.MaintenanceWindow{
+					{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+				},
+				RemoteClusters: []opsterv1.RemoteCluster{
+					{Alias: "logs", OpensearchRef: &opsterv1.ClusterReference{Name: "logs", Namespace: "logs"}},
+					{Alias: "legacy", Seeds: []string{"legacy.example.com:9300"}},
+				},
+				CrossNamespaceReferences: &opsterv1.CrossNamespaceReferences{
+					Namespaces: []string{"team-a"},
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"opensearch-access": "true"},
+					},
+				},
+			},
+			Status: status(),
+		}
+	}
+
+	newV2Cluster := func() *OpenSearchCluster {
+		return &OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "conversion",
+				Namespace: "default",
+			},
+			Spec: ClusterSpec{
+				General: GeneralConfig{
+					HttpPort:    9200,
+					Version:     "2.0.0",
+					ServiceName: "conversion",
+					DefaultRepo: pointer.String("docker.io/opensearchproject"),
+					Config: &runtime.RawExtension{
+						Raw: []byte(`{"cluster":{"routing":{"allocation":{"awareness":{"attributes":"zone"}}}},"indices.query.bool.max_clause_count":2048,"action.auto_create_index":false,"node.attr.zones":["a","b"]}`),
+					},
+				},
+				Management: ManagementConfig{
+					VersionUpdates: VersionUpdates{
+						Enable:  true,
+						Channel: &opsterv1.VersionChannel{Channel: "2.x"},
+					},
+				},
+				Bootstrap: BootstrapConfig{
+					Pod: PodTemplate{Resources: resources, Tolerations: tolerations},
+					Jvm: "-Xmx512M -Xms512M",
+				},
+				Dashboards: DashboardsConfig{
+					Enable:   true,
+					Replicas: 1,
+					Version:  "2.0.0",
+					Port:     5601,
+					Pod:      PodTemplate{Resources: resources, NodeSelector: map[string]string{"pool": "dashboards"}, Affinity: affinity},
+					Tls: &DashboardsTlsConfig{
+						Enable:          true,
+						TlsCertificates: TlsCertificates{Generate: true, CaSecret: &SecretRef{Name: "ca"}},
+					},
+					Config:                      &runtime.RawExtension{Raw: []byte(`{"server":{"basePath":"/dashboards"}}`)},
+					OpensearchCredentialsSecret: &SecretRef{Name: "dashboards-credentials"},
+				},
+				Security: &Security{
+					Tls: &TlsConfig{
+						Transport: &TlsConfigTransport{
+							TlsCertificates: TlsCertificates{Generate: true},
+							PerNode:         true,
+						},
+						Http: &TlsCertificates{Secret: &SecretRef{Name: "http-tls"}},
+					},
+					Config: &SecurityConfig{
+						SecurityconfigSecret:   &SecretRef{Name: "securityconfig"},
+						AdminCredentialsSecret: &SecretRef{Name: "admin-credentials"},
+					},
+				},
+				NodePools: []NodePool{
+					{
+						Component: "masters",
+						Replicas:  3,
+						Roles:     []string{"cluster_manager", "data"},
+						DiskSize:  "30Gi",
+						Jvm:       "-Xmx1024M -Xms1024M",
+						Pod:       PodTemplate{Resources: resources, Tolerations: tolerations, Affinity: affinity},
+						Labels:    map[string]string{"team": "search"},
+						Config:    &runtime.RawExtension{Raw: []byte(`{"node":{"attr":{"zone":"a"}}}`)},
+					},
+					{
+						Component: "data",
+						Replicas:  2,
+						Roles:     []string{"data"},
+						DiskSize:  "100Gi",
+						Jvm:       "-Xmx512M -Xms512M",
+						Config:    &runtime.RawExtension{Raw: []byte(`{"node.attr.temp":"hot"}`)},
+					},
+				},
+			},
+			Status: ClusterStatus{
+				Phase:       PhaseRunning,
+				Version:     "2.0.0",
+				Initialized: true,
+				Components: []ComponentStatus{
+					{Component: "Restarter", State: ComponentRunning, Target: "data"},
+				},
+			},
+		}
+	}
+
+	Context("When converting a v1 cluster", func() {
+		It("should convert it back without changes", func() {
+			original := newV1Cluster()
+			converted := &OpenSearchCluster{}
+			Expect(converted.ConvertFrom(original.DeepCopy())).To(Succeed())
+
+			roundTrip := &opsterv1.OpenSearchCluster{}
+			Expect(converted.ConvertTo(roundTrip)).To(Succeed())
+			Expect(roundTrip).To(Equal(original))
+		})
+
+		It("should restructure the spec", func() {
+			converted := &OpenSearchCluster{}
+			Expect(converted.ConvertFrom(newV1Cluster())).To(Succeed())
+
+			Expect(converted.Spec.Management.VersionUpdates.Enable).To(BeTrue())
+			Expect(converted.Spec.Management.VersionUpdates.Channel.Channel).To(Equal("2.x"))
+			Expect(converted.Spec.General.Config.Raw).To(MatchJSON(`{"cluster.routing.allocation.awareness.attributes":"zone"}`))
+			Expect(converted.Spec.NodePools[0].Pod.NodeSelector).To(Equal(map[string]string{"pool": "masters"}))
+			Expect(converted.Spec.Dashboards.Tls.Secret).To(Equal(&SecretRef{Name: "dashboards-tls"}))
+			Expect(converted.Spec.Dashboards.Tls.CaSecret).To(BeNil())
+			Expect(converted.Spec.Security.Tls.Http.Secret).To(BeNil())
+			Expect(converted.Status.Components).To(Equal([]ComponentStatus{
+				{Component: "Upgrader", State: ComponentUpgrading, Target: "data"},
+			}))
+		})
+	})
+
+	Context("When converting a v2 cluster", func() {
+		It("should convert it back without changes", func() {
+			original := newV2Cluster()
+			hub := &opsterv1.OpenSearchCluster{}
+			Expect(original.DeepCopy().ConvertTo(hub)).To(Succeed())
+
+			roundTrip := &OpenSearchCluster{}
+			Expect(roundTrip.ConvertFrom(hub)).To(Succeed())
+			Expect(roundTrip).To(Equal(original))
+		})
+
+		It("should flatten the config for v1", func() {
+			hub := &opsterv1.OpenSearchCluster{}
+			Expect(newV2Cluster().ConvertTo(hub)).To(Succeed())
+
+			Expect(hub.Spec.General.AdditionalConfig).To(Equal(map[string]string{
+				"cluster.routing.allocation.awareness.attributes": "zone",
+				"indices.query.bool.max_clause_count":             "2048",
+				"action.auto_create_index":                        "false",
+				"node.attr.zones":                                 `["a","b"]`,
+			}))
+			Expect(hub.Spec.NodePools[1].AdditionalConfig).To(Equal(map[string]string{"node.attr.temp": "hot"}))
+			Expect(hub.Spec.Dashboards.Tls.Generate).To(BeTrue())
+			Expect(hub.Spec.Dashboards.Tls.CaSecret.Name).To(Equal("ca"))
+			Expect(hub.Annotations).To(HaveKey(ConfigAnnotation))
+			Expect(hub.Annotations[ConfigAnnotation]).NotTo(ContainSubstring("node.attr.temp"))
+		})
+
+		It("should use the v1 settings if they were changed", func() {
+			hub := &opsterv1.OpenSearchCluster{}
+			Expect(newV2Cluster().ConvertTo(hub)).To(Succeed())
+			hub.Spec.NodePools[0].AdditionalConfig["node.attr.zone"] = "b"
+
+			converted := &OpenSearchCluster{}
+			Expect(converted.ConvertFrom(hub)).To(Succeed())
+			Expect(converted.Annotations).NotTo(HaveKey(ConfigAnnotation))
+			Expect(converted.Spec.General.Config).To(Equal(newV2Cluster().Spec.General.Config))
+			Expect(converted.Spec.NodePools[0].Config.Raw).To(MatchJSON(`{"node.attr.zone":"b"}`))
+		})
+
+		It("should reject a config that is not an object", func() {
+			cluster := newV2Cluster()
+			cluster.Spec.General.Config = &runtime.RawExtension{Raw: []byte(`["a"]`)}
+			Expect(cluster.ConvertTo(&opsterv1.OpenSearchCluster{})).NotTo(Succeed())
+		})
+	})
+})
@@ -0,0 +1,297 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	opsterv1 "opensearch.opster.io/api/v1"
+)
+
+// ClusterPhase is the lifecycle phase of a cluster
+type ClusterPhase string
+
+const (
+	PhasePending ClusterPhase = "PENDING"
+	PhaseRunning ClusterPhase = "RUNNING"
+)
+
+// Types of the conditions of a cluster
+const (
+	ConditionUpgrading         = "Upgrading"
+	ConditionUpgradeCanary     = "UpgradeCanary"
+	ConditionUpgradePreflight  = "UpgradePreflight"
+	ConditionVersionUpdate     = "VersionUpdate"
+	ConditionPaused            = "Paused"
+	ConditionPluginsCompatible = "PluginsCompatible"
+)
+
+// ComponentState is the state reported by a part of the operator
+type ComponentState string
+
+const (
+	ComponentPending                ComponentState = "Pending"
+	ComponentRunning                ComponentState = "Running"
+	ComponentUpgrading              ComponentState = "Upgrading"
+	ComponentUpgraded               ComponentState = "Upgraded"
+	ComponentFinished               ComponentState = "Finished"
+	ComponentFailed                 ComponentState = "Failed"
+	ComponentExcluded               ComponentState = "Excluded"
+	ComponentDrained                ComponentState = "Drained"
+	ComponentVotingExcluded         ComponentState = "VotingExcluded"
+	ComponentVotingExclusionCleanup ComponentState = "VotingExclusionCleanup"
+)
+
+// SecretRef references a secret in the namespace of the cluster
+type SecretRef struct {
+	//+kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+type GeneralConfig struct {
+	*opsterv1.ImageSpec `json:",inline,omitempty"`
+	//+kubebuilder:default=9200
+	HttpPort int32 `json:"httpPort,omitempty"`
+	//+kubebuilder:validation:Enum=Opensearch;Op;OP;os;opensearch
+	Vendor           string `json:"vendor,omitempty"`
+	Version          string `json:"version,omitempty"`
+	ServiceAccount   string `json:"serviceAccount,omitempty"`
+	ServiceName      string `json:"serviceName"`
+	SetVMMaxMapCount bool   `json:"setVMMaxMapCount,omitempty"`
+	//+kubebuilder:default="docker.io/opensearchproject"
+	DefaultRepo *string `json:"defaultRepo,omitempty"`
+	// Extra settings to add to the opensearch.yml of all nodes. Nested objects and flat keys like cluster.routing.allocation.awareness.attributes can be mixed
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Config *runtime.RawExtension `json:"config,omitempty"`
+	// Drain data nodes controls whether to drain data notes on rolling restart operations
+	DrainDataNodes bool     `json:"drainDataNodes,omitempty"`
+	PluginsList    []string `json:"pluginsList,omitempty"`
+	// Plugins to install on all nodes of the cluster
+	Plugins []opsterv1.Plugin `json:"plugins,omitempty"`
+	// Plugins bundled with the image to remove from all nodes of the cluster
+	RemovePlugins []string `json:"removePlugins,omitempty"`
+	// Populate opensearch keystore before startup
+	Keystore []opsterv1.KeystoreValue `json:"keystore,omitempty"`
+	// Additional volumes to mount to all pods in the cluster
+	AdditionalVolumes []opsterv1.AdditionalVolume `json:"additionalVolumes,omitempty"`
+}
+
+// PodTemplate defines the resources and the scheduling of pods
+type PodTemplate struct {
+	Resources    corev1.ResourceRequirements `json:"resources,omitempty"`
+	NodeSelector map[string]string           `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration         `json:"tolerations,omitempty"`
+	Affinity     *corev1.Affinity            `json:"affinity,omitempty"`
+}
+
+type NodePool struct {
+	Component string   `json:"component"`
+	Replicas  int32    `json:"replicas"`
+	Roles     []string `json:"roles"`
+	//+kubebuilder:default="30Gi"
+	DiskSize    string                      `json:"diskSize,omitempty"`
+	Persistence *opsterv1.PersistenceConfig `json:"persistence,omitempty"`
+	//+kubebuilder:default="-Xmx512M -Xms512M"
+	Jvm string `json:"jvm,omitempty"`
+	// Resources and scheduling of the pods of the node pool
+	Pod    PodTemplate       `json:"pod,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Env    []corev1.EnvVar   `json:"env,omitempty"`
+	// Extra settings to add to the opensearch.yml of the nodes of the node pool
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Config *runtime.RawExtension `json:"config,omitempty"`
+	// Plugins to install on the nodes of the node pool in addition to the plugins of the cluster
+	Plugins []opsterv1.Plugin `json:"plugins,omitempty"`
+	// Plugins to remove from the nodes of the node pool, including plugins of the cluster
+	RemovePlugins []string `json:"removePlugins,omitempty"`
+}
+
+// ManagementConfig defines which additional services will be deployed
+type ManagementConfig struct {
+	AutoScaler  bool `json:"autoScaler,omitempty"`
+	Monitoring  bool `json:"monitoring,omitempty"`
+	SmartScaler bool `json:"smartScaler,omitempty"`
+	// Automatic version updates of the cluster
+	VersionUpdates VersionUpdates `json:"versionUpdates,omitempty"`
+}
+
+type VersionUpdates struct {
+	Enable bool `json:"enable,omitempty"`
+	// Versions the cluster is automatically upgraded to
+	Channel *opsterv1.VersionChannel `json:"channel,omitempty"`
+}
+
+type BootstrapConfig struct {
+	// Resources and scheduling of the bootstrap pod
+	Pod PodTemplate `json:"pod,omitempty"`
+	//+kubebuilder:default="-Xmx512M -Xms512M"
+	Jvm string `json:"jvm,omitempty"`
+}
+
+type DashboardsConfig struct {
+	*opsterv1.ImageSpec `json:",inline,omitempty"`
+	Enable              bool   `json:"enable,omitempty"`
+	Replicas            int32  `json:"replicas"`
+	Version             string `json:"version"`
+	// Port of the dashboards http interface
+	//+kubebuilder:default=5601
+	Port int32 `json:"port,omitempty"`
+	// Resources and scheduling of the dashboards pods
+	Pod PodTemplate          `json:"pod,omitempty"`
+	Tls *DashboardsTlsConfig `json:"tls,omitempty"`
+	// Extra settings to add to the opensearch_dashboards.yml
+	//+kubebuilder:pruning:PreserveUnknownFields
+	Config *runtime.RawExtension `json:"config,omitempty"`
+	// Secret that contains fields username and password for dashboards to use to login to opensearch, must only be supplied if a custom securityconfig is provided
+	OpensearchCredentialsSecret *SecretRef                  `json:"opensearchCredentialsSecret,omitempty"`
+	Env                         []corev1.EnvVar             `json:"env,omitempty"`
+	AdditionalVolumes           []opsterv1.AdditionalVolume `json:"additionalVolumes,omitempty"`
+}
+
+type DashboardsTlsConfig struct {
+	// Enable HTTPS for Dashboards
+	Enable          bool `json:"enable,omitempty"`
+	TlsCertificates `json:",inline"`
+}
+
+// Security defines options for managing the opensearch-security plugin
+type Security struct {
+	// Certificates of the transport and http interfaces
+	Tls *TlsConfig `json:"tls,omitempty"`
+	// Configuration of the security plugin and the credentials used to apply it
+	Config *SecurityConfig `json:"config,omitempty"`
+	// Authentication backends the operator renders into the config.yml of the securityconfig
+	Authentication *opsterv1.AuthenticationConfig `json:"authentication,omitempty"`
+	// Audit logging of the security plugin, rendered into opensearch.yml and the audit.yml of the securityconfig
+	Audit *opsterv1.AuditConfig `json:"audit,omitempty"`
+}
+
+type TlsConfig struct {
+	Transport *TlsConfigTransport `json:"transport,omitempty"`
+	Http      *TlsCertificates    `json:"http,omitempty"`
+}
+
+// TlsCertificates defines where the certificates of an interface come from
+type TlsCertificates struct {
+	// If set to true the operator will generate a CA and certificates, if false a secret with existing certificates must be supplied
+	Generate bool `json:"generate,omitempty"`
+	// TLS secret that contains ca.crt, tls.key and tls.crt data. If ca.crt is in a different secret provide it via the caSecret field
+	Secret *SecretRef `json:"secret,omitempty"`
+	// Secret that contains the ca certificate as ca.crt. If this and generate=true is set the existing CA cert from that secret is used to generate the node certs. In this case must contain ca.crt and ca.key fields
+	CaSecret *SecretRef `json:"caSecret,omitempty"`
+}
+
+type TlsConfigTransport struct {
+	TlsCertificates `json:",inline"`
+	// Generate a certificate per node instead of one for all nodes
+	PerNode bool `json:"perNode,omitempty"`
+	// Allowed Certificate DNs for nodes, only used when existing certificates are provided
+	NodesDn []string `json:"nodesDn,omitempty"`
+	// DNs of certificates that should have admin access, mainly used for securityconfig updates via securityadmin.sh, only used when existing certificates are provided
+	AdminDn []string `json:"adminDn,omitempty"`
+}
+
+type SecurityConfig struct {
+	// Secret that contains the different yml files of the opensearch-security config (config.yml, internal_users.yml, ...)
+	SecurityconfigSecret *SecretRef `json:"securityConfigSecret,omitempty"`
+	// TLS Secret that contains a client certificate (tls.key, tls.crt, ca.crt) with admin rights in the opensearch cluster. Must be set if transport certificates are provided by user and not generated
+	AdminSecret *SecretRef `json:"adminSecret,omitempty"`
+	// Secret that contains fields username and password to be used by the operator to access the opensearch cluster. Must be set if custom securityconfig is provided.
+	AdminCredentialsSecret *SecretRef `json:"adminCredentialsSecret,omitempty"`
+	// How changes of the securityconfig are applied, Full replaces the whole security config, Incremental applies only
+	// the changed entries through the security REST API
+	//+kubebuilder:validation:Enum=Full;Incremental
+	//+kubebuilder:default=Full
+	UpdateMode opsterv1.SecurityconfigUpdateMode `json:"updateMode,omitempty"`
+	// Periodically export the live security config of the cluster into secrets
+	Backup *opsterv1.SecurityconfigBackup `json:"backup,omitempty"`
+	// Generate random passwords for the built-in admin and kibanaserver users instead of using the demo credentials
+	GeneratePasswords bool `json:"generatePasswords,omitempty"`
+}
+
+// ClusterSpec defines the desired state of OpenSearchCluster
+type ClusterSpec struct {
+	General    GeneralConfig    `json:"general,omitempty"`
+	Management ManagementConfig `json:"management,omitempty"`
+	Bootstrap  BootstrapConfig  `json:"bootstrap,omitempty"`
+	Dashboards DashboardsConfig `json:"dashboards,omitempty"`
+	Security   *Security        `json:"security,omitempty"`
+	NodePools  []NodePool       `json:"nodePools"`
+	// Controls how rolling upgrades are performed
+	UpgradeStrategy *opsterv1.UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// Time windows for disruptive operations like restarts, upgrades and scale downs, if empty they are done at any time
+	MaintenanceWindows []opsterv1.MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// Remote clusters that can be used in cross-cluster searches
+	RemoteClusters []opsterv1.RemoteCluster `json:"remoteClusters,omitempty"`
+	// Namespaces whose users, roles and role bindings may reference the cluster, by default only the namespace of the cluster
+	CrossNamespaceReferences *opsterv1.CrossNamespaceReferences `json:"crossNamespaceReferences,omitempty"`
+}
+
+// ClusterStatus defines the observed state of OpenSearchCluster
+type ClusterStatus struct {
+	Phase       ClusterPhase `json:"phase,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	Initialized bool         `json:"initialized,omitempty"`
+	// Conditions of the cluster, e.g. the progress of an upgrade
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+	// Progress of the parts of the operator working on the cluster, e.g. the upgrader
+	Components []ComponentStatus `json:"components,omitempty"`
+	// The most recent upgrades of the cluster
+	UpgradeHistory []opsterv1.UpgradeHistoryEntry `json:"upgradeHistory,omitempty"`
+	// State of the automatic version updates
+	VersionUpdate *opsterv1.VersionUpdateStatus `json:"versionUpdate,omitempty"`
+	// Next maintenance window and the actions waiting for it
+	Maintenance *opsterv1.MaintenanceStatus `json:"maintenance,omitempty"`
+	// Connection state of the remote clusters
+	RemoteClusters []opsterv1.RemoteClusterStatus `json:"remoteClusters,omitempty"`
+}
+
+type ComponentStatus struct {
+	// Part of the operator, e.g. Upgrader, Restarter or Scaler
+	Component string         `json:"component,omitempty"`
+	State     ComponentState `json:"state,omitempty"`
+	// Node pool or other object the state refers to
+	Target string `json:"target,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:shortName=os;opensearch
+//+kubebuilder:unservedversion
+
+// OpenSearchCluster is the Schema for the opensearchclusters API
+type OpenSearchCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec   `json:"spec,omitempty"`
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// OpenSearchClusterList contains a list of OpenSearchCluster
+type OpenSearchClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OpenSearchCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OpenSearchCluster{}, &OpenSearchClusterList{})
+}
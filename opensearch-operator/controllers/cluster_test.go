@@ -287,6 +287,13 @@ var _ = Describe("Cluster Reconciler", func() {
 				Expect(HasOwnerReference(&service, &OpensearchCluster)).To(BeTrue())
 			}
 		})
+		It("should use the OnDelete update strategy for all node pools", func() {
+			for _, nodePoolSpec := range OpensearchCluster.Spec.NodePools {
+				sts := appsv1.StatefulSet{}
+				Expect(k8sClient.Get(context.Background(), client.ObjectKey{Namespace: clusterName, Name: clusterName + "-" + nodePoolSpec.Component}, &sts)).To(Succeed())
+				Expect(sts.Spec.UpdateStrategy.Type).To(Equal(appsv1.OnDeleteStatefulSetStrategyType))
+			}
+		})
 		It("should set the version status", func() {
 			Eventually(func() bool {
 				if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&OpensearchCluster), &OpensearchCluster); err != nil {
@@ -18,10 +18,14 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"opensearch.opster.io/pkg/builders"
@@ -29,11 +33,15 @@ import (
 	"opensearch.opster.io/pkg/reconcilers"
 
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -59,6 +67,7 @@ type OpenSearchClusterReconciler struct {
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;create;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=events,verbs=create;update;patch
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
@@ -129,6 +138,16 @@ func (r *OpenSearchClusterReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return ctrl.Result{}, nil
 	}
 
+	// Don't touch the cluster while reconciliation is paused. Deleted clusters are cleaned up even if they are
+	// paused, otherwise the finalizer would block the deletion.
+	if err := r.reconcilePausedCondition(ctx); err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.Instance.ReconcilePaused(opsterv1.PauseAll) {
+		r.Logger.Info("Reconciliation paused")
+		return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
+	}
+
 	/// if crd not deleted started phase 1
 	if r.Instance.Status.Phase == "" {
 		r.Instance.Status.Phase = opsterv1.PhasePending
@@ -155,9 +174,47 @@ func (r *OpenSearchClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.Service{}).
 		Owns(&appsv1.Deployment{}).
 		Owns(&appsv1.StatefulSet{}).
+		// Replication rules change the transport trust of the clusters they connect
+		Watches(&source.Kind{Type: &opsterv1.OpensearchReplicationRule{}}, handler.EnqueueRequestsFromMapFunc(replicationRuleClusters)).
+		Watches(&source.Kind{Type: &opsterv1.OpenSearchCluster{}}, handler.EnqueueRequestsFromMapFunc(remoteClusterPeers)).
 		Complete(r)
 }
 
+// replicationRuleClusters returns the follower and the operator managed leader cluster of a replication rule
+func replicationRuleClusters(obj client.Object) []reconcile.Request {
+	rule, ok := obj.(*opsterv1.OpensearchReplicationRule)
+	if !ok {
+		return nil
+	}
+	requests := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: rule.Spec.OpensearchRef.Name, Namespace: rule.Namespace}},
+	}
+	if rule.Spec.Leader.OpensearchRef != nil {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: rule.Spec.Leader.OpensearchRef.Name, Namespace: rule.Namespace},
+		})
+	}
+	return requests
+}
+
+// remoteClusterPeers returns the operator managed remote clusters of a cluster, they have to trust its transport certificates
+func remoteClusterPeers(obj client.Object) []reconcile.Request {
+	cluster, ok := obj.(*opsterv1.OpenSearchCluster)
+	if !ok {
+		return nil
+	}
+	var requests []reconcile.Request
+	for _, remote := range cluster.Spec.RemoteClusters {
+		if remote.OpensearchRef == nil {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: remote.OpensearchRef.NamespacedName(cluster.Namespace),
+		})
+	}
+	return requests
+}
+
 // delete associated cluster resources //
 func (r *OpenSearchClusterReconciler) deleteExternalResources(ctx context.Context) (ctrl.Result, error) {
 	r.Logger.Info("Deleting resources")
@@ -253,6 +310,10 @@ func (r *OpenSearchClusterReconciler) reconcilePhaseRunning(ctx context.Context)
 		}
 	}
 
+	if err := reconcilers.UpdateMaintenanceStatus(ctx, r.Client, r.Instance); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Run through all sub controllers to create or update all needed objects
 	reconcilerContext := reconcilers.NewReconcilerContext(r.Instance.Spec.NodePools)
 
@@ -311,19 +372,42 @@ func (r *OpenSearchClusterReconciler) reconcilePhaseRunning(ctx context.Context)
 		&reconcilerContext,
 		r.Instance,
 	)
+	remoteClusters := reconcilers.NewRemoteClustersReconciler(
+		r.Client,
+		ctx,
+		r.Recorder,
+		&reconcilerContext,
+		r.Instance,
+	)
+	securityconfigBackup := reconcilers.NewSecurityconfigBackupReconciler(
+		r.Client,
+		ctx,
+		r.Recorder,
+		&reconcilerContext,
+		r.Instance,
+	)
 
-	componentReconcilers := []reconcilers.ComponentReconciler{
-		tls.Reconcile,
-		securityconfig.Reconcile,
-		config.Reconcile,
-		cluster.Reconcile,
-		scaler.Reconcile,
-		dashboards.Reconcile,
-		upgrade.Reconcile,
-		restart.Reconcile,
+	componentReconcilers := []struct {
+		component string
+		reconcile reconcilers.ComponentReconciler
+	}{
+		{opsterv1.PauseTLS, tls.Reconcile},
+		{opsterv1.PauseSecurityconfig, securityconfig.Reconcile},
+		{opsterv1.PauseConfiguration, config.Reconcile},
+		{opsterv1.PauseCluster, cluster.Reconcile},
+		{opsterv1.PauseScaler, scaler.Reconcile},
+		{opsterv1.PauseDashboards, dashboards.Reconcile},
+		{opsterv1.PauseUpgrade, upgrade.Reconcile},
+		{opsterv1.PauseRestart, restart.Reconcile},
+		{opsterv1.PauseRemoteClusters, remoteClusters.Reconcile},
+		{opsterv1.PauseSecurityconfigBackup, securityconfigBackup.Reconcile},
 	}
 	for _, rec := range componentReconcilers {
-		result, err := rec()
+		if r.Instance.ReconcilePaused(rec.component) {
+			r.Logger.V(1).Info("Reconciliation paused", "component", rec.component)
+			continue
+		}
+		result, err := rec.reconcile()
 		if err != nil || result.Requeue {
 			return result, err
 		}
@@ -332,3 +416,34 @@ func (r *OpenSearchClusterReconciler) reconcilePhaseRunning(ctx context.Context)
 	// -------- all resources has been created -----------
 	return ctrl.Result{Requeue: true, RequeueAfter: 30 * time.Second}, nil
 }
+
+// reconcilePausedCondition reflects the pause annotation in the Paused condition
+func (r *OpenSearchClusterReconciler) reconcilePausedCondition(ctx context.Context) error {
+	paused := r.Instance.PausedComponents()
+	existing := meta.FindStatusCondition(r.Instance.Status.Conditions, opsterv1.ConditionPaused)
+	annotations := map[string]string{"cluster-name": r.Instance.GetName()}
+
+	if len(paused) == 0 {
+		if existing == nil || existing.Status == metav1.ConditionFalse {
+			return nil
+		}
+		r.Recorder.AnnotatedEventf(r.Instance, annotations, "Normal", "Paused", "Reconciliation resumed")
+		return reconcilers.UpdateOpensearchCondition(ctx, r.Client, r.Instance, metav1.Condition{
+			Type:    opsterv1.ConditionPaused,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Resumed",
+			Message: "Reconciliation resumed",
+		})
+	}
+
+	message := fmt.Sprintf("Reconciliation paused for: %s", strings.Join(paused, ", "))
+	if existing == nil || existing.Message != message {
+		r.Recorder.AnnotatedEventf(r.Instance, annotations, "Normal", "Paused", "%s", message)
+	}
+	return reconcilers.UpdateOpensearchCondition(ctx, r.Client, r.Instance, metav1.Condition{
+		Type:    opsterv1.ConditionPaused,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReconcilePaused",
+		Message: message,
+	})
+}
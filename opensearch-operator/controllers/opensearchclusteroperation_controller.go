@@ -0,0 +1,77 @@
+/*
+Copyright 2021.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/reconcilers"
+)
+
+// OpensearchClusterOperationReconciler reconciles a OpensearchClusterOperation object
+type OpensearchClusterOperationReconciler struct {
+	client.Client
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+	Instance *opsterv1.OpensearchClusterOperation
+	logr.Logger
+}
+
+//+kubebuilder:rbac:groups=opensearch.opster.io,resources=opensearchclusteroperations,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=opensearch.opster.io,resources=opensearchclusteroperations/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=opensearch.opster.io,resources=opensearchclusteroperations/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+func (r *OpensearchClusterOperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Logger = log.FromContext(ctx).WithValues("clusteroperation", req.NamespacedName)
+	r.Logger.Info("Reconciling OpensearchClusterOperation")
+
+	r.Instance = &opsterv1.OpensearchClusterOperation{}
+	err := r.Get(ctx, req.NamespacedName, r.Instance)
+	if err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Operations only act on the cluster while they run, nothing needs to be cleaned up on deletion
+	if !r.Instance.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	operationReconciler := reconcilers.NewClusterOperationReconciler(
+		ctx,
+		r.Client,
+		r.Recorder,
+		r.Instance,
+	)
+	return operationReconciler.Reconcile()
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *OpensearchClusterOperationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&opsterv1.OpensearchClusterOperation{}).
+		Complete(r)
+}
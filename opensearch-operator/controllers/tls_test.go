@@ -127,14 +127,18 @@ var _ = Describe("TLS Reconciler", func() {
 			}
 		})
 
-		It("should create a security config job", func() {
+		It("should let the nodes initialize the default securityconfig", func() {
+			cm := corev1.ConfigMap{}
+			Eventually(func() string {
+				if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-config", Namespace: namespace}, &cm); err != nil {
+					return ""
+				}
+				return cm.Data["opensearch.yml"]
+			}, timeout, interval).Should(ContainSubstring("plugins.security.allow_default_init_securityindex: true"))
+
+			// The securityconfig is applied by the operator, no update job is created
 			job := batchv1.Job{}
-			Eventually(func() bool {
-				err := k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-update", Namespace: namespace}, &job)
-				return err == nil
-			}, timeout, interval).Should(BeTrue())
-			Expect(len(job.Spec.Template.Spec.Containers[0].VolumeMounts)).Should(BeNumerically(">=", 2))
-			Expect(helpers.CheckVolumeExists(job.Spec.Template.Spec.Volumes, job.Spec.Template.Spec.Containers[0].VolumeMounts, clusterName+"-transport-cert", "transport-cert")).Should((BeTrue()))
+			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-update", Namespace: namespace}, &job)).ToNot(Succeed())
 		})
 	})
 
@@ -18,7 +18,9 @@ require (
 	github.com/onsi/gomega v1.19.0
 	github.com/opensearch-project/opensearch-go v1.1.0
 	github.com/phayes/freeport v0.0.0-20180830031419-95f893ade6f2
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/spf13/cast v1.4.1 // indirect
+	golang.org/x/crypto v0.0.0-20210921155107-089bfa567519
 	golang.org/x/sys v0.0.0-20220330033206-e17cdc41300f // indirect
 	k8s.io/api v0.23.1
 	k8s.io/apimachinery v0.23.1
@@ -26,4 +28,5 @@ require (
 	k8s.io/kube-openapi v0.0.0-20220114203427-a0453230fd26
 	k8s.io/utils v0.0.0-20211208161948-7d6a63dca704
 	sigs.k8s.io/controller-runtime v0.11.0
+	sigs.k8s.io/yaml v1.3.0
 )
@@ -34,6 +34,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	opsterv1 "opensearch.opster.io/api/v1"
+	opsterv2 "opensearch.opster.io/api/v2"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -46,6 +47,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(opsterv1.AddToScheme(scheme))
+	utilruntime.Must(opsterv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -116,6 +118,29 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "OpensearchUserRoleBinding")
 		os.Exit(1)
 	}
+	if err = (&controllers.OpensearchClusterOperationReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("clusteroperation-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OpensearchClusterOperation")
+		os.Exit(1)
+	}
+	if err = (&controllers.OpensearchReplicationRuleReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("replicationrule-controller"),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "OpensearchReplicationRule")
+		os.Exit(1)
+	}
+	// The webhooks need a serving certificate, e.g. from cert-manager, so they are only started if enabled
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = (&opsterv1.OpenSearchCluster{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OpenSearchCluster")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
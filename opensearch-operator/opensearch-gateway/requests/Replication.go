@@ -0,0 +1,19 @@
+package requests
+
+type StartReplication struct {
+	LeaderAlias string            `json:"leader_alias"`
+	LeaderIndex string            `json:"leader_index"`
+	UseRoles    *ReplicationRoles `json:"use_roles,omitempty"`
+}
+
+type AutoFollowRule struct {
+	LeaderAlias string            `json:"leader_alias"`
+	Name        string            `json:"name"`
+	Pattern     string            `json:"pattern,omitempty"`
+	UseRoles    *ReplicationRoles `json:"use_roles,omitempty"`
+}
+
+type ReplicationRoles struct {
+	LeaderClusterRole   string `json:"leader_cluster_role"`
+	FollowerClusterRole string `json:"follower_cluster_role"`
+}
@@ -0,0 +1,18 @@
+package responses
+
+type ClusterStateResponse struct {
+	Metadata ClusterStateMetadata `json:"metadata"`
+}
+
+type ClusterStateMetadata struct {
+	ClusterCoordination ClusterCoordination `json:"cluster_coordination"`
+}
+
+type ClusterCoordination struct {
+	VotingConfigExclusions []VotingConfigExclusion `json:"voting_config_exclusions"`
+}
+
+type VotingConfigExclusion struct {
+	NodeId   string `json:"node_id"`
+	NodeName string `json:"node_name"`
+}
@@ -0,0 +1,7 @@
+package responses
+
+type IndicesSettingsResponse map[string]IndexSettings
+
+type IndexSettings struct {
+	Settings map[string]interface{} `json:"settings"`
+}
@@ -0,0 +1,55 @@
+package responses
+
+const (
+	ReplicationStatusBootstrapping = "BOOTSTRAPPING"
+	ReplicationStatusSyncing       = "SYNCING"
+	ReplicationStatusPaused        = "PAUSED"
+	ReplicationStatusFailed        = "FAILED"
+	ReplicationStatusNotRunning    = "REPLICATION NOT IN PROGRESS"
+)
+
+type ReplicationStatusResponse struct {
+	Status         string                     `json:"status"`
+	Reason         string                     `json:"reason,omitempty"`
+	LeaderAlias    string                     `json:"leader_alias,omitempty"`
+	LeaderIndex    string                     `json:"leader_index,omitempty"`
+	FollowerIndex  string                     `json:"follower_index,omitempty"`
+	SyncingDetails *ReplicationSyncingDetails `json:"syncing_details,omitempty"`
+}
+
+type ReplicationSyncingDetails struct {
+	LeaderCheckpoint   int64 `json:"leader_checkpoint"`
+	FollowerCheckpoint int64 `json:"follower_checkpoint"`
+	SeqNo              int64 `json:"seq_no"`
+}
+
+type AutoFollowStatsResponse struct {
+	AutoFollowStats []AutoFollowRuleStats `json:"autofollow_stats"`
+}
+
+type AutoFollowRuleStats struct {
+	Name                       string   `json:"name"`
+	Pattern                    string   `json:"pattern"`
+	NumSuccessStartReplication int64    `json:"num_success_start_replication"`
+	NumFailedStartReplication  int64    `json:"num_failed_start_replication"`
+	NumFailedLeaderCalls       int64    `json:"num_failed_leader_calls"`
+	FailedIndices              []string `json:"failed_indices"`
+}
+
+type FollowerStatsResponse struct {
+	IndexStats map[string]FollowerIndexStats `json:"index_stats"`
+}
+
+type FollowerIndexStats struct {
+	LeaderCheckpoint   int64 `json:"leader_checkpoint"`
+	FollowerCheckpoint int64 `json:"follower_checkpoint"`
+}
+
+type RemoteInfoResponse map[string]RemoteClusterInfo
+
+type RemoteClusterInfo struct {
+	Connected         bool     `json:"connected"`
+	Mode              string   `json:"mode"`
+	Seeds             []string `json:"seeds"`
+	NumNodesConnected int32    `json:"num_nodes_connected"`
+}
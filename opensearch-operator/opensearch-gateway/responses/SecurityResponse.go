@@ -7,3 +7,23 @@ type GetRoleMappingReponse map[string]requests.RoleMapping
 type GetRoleResponse map[string]requests.Role
 
 type GetUserResponse map[string]requests.User
+
+// SecurityconfigDocumentResponse is a document of the security index, the source contains the base64 encoded
+// config of the type the document is named after
+type SecurityconfigDocumentResponse struct {
+	ID     string            `json:"_id"`
+	Found  bool              `json:"found"`
+	Source map[string]string `json:"_source"`
+}
+
+type SecurityconfigUpdateResponse struct {
+	Nodes struct {
+		Total      int `json:"total"`
+		Successful int `json:"successful"`
+		Failed     int `json:"failed"`
+	} `json:"_nodes"`
+	ConfigUpdateResponse struct {
+		HasFailures  bool `json:"has_failures"`
+		FailuresSize int  `json:"failures_size"`
+	} `json:"configupdate_response"`
+}
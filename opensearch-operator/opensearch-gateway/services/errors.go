@@ -9,6 +9,13 @@ var (
 	ErrClusterHealthOperation   = errors.New("cluster health failed")
 	ErrClusterSettingsOperation = errors.New("cluster settings failed")
 	ErrCatIndicesOperation      = errors.New("cat indices failed")
+	ErrVotingConfigOperation    = errors.New("voting config exclusions failed")
+	ErrIndicesSettingsOperation = errors.New("indices settings failed")
+	ErrRerouteOperation         = errors.New("cluster reroute failed")
+	ErrFlushOperation           = errors.New("flush failed")
+	ErrRemoteInfoOperation      = errors.New("remote cluster info failed")
+	ErrReplicationOperation     = errors.New("replication failed")
+	ErrSecurityconfigOperation  = errors.New("securityconfig update failed")
 )
 
 func ErrClusterHealthGetFailed(resp string) error {
@@ -22,3 +29,31 @@ func ErrClusterSettingsGetFailed(resp string) error {
 func ErrCatIndicesFailed(resp string) error {
 	return fmt.Errorf("%w: %s", ErrCatIndicesOperation, resp)
 }
+
+func ErrVotingConfigExclusionsFailed(resp string) error {
+	return fmt.Errorf("%w: %s", ErrVotingConfigOperation, resp)
+}
+
+func ErrIndicesSettingsGetFailed(resp string) error {
+	return fmt.Errorf("get error %w: %s", ErrIndicesSettingsOperation, resp)
+}
+
+func ErrRerouteFailed(resp string) error {
+	return fmt.Errorf("%w: %s", ErrRerouteOperation, resp)
+}
+
+func ErrFlushFailed(resp string) error {
+	return fmt.Errorf("%w: %s", ErrFlushOperation, resp)
+}
+
+func ErrRemoteInfoGetFailed(resp string) error {
+	return fmt.Errorf("get error %w: %s", ErrRemoteInfoOperation, resp)
+}
+
+func ErrReplicationFailed(resp string) error {
+	return fmt.Errorf("%w: %s", ErrReplicationOperation, resp)
+}
+
+func ErrSecurityconfigUpdateFailed(resp string) error {
+	return fmt.Errorf("%w: %s", ErrSecurityconfigOperation, resp)
+}
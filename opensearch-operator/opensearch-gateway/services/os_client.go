@@ -20,6 +20,9 @@ const (
 	headerContentType = "Content-Type"
 
 	jsonContentHeader = "application/json"
+
+	// SecurityIndex is the index the security plugin stores its config in
+	SecurityIndex = ".opendistro_security"
 )
 
 var (
@@ -248,6 +251,35 @@ func (client *OsClusterClient) ReRouteShard(rerouteJson string) (responses.Clust
 	return response, err
 }
 
+// RetryFailedShards retries the allocation of shards that have been blocked due to too many subsequent failures
+func (client *OsClusterClient) RetryFailedShards() error {
+	req := opensearchapi.ClusterRerouteRequest{RetryFailed: pointer.BoolPtr(true)}
+	resp, err := req.Do(context.Background(), client.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrRerouteFailed(resp.String())
+	}
+	return nil
+}
+
+func (client *OsClusterClient) FlushIndices() error {
+	req := opensearchapi.IndicesFlushRequest{}
+	resp, err := req.Do(context.Background(), client.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrFlushFailed(resp.String())
+	}
+	return nil
+}
+
 func (client *OsClusterClient) GetClusterHealth() (responses.ClusterHealthResponse, error) {
 	req := opensearchapi.ClusterHealthRequest{
 		Timeout: 10 * time.Second,
@@ -289,6 +321,81 @@ func (client *OsClusterClient) IndexExists(indexName string) (bool, error) {
 	return true, nil
 }
 
+func (client *OsClusterClient) GetIndicesSettings(names []string) (responses.IndicesSettingsResponse, error) {
+	req := opensearchapi.IndicesGetSettingsRequest{
+		Index:           []string{"_all"},
+		Name:            names,
+		ExpandWildcards: "all",
+		FlatSettings:    pointer.BoolPtr(true),
+		Human:           true,
+	}
+	settingsRes, err := req.Do(context.Background(), client.client)
+	var response responses.IndicesSettingsResponse
+	if err != nil {
+		return response, err
+	}
+	defer settingsRes.Body.Close()
+
+	if settingsRes.IsError() {
+		return response, ErrIndicesSettingsGetFailed(settingsRes.String())
+	}
+
+	err = json.NewDecoder(settingsRes.Body).Decode(&response)
+	return response, err
+}
+
+func (client *OsClusterClient) GetVotingConfigExclusions() ([]responses.VotingConfigExclusion, error) {
+	req := opensearchapi.ClusterStateRequest{
+		Metric:     []string{"metadata"},
+		FilterPath: []string{"metadata.cluster_coordination.voting_config_exclusions"},
+	}
+	stateRes, err := req.Do(context.Background(), client.client)
+	var response responses.ClusterStateResponse
+	if err != nil {
+		return nil, err
+	}
+	defer stateRes.Body.Close()
+
+	if stateRes.IsError() {
+		return nil, ErrVotingConfigExclusionsFailed(stateRes.String())
+	}
+
+	err = json.NewDecoder(stateRes.Body).Decode(&response)
+	return response.Metadata.ClusterCoordination.VotingConfigExclusions, err
+}
+
+func (client *OsClusterClient) PostVotingConfigExclusions(nodeNames []string) error {
+	req := opensearchapi.ClusterPostVotingConfigExclusionsRequest{
+		NodeNames: strings.Join(nodeNames, ","),
+	}
+	resp, err := req.Do(context.Background(), client.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrVotingConfigExclusionsFailed(resp.String())
+	}
+	return nil
+}
+
+func (client *OsClusterClient) DeleteVotingConfigExclusions(waitForRemoval bool) error {
+	req := opensearchapi.ClusterDeleteVotingConfigExclusionsRequest{
+		WaitForRemoval: pointer.BoolPtr(waitForRemoval),
+	}
+	resp, err := req.Do(context.Background(), client.client)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrVotingConfigExclusionsFailed(resp.String())
+	}
+	return nil
+}
+
 func (client *OsClusterClient) GetRole(ctx context.Context, name string) (*opensearchapi.Response, error) {
 	path := generateRolesPath(name)
 
@@ -474,6 +581,229 @@ func (client *OsClusterClient) DeleteRolesMapping(ctx context.Context, name stri
 	return &opensearchapi.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}, nil
 }
 
+// PutSecurityconfigEntry creates or replaces an entry of a type of the security config, e.g. a role of roles
+func (client *OsClusterClient) PutSecurityconfigEntry(ctx context.Context, configType string, name string, body io.Reader) (*opensearchapi.Response, error) {
+	path := generateSecurityconfigPath(configType, name)
+
+	req, err := http.NewRequest(http.MethodPut, path.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	req.Header.Add(headerContentType, jsonContentHeader)
+
+	res, err := client.client.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opensearchapi.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}, nil
+}
+
+// DeleteSecurityconfigEntry deletes an entry of a type of the security config
+func (client *OsClusterClient) DeleteSecurityconfigEntry(ctx context.Context, configType string, name string) (*opensearchapi.Response, error) {
+	path := generateSecurityconfigPath(configType, name)
+
+	req, err := http.NewRequest(http.MethodDelete, path.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	res, err := client.client.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opensearchapi.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}, nil
+}
+
+// SecurityIndexExists checks if the index of the security config exists
+func (client *OsClusterClient) SecurityIndexExists(ctx context.Context) (bool, error) {
+	req := opensearchapi.IndicesExistsRequest{Index: []string{SecurityIndex}}
+	res, err := req.Do(ctx, client.client)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, ErrSecurityconfigUpdateFailed(res.String())
+	}
+	return true, nil
+}
+
+// CreateSecurityIndex creates the index of the security config with a replica on every node, like securityadmin.sh does
+func (client *OsClusterClient) CreateSecurityIndex(ctx context.Context) error {
+	req := opensearchapi.IndicesCreateRequest{
+		Index: SecurityIndex,
+		Body:  strings.NewReader(`{"settings":{"index":{"number_of_shards":1,"auto_expand_replicas":"0-all"}}}`),
+	}
+	res, err := req.Do(ctx, client.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return ErrSecurityconfigUpdateFailed(res.String())
+	}
+	return nil
+}
+
+// PutSecurityconfigDocument replaces the document of a type of the security config in the security index
+func (client *OsClusterClient) PutSecurityconfigDocument(ctx context.Context, configType string, body io.Reader) error {
+	req := opensearchapi.IndexRequest{
+		Index:      SecurityIndex,
+		DocumentID: configType,
+		Body:       body,
+		Refresh:    "true",
+	}
+	res, err := req.Do(ctx, client.client)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.IsError() {
+		return ErrSecurityconfigUpdateFailed(res.String())
+	}
+	return nil
+}
+
+// GetSecurityconfigDocument returns the document of a type of the security config from the security index
+func (client *OsClusterClient) GetSecurityconfigDocument(ctx context.Context, configType string) (responses.SecurityconfigDocumentResponse, error) {
+	req := opensearchapi.GetRequest{
+		Index:      SecurityIndex,
+		DocumentID: configType,
+	}
+	var response responses.SecurityconfigDocumentResponse
+	res, err := req.Do(ctx, client.client)
+	if err != nil {
+		return response, err
+	}
+	defer res.Body.Close()
+	// A missing document is reported as not found in the response
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return response, ErrSecurityconfigUpdateFailed(res.String())
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	return response, err
+}
+
+// ReloadSecurityconfig makes all nodes reload the given types of the security config from the security index
+func (client *OsClusterClient) ReloadSecurityconfig(ctx context.Context, configTypes []string) (responses.SecurityconfigUpdateResponse, error) {
+	var response responses.SecurityconfigUpdateResponse
+	req, err := http.NewRequest(http.MethodPut, "/_plugins/_security/configupdate?config_types="+strings.Join(configTypes, ","), nil)
+	if err != nil {
+		return response, err
+	}
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+
+	res, err := client.client.Perform(req)
+	if err != nil {
+		return response, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode > 299 {
+		resp := opensearchapi.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}
+		return response, ErrSecurityconfigUpdateFailed(resp.String())
+	}
+	err = json.NewDecoder(res.Body).Decode(&response)
+	return response, err
+}
+
+func (client *OsClusterClient) GetRemoteInfo() (responses.RemoteInfoResponse, error) {
+	req := opensearchapi.ClusterRemoteInfoRequest{}
+	infoRes, err := req.Do(context.Background(), client.client)
+	var response responses.RemoteInfoResponse
+	if err != nil {
+		return response, err
+	}
+	defer infoRes.Body.Close()
+
+	if infoRes.IsError() {
+		return response, ErrRemoteInfoGetFailed(infoRes.String())
+	}
+
+	err = json.NewDecoder(infoRes.Body).Decode(&response)
+	return response, err
+}
+
+func (client *OsClusterClient) GetReplicationStatus(ctx context.Context, index string) (*opensearchapi.Response, error) {
+	path := generateReplicationPath(index, "_status")
+	return client.performReplicationRequest(ctx, http.MethodGet, path, nil)
+}
+
+func (client *OsClusterClient) StartReplication(ctx context.Context, index string, body io.Reader) (*opensearchapi.Response, error) {
+	path := generateReplicationPath(index, "_start")
+	return client.performReplicationRequest(ctx, http.MethodPut, path, body)
+}
+
+func (client *OsClusterClient) PauseReplication(ctx context.Context, index string) (*opensearchapi.Response, error) {
+	path := generateReplicationPath(index, "_pause")
+	return client.performReplicationRequest(ctx, http.MethodPost, path, strings.NewReader("{}"))
+}
+
+func (client *OsClusterClient) ResumeReplication(ctx context.Context, index string) (*opensearchapi.Response, error) {
+	path := generateReplicationPath(index, "_resume")
+	return client.performReplicationRequest(ctx, http.MethodPost, path, strings.NewReader("{}"))
+}
+
+func (client *OsClusterClient) StopReplication(ctx context.Context, index string) (*opensearchapi.Response, error) {
+	path := generateReplicationPath(index, "_stop")
+	return client.performReplicationRequest(ctx, http.MethodPost, path, strings.NewReader("{}"))
+}
+
+func (client *OsClusterClient) PostAutoFollowRule(ctx context.Context, body io.Reader) (*opensearchapi.Response, error) {
+	path := generateReplicationPath("", "_autofollow")
+	return client.performReplicationRequest(ctx, http.MethodPost, path, body)
+}
+
+func (client *OsClusterClient) DeleteAutoFollowRule(ctx context.Context, body io.Reader) (*opensearchapi.Response, error) {
+	path := generateReplicationPath("", "_autofollow")
+	return client.performReplicationRequest(ctx, http.MethodDelete, path, body)
+}
+
+func (client *OsClusterClient) GetAutoFollowStats(ctx context.Context) (*opensearchapi.Response, error) {
+	path := generateReplicationPath("", "autofollow_stats")
+	return client.performReplicationRequest(ctx, http.MethodGet, path, nil)
+}
+
+func (client *OsClusterClient) GetFollowerStats(ctx context.Context) (*opensearchapi.Response, error) {
+	path := generateReplicationPath("", "follower_stats")
+	return client.performReplicationRequest(ctx, http.MethodGet, path, nil)
+}
+
+func (client *OsClusterClient) performReplicationRequest(ctx context.Context, method string, path strings.Builder, body io.Reader) (*opensearchapi.Response, error) {
+	req, err := http.NewRequest(method, path.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	if ctx != nil {
+		req = req.WithContext(ctx)
+	}
+	if body != nil {
+		req.Header.Add(headerContentType, jsonContentHeader)
+	}
+
+	res, err := client.client.Perform(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opensearchapi.Response{StatusCode: res.StatusCode, Body: res.Body, Header: res.Header}, nil
+}
+
 func generateRolesPath(name string) strings.Builder {
 	var path strings.Builder
 	path.Grow(1 + len("_plugins") + 1 + len("_security") + 1 + len("api") + 1 + len("roles") + 1 + len(name))
@@ -521,3 +851,37 @@ func generateRolesMappingPath(name string) strings.Builder {
 	path.WriteString(name)
 	return path
 }
+
+// generateSecurityconfigPath builds the path of an entry of the security REST API, e.g. /_plugins/_security/api/roles/<name>
+func generateSecurityconfigPath(configType string, name string) strings.Builder {
+	var path strings.Builder
+	path.Grow(1 + len("_plugins") + 1 + len("_security") + 1 + len("api") + 1 + len(configType) + 1 + len(name))
+	path.WriteString("/")
+	path.WriteString("_plugins")
+	path.WriteString("/")
+	path.WriteString("_security")
+	path.WriteString("/")
+	path.WriteString("api")
+	path.WriteString("/")
+	path.WriteString(configType)
+	path.WriteString("/")
+	path.WriteString(name)
+	return path
+}
+
+// generateReplicationPath builds the path of a replication API, the index is omitted for APIs that are not bound to an index
+func generateReplicationPath(index string, action string) strings.Builder {
+	var path strings.Builder
+	path.Grow(1 + len("_plugins") + 1 + len("_replication") + 1 + len(index) + 1 + len(action))
+	path.WriteString("/")
+	path.WriteString("_plugins")
+	path.WriteString("/")
+	path.WriteString("_replication")
+	path.WriteString("/")
+	if index != "" {
+		path.WriteString(index)
+		path.WriteString("/")
+	}
+	path.WriteString(action)
+	return path
+}
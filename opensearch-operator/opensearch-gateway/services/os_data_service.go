@@ -111,6 +111,175 @@ func RemoveExcludeNodeHost(service *OsClusterClient, nodeNameToExclude string) (
 	return err == nil, err
 }
 
+// ClearExcludeNodeHosts removes all nodes from the shard allocation excludes
+func ClearExcludeNodeHosts(service *OsClusterClient) error {
+	settings := createClusterSettingsResponseWithExcludeName("")
+	_, err := service.PutClusterSettings(settings)
+	return err
+}
+
+// AppendVotingConfigExclusion excludes the node from the voting configuration so it can
+// leave the cluster without affecting the cluster manager quorum
+func AppendVotingConfigExclusion(service *OsClusterClient, nodeName string) (bool, error) {
+	excluded, err := HasVotingConfigExclusion(service, nodeName)
+	if err != nil || excluded {
+		return excluded, err
+	}
+	err = service.PostVotingConfigExclusions([]string{nodeName})
+	return err == nil, err
+}
+
+func HasVotingConfigExclusion(service *OsClusterClient, nodeName string) (bool, error) {
+	exclusions, err := service.GetVotingConfigExclusions()
+	if err != nil {
+		return false, err
+	}
+	for _, exclusion := range exclusions {
+		if exclusion.NodeName == nodeName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// absentNodeName is the name of voting config exclusions that were added by node id for a node unknown to the cluster
+const absentNodeName = "_absent_"
+
+// RemoveVotingConfigExclusion removes the voting config exclusion of the node once it has left the cluster. It returns
+// false while the node is still part of the cluster. The API can only clear all exclusions, the exclusions of other
+// nodes are added again and returned, they were not added for this node and are left to whoever added them.
+func RemoveVotingConfigExclusion(service *OsClusterClient, nodeName string) (bool, []string, error) {
+	exclusions, err := service.GetVotingConfigExclusions()
+	if err != nil {
+		return false, nil, err
+	}
+	var others []string
+	excluded := false
+	for _, exclusion := range exclusions {
+		if exclusion.NodeName == nodeName {
+			excluded = true
+		} else {
+			others = append(others, exclusion.NodeName)
+		}
+	}
+	if !excluded {
+		return true, others, nil
+	}
+
+	nodes, err := service.CatNodes()
+	if err != nil {
+		return false, others, err
+	}
+	for _, node := range nodes {
+		if node.Name == nodeName {
+			return false, others, nil
+		}
+	}
+
+	// The node has left, so the other exclusions must not be waited for
+	if err := replaceVotingConfigExclusions(service, others); err != nil {
+		return false, others, err
+	}
+	return true, others, nil
+}
+
+// ClearVotingConfigExclusion removes the voting config exclusion of the node while it is part of the cluster, e.g.
+// after it was restarted. Like RemoveVotingConfigExclusion it adds the exclusions of other nodes again and returns them.
+func ClearVotingConfigExclusion(service *OsClusterClient, nodeName string) ([]string, error) {
+	exclusions, err := service.GetVotingConfigExclusions()
+	if err != nil {
+		return nil, err
+	}
+	var others []string
+	excluded := false
+	for _, exclusion := range exclusions {
+		if exclusion.NodeName == nodeName {
+			excluded = true
+		} else {
+			others = append(others, exclusion.NodeName)
+		}
+	}
+	if !excluded {
+		return others, nil
+	}
+	return others, replaceVotingConfigExclusions(service, others)
+}
+
+// replaceVotingConfigExclusions clears all voting config exclusions and excludes the given nodes again. Exclusions of
+// nodes unknown to the cluster can't be added by name and are lost.
+func replaceVotingConfigExclusions(service *OsClusterClient, nodeNames []string) error {
+	if err := service.DeleteVotingConfigExclusions(false); err != nil {
+		return err
+	}
+	var restore []string
+	for _, nodeName := range nodeNames {
+		if nodeName != absentNodeName {
+			restore = append(restore, nodeName)
+		}
+	}
+	if len(restore) > 0 {
+		return service.PostVotingConfigExclusions(restore)
+	}
+	return nil
+}
+
+// GetElectedClusterManager returns the name of the currently elected cluster manager node
+func GetElectedClusterManager(service *OsClusterClient) (string, error) {
+	nodes, err := service.CatNodes()
+	if err != nil {
+		return "", err
+	}
+	for _, node := range nodes {
+		if node.Master == "*" {
+			return node.Name, nil
+		}
+	}
+	return "", nil
+}
+
+// NodesJoined checks that all the given nodes are part of the cluster
+func NodesJoined(service *OsClusterClient, nodeNames []string) (bool, error) {
+	missing, err := MissingNodes(service, nodeNames)
+	return len(missing) == 0 && err == nil, err
+}
+
+// MissingNodes returns the given nodes that are not part of the cluster
+func MissingNodes(service *OsClusterClient, nodeNames []string) ([]string, error) {
+	nodes, err := service.CatNodes()
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, nodeName := range nodeNames {
+		found := false
+		for _, node := range nodes {
+			if node.Name == nodeName {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, nodeName)
+		}
+	}
+	return missing, nil
+}
+
+// GetIndicesCreatedVersion returns the version each index was created with
+func GetIndicesCreatedVersion(service *OsClusterClient) (map[string]string, error) {
+	response, err := service.GetIndicesSettings([]string{"index.version.created*"})
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]string, len(response))
+	for index, settings := range response {
+		if created, ok := settings.Settings["index.version.created_string"].(string); ok {
+			versions[index] = created
+		}
+	}
+	return versions, nil
+}
+
 func SetClusterShardAllocation(service *OsClusterClient, enableType ClusterSettingsAllocation) error {
 	settings := createClusterSettingsAllocationEnable(enableType)
 	_, err := service.PutClusterSettings(settings)
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/opensearch-project/opensearch-go/opensearchutil"
+	"opensearch.opster.io/opensearch-gateway/requests"
+	"opensearch.opster.io/opensearch-gateway/responses"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// EnsureRemoteCluster configures the remote cluster connection with the given alias and seeds,
+// it returns true if the cluster settings had to be changed
+func EnsureRemoteCluster(ctx context.Context, service *OsClusterClient, alias string, seeds []string) (bool, error) {
+	info, err := service.GetRemoteInfo()
+	if err != nil {
+		return false, err
+	}
+	if remote, ok := info[alias]; ok && equalSeeds(remote.Seeds, seeds) {
+		return false, nil
+	}
+
+	lg := log.FromContext(ctx).WithValues("os_service", "replication")
+	lg.Info("configuring remote cluster", "alias", alias, "seeds", seeds)
+	_, err = service.PutClusterSettings(createRemoteClusterSettings(alias, seeds))
+	return true, err
+}
+
+// RemoveRemoteCluster removes the remote cluster connection with the given alias
+func RemoveRemoteCluster(ctx context.Context, service *OsClusterClient, alias string) error {
+	info, err := service.GetRemoteInfo()
+	if err != nil {
+		return err
+	}
+	if _, ok := info[alias]; !ok {
+		return nil
+	}
+	_, err = service.PutClusterSettings(createRemoteClusterSettings(alias, nil))
+	return err
+}
+
+func GetReplicationStatus(ctx context.Context, service *OsClusterClient, index string) (responses.ReplicationStatusResponse, error) {
+	status := responses.ReplicationStatusResponse{}
+	resp, err := service.GetReplicationStatus(ctx, index)
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return status, ErrReplicationFailed(resp.String())
+	}
+	err = json.NewDecoder(resp.Body).Decode(&status)
+	return status, err
+}
+
+func StartReplication(ctx context.Context, service *OsClusterClient, index string, request requests.StartReplication) error {
+	resp, err := service.StartReplication(ctx, index, opensearchutil.NewJSONReader(request))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+func PauseReplication(ctx context.Context, service *OsClusterClient, index string) error {
+	resp, err := service.PauseReplication(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+func ResumeReplication(ctx context.Context, service *OsClusterClient, index string) error {
+	resp, err := service.ResumeReplication(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+func StopReplication(ctx context.Context, service *OsClusterClient, index string) error {
+	resp, err := service.StopReplication(ctx, index)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+// GetAutoFollowRules returns the auto-follow rules of the follower cluster
+func GetAutoFollowRules(ctx context.Context, service *OsClusterClient) ([]responses.AutoFollowRuleStats, error) {
+	resp, err := service.GetAutoFollowStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, ErrReplicationFailed(resp.String())
+	}
+	stats := responses.AutoFollowStatsResponse{}
+	err = json.NewDecoder(resp.Body).Decode(&stats)
+	return stats.AutoFollowStats, err
+}
+
+func CreateAutoFollowRule(ctx context.Context, service *OsClusterClient, rule requests.AutoFollowRule) error {
+	resp, err := service.PostAutoFollowRule(ctx, opensearchutil.NewJSONReader(rule))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+func DeleteAutoFollowRule(ctx context.Context, service *OsClusterClient, alias string, name string) error {
+	rule := requests.AutoFollowRule{
+		LeaderAlias: alias,
+		Name:        name,
+	}
+	resp, err := service.DeleteAutoFollowRule(ctx, opensearchutil.NewJSONReader(rule))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return ErrReplicationFailed(resp.String())
+	}
+	return nil
+}
+
+// GetFollowerIndicesMatching returns the follower indices that match one of the patterns, sorted by name
+func GetFollowerIndicesMatching(ctx context.Context, service *OsClusterClient, patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	resp, err := service.GetFollowerStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.IsError() {
+		return nil, ErrReplicationFailed(resp.String())
+	}
+	stats := responses.FollowerStatsResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, err
+	}
+
+	var indices []string
+	for index := range stats.IndexStats {
+		for _, pattern := range patterns {
+			matched, err := path.Match(pattern, index)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %s: %w", pattern, err)
+			}
+			if matched {
+				indices = append(indices, index)
+				break
+			}
+		}
+	}
+	sort.Strings(indices)
+	return indices, nil
+}
+
+func createRemoteClusterSettings(alias string, seeds []string) responses.ClusterSettingsResponse {
+	var seedsValue interface{}
+	if seeds != nil {
+		seedsValue = seeds
+	}
+	return responses.ClusterSettingsResponse{
+		Persistent: map[string]interface{}{
+			"cluster": map[string]interface{}{
+				"remote": map[string]interface{}{
+					alias: map[string]interface{}{
+						"seeds": seedsValue,
+					},
+				},
+			},
+		},
+	}
+}
+
+func equalSeeds(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
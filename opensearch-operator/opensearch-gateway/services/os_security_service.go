@@ -2,9 +2,11 @@ package services
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/opensearch-project/opensearch-go/opensearchutil"
 	"opensearch.opster.io/opensearch-gateway/requests"
@@ -283,3 +285,105 @@ func DeleteRoleMapping(ctx context.Context, service *OsClusterClient, rolename s
 	}
 	return nil
 }
+
+// PutSecurityconfigEntry creates or replaces an entry of a type of the security config
+func PutSecurityconfigEntry(
+	ctx context.Context,
+	service *OsClusterClient,
+	configType string,
+	name string,
+	entry interface{},
+) error {
+	resp, err := service.PutSecurityconfigEntry(ctx, configType, name, opensearchutil.NewJSONReader(entry))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.IsError() {
+		return fmt.Errorf("failed to update %s %s: %s", configType, name, resp.String())
+	}
+	return nil
+}
+
+// DeleteSecurityconfigEntry deletes an entry of a type of the security config, entries that don't exist are ignored
+func DeleteSecurityconfigEntry(ctx context.Context, service *OsClusterClient, configType string, name string) error {
+	resp, err := service.DeleteSecurityconfigEntry(ctx, configType, name)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 404 && resp.IsError() {
+		return fmt.Errorf("failed to delete %s %s: %s", configType, name, resp.String())
+	}
+	return nil
+}
+
+// UploadSecurityconfig replaces the given types of the security config with their JSON encoded configs like
+// securityadmin.sh does. The configs are stored in the security index, reloaded by all nodes and verified afterwards.
+func UploadSecurityconfig(ctx context.Context, service *OsClusterClient, configs map[string][]byte) error {
+	exists, err := service.SecurityIndexExists(ctx)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := service.CreateSecurityIndex(ctx); err != nil {
+			return err
+		}
+	}
+
+	configTypes := make([]string, 0, len(configs))
+	for configType := range configs {
+		configTypes = append(configTypes, configType)
+	}
+	sort.Strings(configTypes)
+
+	encoded := make(map[string]string, len(configs))
+	for _, configType := range configTypes {
+		encoded[configType] = base64.StdEncoding.EncodeToString(configs[configType])
+		document := map[string]string{configType: encoded[configType]}
+		if err := service.PutSecurityconfigDocument(ctx, configType, opensearchutil.NewJSONReader(document)); err != nil {
+			return err
+		}
+	}
+
+	resp, err := service.ReloadSecurityconfig(ctx, configTypes)
+	if err != nil {
+		return err
+	}
+	if resp.Nodes.Failed > 0 || resp.ConfigUpdateResponse.HasFailures {
+		return ErrSecurityconfigUpdateFailed(fmt.Sprintf("%d of %d nodes failed to reload the securityconfig", resp.Nodes.Failed, resp.Nodes.Total))
+	}
+
+	for _, configType := range configTypes {
+		document, err := service.GetSecurityconfigDocument(ctx, configType)
+		if err != nil {
+			return err
+		}
+		if !document.Found || document.Source[configType] != encoded[configType] {
+			return ErrSecurityconfigUpdateFailed(fmt.Sprintf("the stored %s does not match the uploaded one", configType))
+		}
+	}
+	return nil
+}
+
+// ExportSecurityconfig reads the given types of the security config from the security index and returns their JSON
+// encoded configs. Types without a document in the index are left out.
+func ExportSecurityconfig(ctx context.Context, service *OsClusterClient, configTypes []string) (map[string][]byte, error) {
+	configs := make(map[string][]byte, len(configTypes))
+	for _, configType := range configTypes {
+		document, err := service.GetSecurityconfigDocument(ctx, configType)
+		if err != nil {
+			return nil, err
+		}
+		if !document.Found {
+			continue
+		}
+		config, err := base64.StdEncoding.DecodeString(document.Source[configType])
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", configType, err)
+		}
+		configs[configType] = config
+	}
+	return configs, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/jarcoal/httpmock"
+	"opensearch.opster.io/opensearch-gateway/responses"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Voting config exclusions", func() {
+	const (
+		clusterUrl        = "https://opensearch.default.svc.cluster.local:9200"
+		failMessage       = "unexpected request"
+		stateUrl          = clusterUrl + "/_cluster/state/metadata"
+		exclusionsUrl     = clusterUrl + "/_cluster/voting_config_exclusions"
+		catNodesUrl       = clusterUrl + "/_cat/nodes"
+		excludedNode      = "opensearch-masters-2"
+		otherExcludedNode = "other-node"
+	)
+
+	var (
+		transport     *httpmock.MockTransport
+		clusterClient *OsClusterClient
+	)
+
+	exclusionsResponder := func(nodeNames ...string) httpmock.Responder {
+		response := responses.ClusterStateResponse{}
+		for _, nodeName := range nodeNames {
+			exclusion := responses.VotingConfigExclusion{NodeId: nodeName + "-id", NodeName: nodeName}
+			response.Metadata.ClusterCoordination.VotingConfigExclusions = append(response.Metadata.ClusterCoordination.VotingConfigExclusions, exclusion)
+		}
+		return httpmock.NewJsonResponderOrPanic(200, response)
+	}
+
+	nodesResponder := func(nodeNames ...string) httpmock.Responder {
+		var nodes []responses.CatNodesResponse
+		for _, nodeName := range nodeNames {
+			nodes = append(nodes, responses.CatNodesResponse{Name: nodeName})
+		}
+		return httpmock.NewJsonResponderOrPanic(200, nodes)
+	}
+
+	BeforeEach(func() {
+		transport = httpmock.NewMockTransport()
+		transport.RegisterNoResponder(httpmock.NewNotFoundResponder(func(args ...interface{}) {
+			Fail(failMessage)
+		}))
+		transport.RegisterResponder(http.MethodHead, clusterUrl+"/", httpmock.NewStringResponder(200, "OK"))
+		transport.RegisterResponder(http.MethodGet, clusterUrl+"/", httpmock.NewStringResponder(200, "{}"))
+		var err error
+		clusterClient, err = NewOsClusterClient(clusterUrl, "admin", "admin", WithTransport(transport))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	When("When excluding a node", func() {
+		It("should add the exclusion", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(otherExcludedNode))
+			transport.RegisterResponder(http.MethodPost, exclusionsUrl+"?node_names="+excludedNode, httpmock.NewStringResponder(200, ""))
+
+			excluded, err := AppendVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(excluded).To(BeTrue())
+			Expect(transport.GetCallCountInfo()[http.MethodPost+" "+exclusionsUrl+"?node_names="+excludedNode]).To(Equal(1))
+		})
+
+		It("should not add the exclusion twice", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(excludedNode))
+
+			excluded, err := AppendVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(excluded).To(BeTrue())
+		})
+
+		It("should return the error of the cluster", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder())
+			transport.RegisterResponder(http.MethodPost, exclusionsUrl, httpmock.NewStringResponder(400, "error"))
+
+			excluded, err := AppendVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).To(HaveOccurred())
+			Expect(excluded).To(BeFalse())
+		})
+	})
+
+	When("When removing the exclusion of a node", func() {
+		It("should wait for the node to leave the cluster", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(excludedNode))
+			transport.RegisterResponder(http.MethodGet, catNodesUrl, nodesResponder("opensearch-masters-0", excludedNode))
+
+			removed, others, err := RemoveVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeFalse())
+			Expect(others).To(BeEmpty())
+			Expect(transport.GetCallCountInfo()[http.MethodDelete+" "+exclusionsUrl]).To(Equal(0))
+		})
+
+		It("should keep the exclusions of other nodes", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(excludedNode, otherExcludedNode))
+			transport.RegisterResponder(http.MethodGet, catNodesUrl, nodesResponder("opensearch-masters-0"))
+			transport.RegisterResponder(http.MethodDelete, exclusionsUrl+"?wait_for_removal=false", httpmock.NewStringResponder(200, ""))
+			transport.RegisterResponder(http.MethodPost, exclusionsUrl+"?node_names="+otherExcludedNode, httpmock.NewStringResponder(200, ""))
+
+			removed, others, err := RemoveVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeTrue())
+			Expect(others).To(Equal([]string{otherExcludedNode}))
+			calls := transport.GetCallCountInfo()
+			Expect(calls[http.MethodDelete+" "+exclusionsUrl+"?wait_for_removal=false"]).To(Equal(1))
+			Expect(calls[http.MethodPost+" "+exclusionsUrl+"?node_names="+otherExcludedNode]).To(Equal(1))
+		})
+
+		It("should not touch the exclusions if the node is not excluded", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(otherExcludedNode))
+
+			removed, others, err := RemoveVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(BeTrue())
+			Expect(others).To(Equal([]string{otherExcludedNode}))
+			Expect(transport.GetCallCountInfo()[http.MethodDelete+" "+exclusionsUrl]).To(Equal(0))
+		})
+	})
+
+	When("When clearing the exclusion of a restarted node", func() {
+		It("should not wait for the node to leave the cluster", func() {
+			transport.RegisterResponder(http.MethodGet, stateUrl, exclusionsResponder(excludedNode, otherExcludedNode))
+			transport.RegisterResponder(http.MethodDelete, exclusionsUrl+"?wait_for_removal=false", httpmock.NewStringResponder(200, ""))
+			transport.RegisterResponder(http.MethodPost, exclusionsUrl+"?node_names="+otherExcludedNode, httpmock.NewStringResponder(200, ""))
+
+			others, err := ClearVotingConfigExclusion(clusterClient, excludedNode)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(others).To(Equal([]string{otherExcludedNode}))
+			calls := transport.GetCallCountInfo()
+			Expect(calls[http.MethodGet+" "+catNodesUrl]).To(Equal(0))
+			Expect(calls[http.MethodDelete+" "+exclusionsUrl+"?wait_for_removal=false"]).To(Equal(1))
+			Expect(calls[http.MethodPost+" "+exclusionsUrl+"?node_names="+otherExcludedNode]).To(Equal(1))
+		})
+	})
+})
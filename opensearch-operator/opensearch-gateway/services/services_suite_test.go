@@ -1,5 +1,17 @@
 package services
 
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestServices(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Services Suite")
+}
+
 /*
 
    import (
@@ -13,13 +25,6 @@ package services
    	"testing"
    )
 
-   func TestServices(t *testing.T) {
-   	RegisterFailHandler(Fail)
-   	RunSpecsWithDefaultAndCustomReporters(t,
-   		"Services Suite",
-   		[]Reporter{printer.NewlineReporter{}})
-   }
-
    const (
    	TestClusterUrl      = "https://localhost:9111"
    	TestClusterUserName = "admin"
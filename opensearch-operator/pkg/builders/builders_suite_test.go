@@ -0,0 +1,13 @@
+package builders
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestBuilders(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Builders Suite")
+}
@@ -5,10 +5,11 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
-	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -22,10 +23,18 @@ import (
 /// package that declare and build all the resources that related to the OpenSearch cluster ///
 
 const (
-	ClusterLabel                     = "opster.io/opensearch-cluster"
-	NodePoolLabel                    = "opster.io/opensearch-nodepool"
-	ConfigurationChecksumAnnotation  = "opster.io/config"
-	securityconfigChecksumAnnotation = "securityconfig/checksum"
+	ClusterLabel                    = "opster.io/opensearch-cluster"
+	NodePoolLabel                   = "opster.io/opensearch-nodepool"
+	ConfigurationChecksumAnnotation = "opster.io/config"
+	NodeRoleLabel                   = "opensearch.role"
+	KeystoreChecksumAnnotation      = "opster.io/keystore"
+	SecurityconfigBackupLabel       = "opster.io/securityconfig-backup"
+	CredentialsChecksumAnnotation   = "opster.io/credentials"
+	// Env vars with the credentials of the external audit sink, referenced by opensearch.yml
+	AuditUsernameEnv = "OPENSEARCH_AUDIT_USERNAME"
+	AuditPasswordEnv = "OPENSEARCH_AUDIT_PASSWORD"
+
+	adminPasswordMountPath = "/mnt/admin-password"
 )
 
 func NewSTSForNodePool(
@@ -36,32 +45,22 @@ func NewSTSForNodePool(
 	volumes []corev1.Volume,
 	volumeMounts []corev1.VolumeMount,
 	extraConfig map[string]string,
-) *appsv1.StatefulSet {
+) (*appsv1.StatefulSet, error) {
+	if !helpers.ContainsString(opsterv1.SupportedVendors, cr.Spec.General.Vendor) {
+		return nil, fmt.Errorf("vendor %s is not supported", cr.Spec.General.Vendor)
+	}
+
 	//To make sure disksize is not passed as empty
 	var disksize string
 	if len(node.DiskSize) == 0 {
-		disksize = "30Gi"
+		disksize = opsterv1.DefaultDiskSize
 	} else {
 		disksize = node.DiskSize
 	}
 
-	availableRoles := []string{
-		"master",
-		"data",
-		"data_content",
-		"data_hot",
-		"data_warm",
-		"data_cold",
-		"data_frozen",
-		"ingest",
-		"ml",
-		"remote_cluster_client",
-		"transform",
-		"cluster_manager",
-	}
 	var selectedRoles []string
 	for _, role := range node.Roles {
-		if helpers.ContainsString(availableRoles, role) {
+		if helpers.ContainsString(opsterv1.AvailableRoles, role) {
 			selectedRoles = append(selectedRoles, role)
 		}
 	}
@@ -119,7 +118,11 @@ func NewSTSForNodePool(
 		MountPath: "/usr/share/opensearch/data",
 	})
 
-	//var vendor string
+	if generatedPasswords(cr) {
+		volumes = append(volumes, adminPasswordVolume(cr))
+		volumeMounts = append(volumeMounts, adminPasswordVolumeMount())
+	}
+
 	labels := map[string]string{
 		ClusterLabel:  cr.Name,
 		NodePoolLabel: node.Component,
@@ -129,11 +132,11 @@ func NewSTSForNodePool(
 	}
 
 	if helpers.ContainsString(selectedRoles, "master") {
-		labels["opensearch.role"] = "master"
+		labels[NodeRoleLabel] = "master"
 	}
 
 	if helpers.ContainsString(selectedRoles, "cluster_manager") {
-		labels["opensearch.role"] = "cluster_manager"
+		labels[NodeRoleLabel] = "cluster_manager"
 	}
 
 	// cr.Spec.NodePool.labels
@@ -142,19 +145,9 @@ func NewSTSForNodePool(
 	}
 	runas := int64(0)
 
-	if cr.Spec.General.Vendor == "Op" || cr.Spec.General.Vendor == "OP" ||
-		cr.Spec.General.Vendor == "Opensearch" ||
-		cr.Spec.General.Vendor == "opensearch" ||
-		cr.Spec.General.Vendor == "" {
-		//	vendor = "opensearchproject/opensearch"
-	} else {
-		panic("vendor=elasticsearch not implemented")
-		//vendor ="elasticsearch"
-	}
-
 	var jvm string
 	if node.Jvm == "" {
-		jvm = "-Xmx512M -Xms512M"
+		jvm = opsterv1.DefaultJvm
 	} else {
 		jvm = node.Jvm
 	}
@@ -172,7 +165,7 @@ func NewSTSForNodePool(
 
 	// Because the http endpoint requires auth we need to do it as a curl script
 	httpPort := PortForCluster(cr)
-	curlCmd := "curl -k -u \"${OPENSEARCH_USER}:${OPENSEARCH_PASSWORD}\" --silent --fail https://localhost:" + fmt.Sprint(httpPort)
+	curlCmd := "curl -k -u \"${OPENSEARCH_USER}:" + adminPasswordVariable(cr) + "\" --silent --fail https://localhost:" + fmt.Sprint(httpPort)
 	readinessProbe := corev1.Probe{
 		InitialDelaySeconds: 60,
 		PeriodSeconds:       30,
@@ -191,19 +184,31 @@ func NewSTSForNodePool(
 
 	image := helpers.ResolveImage(cr, &node)
 
-	var mainCommand []string
-	com := "./bin/opensearch-plugin install --batch"
-	if len(cr.Spec.General.PluginsList) > 0 {
-		mainCommand = append(mainCommand, "/bin/bash", "-c")
-		for index, plugin := range cr.Spec.General.PluginsList {
-			fmt.Println(index, plugin)
-			com = com + " '" + strings.Replace(plugin, "'", "\\'", -1) + "'"
-		}
+	mainCommand := []string{"/bin/bash", "-c", "./opensearch-docker-entrypoint.sh"}
 
-		com = com + " && ./opensearch-docker-entrypoint.sh"
-		mainCommand = append(mainCommand, com)
-	} else {
-		mainCommand = []string{"/bin/bash", "-c", "./opensearch-docker-entrypoint.sh"}
+	// Plugins are installed by an init container into an emptyDir, every new pod downloads them again
+	installPlugins, removePlugins := NodePoolPlugins(cr, &node)
+	if len(installPlugins) > 0 || len(removePlugins) > 0 {
+		volumes = append(volumes, corev1.Volume{
+			Name: "plugins",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "plugins",
+			MountPath: "/usr/share/opensearch/plugins",
+		})
+	}
+
+	// The keystore is built by an init container and linked into the config directory
+	if len(cr.Spec.General.Keystore) > 0 {
+		volumes = append(volumes, keystoreVolumes(cr)...)
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      "keystore",
+			MountPath: "/usr/share/opensearch/config/keystore",
+		})
+		mainCommand = []string{"/bin/bash", "-c", "ln -sf keystore/opensearch.keystore config/opensearch.keystore && ./opensearch-docker-entrypoint.sh"}
 	}
 
 	sts := &appsv1.StatefulSet{
@@ -218,16 +223,10 @@ func NewSTSForNodePool(
 				MatchLabels: labels,
 			},
 			PodManagementPolicy: appsv1.OrderedReadyPodManagement,
-			UpdateStrategy: func() appsv1.StatefulSetUpdateStrategy {
-				if helpers.ContainsString(selectedRoles, "data") {
-					return appsv1.StatefulSetUpdateStrategy{
-						Type: appsv1.OnDeleteStatefulSetStrategyType,
-					}
-				}
-				return appsv1.StatefulSetUpdateStrategy{
-					Type: appsv1.RollingUpdateStatefulSetStrategyType,
-				}
-			}(),
+			// Pods of all node pools are restarted by the operator so restarts can be gated on the cluster health
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.OnDeleteStatefulSetStrategyType,
+			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels:      labels,
@@ -353,9 +352,21 @@ func NewSTSForNodePool(
 			Value: extraConfig[k],
 		})
 	}
+	sts.Spec.Template.Spec.Containers[0].Env = append(sts.Spec.Template.Spec.Containers[0].Env, auditCredentialsEnv(cr)...)
 	// Append additional env vars from cr.Spec.NodePool.env
 	sts.Spec.Template.Spec.Containers[0].Env = append(sts.Spec.Template.Spec.Containers[0].Env, node.Env...)
 
+	if len(installPlugins) > 0 || len(removePlugins) > 0 {
+		sts.Spec.Template.Spec.InitContainers = append(sts.Spec.Template.Spec.InitContainers, pluginsInitContainer(image, installPlugins, removePlugins))
+	}
+
+	if len(cr.Spec.General.Keystore) > 0 {
+		sts.Spec.Template.Spec.InitContainers = append(sts.Spec.Template.Spec.InitContainers, keystoreInitContainer(cr, image))
+		if keystoreReloadable(cr) {
+			sts.Spec.Template.Spec.Containers = append(sts.Spec.Template.Spec.Containers, keystoreReloaderContainer(cr, image, username))
+		}
+	}
+
 	if cr.Spec.General.SetVMMaxMapCount {
 		sts.Spec.Template.Spec.InitContainers = append(sts.Spec.Template.Spec.InitContainers, corev1.Container{
 			Name:  "init-sysctl",
@@ -371,7 +382,7 @@ func NewSTSForNodePool(
 		})
 	}
 
-	return sts
+	return sts, nil
 }
 func NewHeadlessServiceForNodePool(cr *opsterv1.OpenSearchCluster, nodePool *opsterv1.NodePool) *corev1.Service {
 	labels := map[string]string{
@@ -549,7 +560,7 @@ func NewBootstrapPod(
 
 	var jvm string
 	if cr.Spec.Bootstrap.Jvm == "" {
-		jvm = "-Xmx512M -Xms512M"
+		jvm = opsterv1.DefaultJvm
 	} else {
 		jvm = cr.Spec.Bootstrap.Jvm
 	}
@@ -698,6 +709,52 @@ func URLForCluster(cr *opsterv1.OpenSearchCluster) string {
 	return fmt.Sprintf("https://%s.svc.cluster.local:%d", DnsOfService(cr), httpPort)
 }
 
+// auditCredentialsEnv returns the env vars with the credentials of the external audit sink
+func auditCredentialsEnv(cr *opsterv1.OpenSearchCluster) []corev1.EnvVar {
+	if cr.Spec.Security == nil || cr.Spec.Security.Audit == nil || cr.Spec.Security.Audit.Type != opsterv1.AuditSinkExternal {
+		return nil
+	}
+	external := cr.Spec.Security.Audit.External
+	if external == nil || external.CredentialsSecret == nil {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: AuditUsernameEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: *external.CredentialsSecret, Key: "username"}}},
+		{Name: AuditPasswordEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: *external.CredentialsSecret, Key: "password"}}},
+	}
+}
+
+// generatedPasswords returns true if the operator generates the passwords of the built-in users
+func generatedPasswords(cr *opsterv1.OpenSearchCluster) bool {
+	return cr.Spec.Security != nil && cr.Spec.Security.Config != nil && cr.Spec.Security.Config.GeneratePasswords
+}
+
+// adminPasswordVariable returns the shell expression for the admin password used by the scripts of the nodes.
+// Generated passwords are read from the mounted secret, so rotated passwords are picked up without a restart.
+func adminPasswordVariable(cr *opsterv1.OpenSearchCluster) string {
+	if generatedPasswords(cr) {
+		return fmt.Sprintf("$(cat %s/password)", adminPasswordMountPath)
+	}
+	return "${OPENSEARCH_PASSWORD}"
+}
+
+func adminPasswordVolume(cr *opsterv1.OpenSearchCluster) corev1.Volume {
+	return corev1.Volume{
+		Name: "admin-password",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{SecretName: fmt.Sprintf("%s-admin-password", cr.Name)},
+		},
+	}
+}
+
+func adminPasswordVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{
+		Name:      "admin-password",
+		MountPath: adminPasswordMountPath,
+		ReadOnly:  true,
+	}
+}
+
 func PasswordSecret(cr *opsterv1.OpenSearchCluster, password string) *corev1.Secret {
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -726,6 +783,41 @@ func DiscoveryServiceName(cr *opsterv1.OpenSearchCluster) string {
 	return fmt.Sprintf("%s-discovery", cr.Name)
 }
 
+// TransportSeedForCluster returns the transport address a cluster in the given namespace can use as seed to connect to the cluster
+func TransportSeedForCluster(cr *opsterv1.OpenSearchCluster, namespace string) string {
+	if cr.Namespace == namespace {
+		return fmt.Sprintf("%s:%d", DiscoveryServiceName(cr), 9300)
+	}
+	return fmt.Sprintf("%s.%s.svc:%d", DiscoveryServiceName(cr), cr.Namespace, 9300)
+}
+
+// GeneratedSecurityconfigSecretName returns the name of the secret with the securityconfig rendered by the operator
+func GeneratedSecurityconfigSecretName(cr *opsterv1.OpenSearchCluster) string {
+	return fmt.Sprintf("%s-securityconfig-generated", cr.Name)
+}
+
+// AppliedSecurityconfigSecretName returns the name of the secret with the securityconfig that was last applied
+func AppliedSecurityconfigSecretName(cr *opsterv1.OpenSearchCluster) string {
+	return fmt.Sprintf("%s-securityconfig-applied", cr.Name)
+}
+
+// NewSecurityconfigBackupSecret builds the secret for a backup of the security config taken at the given time.
+// The secret is not owned by the cluster so that it can be used to restore the security config of a new cluster.
+func NewSecurityconfigBackupSecret(cr *opsterv1.OpenSearchCluster, takenAt time.Time, data map[string][]byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-securityconfig-backup-%s", cr.Name, takenAt.UTC().Format("20060102150405")),
+			Namespace: cr.Namespace,
+			Labels: map[string]string{
+				ClusterLabel:              cr.Name,
+				SecurityconfigBackupLabel: "true",
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+}
+
 func BootstrapPodName(cr *opsterv1.OpenSearchCluster) string {
 	return fmt.Sprintf("%s-bootstrap-0", cr.Name)
 }
@@ -735,6 +827,32 @@ func WorkingPodForRollingRestart(sts *appsv1.StatefulSet) string {
 	return ReplicaHostName(*sts, ordinal)
 }
 
+// PodsPendingUpdate returns the names of the pods of the statefulset that are not running the
+// update revision yet, starting with the highest ordinal
+func PodsPendingUpdate(ctx context.Context, k8sClient client.Client, sts *appsv1.StatefulSet) ([]string, error) {
+	var pending []string
+	if sts.Status.UpdateRevision == "" {
+		return pending, nil
+	}
+	for ordinal := pointer.Int32Deref(sts.Spec.Replicas, 1) - 1; ordinal >= 0; ordinal-- {
+		podName := ReplicaHostName(*sts, ordinal)
+		pod := &corev1.Pod{}
+		if err := k8sClient.Get(ctx, types.NamespacedName{
+			Name:      podName,
+			Namespace: sts.Namespace,
+		}, pod); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision {
+			pending = append(pending, podName)
+		}
+	}
+	return pending, nil
+}
+
 func STSInNodePools(sts appsv1.StatefulSet, nodepools []opsterv1.NodePool) bool {
 	for _, nodepool := range nodepools {
 		if sts.Labels[NodePoolLabel] == nodepool.Component {
@@ -744,79 +862,6 @@ func STSInNodePools(sts appsv1.StatefulSet, nodepools []opsterv1.NodePool) bool
 	return false
 }
 
-func NewSecurityconfigUpdateJob(
-	instance *opsterv1.OpenSearchCluster,
-	jobName string,
-	namespace string,
-	checksum string,
-	adminCertName string,
-	clusterName string,
-	volumes []corev1.Volume,
-	volumeMounts []corev1.VolumeMount,
-) batchv1.Job {
-	dns := DnsOfService(instance)
-	adminCert := "/certs/tls.crt"
-	adminKey := "/certs/tls.key"
-	caCert := "/certs/ca.crt"
-
-	// Dummy node spec required to resolve image
-	node := opsterv1.NodePool{
-		Component: "securityconfig",
-	}
-
-	volumes = append(volumes, corev1.Volume{
-		Name:         "admin-cert",
-		VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: adminCertName}},
-	})
-	volumeMounts = append(volumeMounts, corev1.VolumeMount{
-		Name:      "admin-cert",
-		MountPath: "/certs",
-	})
-	//Following httpPort, securityconfigPath are used for executing securityadmin.sh
-	httpPort, securityconfigPath := helpers.VersionCheck(instance)
-	// The following curl command is added to make sure cluster is full connected before .opendistro_security is created.
-	arg := "ADMIN=/usr/share/opensearch/plugins/opensearch-security/tools/securityadmin.sh;" +
-		"chmod +x $ADMIN;" +
-		fmt.Sprintf("until curl -k --silent https://%s.svc.cluster.local:%v; do", dns, instance.Spec.General.HttpPort) +
-		" echo 'Waiting to connect to the cluster'; sleep 120; " +
-		"done; " +
-		"count=0;" +
-		fmt.Sprintf("until $ADMIN -cacert %s -cert %s -key %s -cd %s -icl -nhnv -h %s.svc.cluster.local -p %v || (( count++ >= 20 )); do", caCert, adminCert, adminKey, securityconfigPath, dns, httpPort) +
-		"  sleep 20; " +
-		"done"
-	annotations := map[string]string{
-		securityconfigChecksumAnnotation: checksum,
-	}
-	terminationGracePeriodSeconds := int64(5)
-	backoffLimit := int32(0)
-
-	image := helpers.ResolveImage(instance, &node)
-
-	return batchv1.Job{
-		ObjectMeta: metav1.ObjectMeta{Name: jobName, Namespace: namespace, Annotations: annotations},
-		Spec: batchv1.JobSpec{
-			BackoffLimit: &backoffLimit,
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{Name: jobName},
-				Spec: corev1.PodSpec{
-					TerminationGracePeriodSeconds: &terminationGracePeriodSeconds,
-					Containers: []corev1.Container{{
-						Name:            "updater",
-						Image:           image.GetImage(),
-						ImagePullPolicy: image.GetImagePullPolicy(),
-						Command:         []string{"/bin/bash", "-c"},
-						Args:            []string{arg},
-						VolumeMounts:    volumeMounts,
-					}},
-					Volumes:          volumes,
-					RestartPolicy:    corev1.RestartPolicyNever,
-					ImagePullSecrets: image.ImagePullSecrets,
-				},
-			},
-		},
-	}
-}
-
 func AllMastersReady(ctx context.Context, k8sClient client.Client, cr *opsterv1.OpenSearchCluster) bool {
 	for _, nodePool := range cr.Spec.NodePools {
 		masterRole := helpers.ResolveClusterManagerRole(cr.Spec.General.Version)
@@ -851,3 +896,238 @@ func DataNodesCount(ctx context.Context, k8sClient client.Client, cr *opsterv1.O
 	}
 	return count
 }
+
+// IsClusterManagerSts returns true if the pods of the statefulset are cluster manager eligible
+func IsClusterManagerSts(sts appsv1.StatefulSet) bool {
+	_, ok := sts.Labels[NodeRoleLabel]
+	return ok
+}
+
+// ClusterManagerNodesCount returns the number of cluster manager eligible replicas of the cluster
+// and how many of them are ready, including statefulsets of node pools that are being removed
+func ClusterManagerNodesCount(ctx context.Context, k8sClient client.Client, cr *opsterv1.OpenSearchCluster) (int32, int32, error) {
+	stsList := &appsv1.StatefulSetList{}
+	if err := k8sClient.List(
+		ctx,
+		stsList,
+		client.InNamespace(cr.Namespace),
+		client.MatchingLabels{ClusterLabel: cr.Name},
+	); err != nil {
+		return 0, 0, err
+	}
+
+	replicas := int32(0)
+	ready := int32(0)
+	for _, sts := range stsList.Items {
+		if IsClusterManagerSts(sts) {
+			replicas = replicas + pointer.Int32Deref(sts.Spec.Replicas, 1)
+			ready = ready + sts.Status.ReadyReplicas
+		}
+	}
+	return replicas, ready, nil
+}
+
+// PluginSource returns what to pass to opensearch-plugin install for the plugin
+func PluginSource(plugin opsterv1.Plugin) string {
+	if plugin.URL != "" {
+		return plugin.URL
+	}
+	if plugin.Version != "" {
+		return fmt.Sprintf("https://artifacts.opensearch.org/releases/plugins/%s/%s/%s-%s.zip", plugin.Name, plugin.Version, plugin.Name, plugin.Version)
+	}
+	return plugin.Name
+}
+
+// NodePoolPlugins returns the plugins to install on and the plugins to remove from the nodes of the node pool
+func NodePoolPlugins(cr *opsterv1.OpenSearchCluster, node *opsterv1.NodePool) ([]string, []string) {
+	remove := append([]string{}, cr.Spec.General.RemovePlugins...)
+	for _, plugin := range node.RemovePlugins {
+		if !helpers.ContainsString(remove, plugin) {
+			remove = append(remove, plugin)
+		}
+	}
+
+	var install []string
+	add := func(name string, source string) {
+		if source != "" && !helpers.ContainsString(node.RemovePlugins, name) && !helpers.ContainsString(install, source) {
+			install = append(install, source)
+		}
+	}
+	for _, plugin := range cr.Spec.General.PluginsList {
+		add(plugin, plugin)
+	}
+	for _, plugin := range append(append([]opsterv1.Plugin{}, cr.Spec.General.Plugins...), node.Plugins...) {
+		add(plugin.Name, PluginSource(plugin))
+	}
+	return install, remove
+}
+
+// pluginsInitContainer installs and removes the plugins of the node pool when the pod starts
+// and copies the resulting plugins directory to the shared plugins volume
+func pluginsInitContainer(image opsterv1.ImageSpec, install []string, remove []string) corev1.Container {
+	commands := []string{"set -e"}
+	// Removing a plugin that is not part of the image is not an error, e.g. when a node pool removes a plugin of the cluster
+	for _, plugin := range remove {
+		commands = append(commands, fmt.Sprintf("if ./bin/opensearch-plugin list | grep -qx %s; then ./bin/opensearch-plugin remove %s; fi", shellQuote(plugin), shellQuote(plugin)))
+	}
+	if len(install) > 0 {
+		args := make([]string, 0, len(install))
+		for _, plugin := range install {
+			args = append(args, shellQuote(plugin))
+		}
+		commands = append(commands, "./bin/opensearch-plugin install --batch "+strings.Join(args, " "))
+	}
+	commands = append(commands, "cp -a ./plugins/. /mnt/plugins/")
+
+	return corev1.Container{
+		Name:            "install-plugins",
+		Image:           image.GetImage(),
+		ImagePullPolicy: image.GetImagePullPolicy(),
+		Command:         []string{"/bin/bash", "-c"},
+		Args:            []string{strings.Join(commands, "\n")},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      "plugins",
+				MountPath: "/mnt/plugins",
+			},
+		},
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func keystoreVolumes(cr *opsterv1.OpenSearchCluster) []corev1.Volume {
+	volumes := []corev1.Volume{
+		{
+			Name: "keystore",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	for i, value := range cr.Spec.General.Keystore {
+		volumes = append(volumes, corev1.Volume{
+			Name: fmt.Sprintf("keystore-secret-%d", i),
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: value.Secret.Name,
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+func keystoreVolumeMounts(cr *opsterv1.OpenSearchCluster) []corev1.VolumeMount {
+	volumeMounts := []corev1.VolumeMount{
+		{
+			Name:      "keystore",
+			MountPath: "/mnt/keystore",
+		},
+	}
+	for i := range cr.Spec.General.Keystore {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      fmt.Sprintf("keystore-secret-%d", i),
+			MountPath: fmt.Sprintf("/mnt/keystore-secrets/%d", i),
+			ReadOnly:  true,
+		})
+	}
+	return volumeMounts
+}
+
+// keystoreCommands build a new keystore from the mounted secrets and atomically replace the shared keystore
+func keystoreCommands(cr *opsterv1.OpenSearchCluster) []string {
+	commands := []string{
+		"rm -f config/opensearch.keystore",
+		"./bin/opensearch-keystore create",
+	}
+	for i, value := range cr.Spec.General.Keystore {
+		dir := fmt.Sprintf("/mnt/keystore-secrets/%d", i)
+		if len(value.KeyMappings) == 0 {
+			commands = append(commands, fmt.Sprintf(`for key in $(ls %s); do ./bin/opensearch-keystore add -f -x "$key" < "%s/$key"; done`, dir, dir))
+			continue
+		}
+		keys := make([]string, 0, len(value.KeyMappings))
+		for key := range value.KeyMappings {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			commands = append(commands, fmt.Sprintf("./bin/opensearch-keystore add -f -x %s < %s", shellQuote(value.KeyMappings[key]), shellQuote(dir+"/"+key)))
+		}
+	}
+	return append(commands,
+		"cp config/opensearch.keystore /mnt/keystore/opensearch.keystore.new",
+		"mv -f /mnt/keystore/opensearch.keystore.new /mnt/keystore/opensearch.keystore",
+	)
+}
+
+func keystoreInitContainer(cr *opsterv1.OpenSearchCluster, image opsterv1.ImageSpec) corev1.Container {
+	return corev1.Container{
+		Name:            "keystore",
+		Image:           image.GetImage(),
+		ImagePullPolicy: image.GetImagePullPolicy(),
+		Command:         []string{"/bin/bash", "-c"},
+		Args:            []string{strings.Join(append([]string{"set -e"}, keystoreCommands(cr)...), "\n")},
+		VolumeMounts:    keystoreVolumeMounts(cr),
+	}
+}
+
+func keystoreReloadable(cr *opsterv1.OpenSearchCluster) bool {
+	for _, value := range cr.Spec.General.Keystore {
+		if value.Reloadable {
+			return true
+		}
+	}
+	return false
+}
+
+// keystoreReloaderContainer rebuilds the keystore when the mounted secrets change and reloads the secure settings of the node
+func keystoreReloaderContainer(cr *opsterv1.OpenSearchCluster, image opsterv1.ImageSpec, username string) corev1.Container {
+	script := fmt.Sprintf(`checksum() { cat /mnt/keystore-secrets/*/* | sha256sum; }
+build() (
+set -e
+%s
+)
+last=$(checksum)
+while true; do
+  sleep 30
+  current=$(checksum)
+  if [ "$current" != "$last" ] && build && curl -k -u "${OPENSEARCH_USER}:%s" --silent --fail -XPOST https://localhost:%d/_nodes/_local/reload_secure_settings; then
+    last=$current
+  fi
+done`, strings.Join(keystoreCommands(cr), "\n"), adminPasswordVariable(cr), PortForCluster(cr))
+
+	volumeMounts := keystoreVolumeMounts(cr)
+	if generatedPasswords(cr) {
+		volumeMounts = append(volumeMounts, adminPasswordVolumeMount())
+	}
+
+	return corev1.Container{
+		Name:            "keystore-reloader",
+		Image:           image.GetImage(),
+		ImagePullPolicy: image.GetImagePullPolicy(),
+		Command:         []string{"/bin/bash", "-c"},
+		Args:            []string{script},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "OPENSEARCH_USER",
+				Value: username,
+			},
+			{
+				Name: "OPENSEARCH_PASSWORD",
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: fmt.Sprintf("%s-admin-password", cr.Name),
+						},
+						Key: "password",
+					},
+				},
+			},
+		},
+		VolumeMounts: volumeMounts,
+	}
+}
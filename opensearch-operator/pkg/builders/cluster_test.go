@@ -0,0 +1,141 @@
+package builders
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster builders", func() {
+	newCluster := func(keystore ...opsterv1.KeystoreValue) *opsterv1.OpenSearchCluster {
+		return &opsterv1.OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "keystore", Namespace: "keystore"},
+			Spec: opsterv1.ClusterSpec{
+				General: opsterv1.GeneralConfig{
+					ServiceName: "keystore",
+					Version:     "2.3.0",
+					Keystore:    keystore,
+				},
+				NodePools: []opsterv1.NodePool{
+					{Component: "masters", Replicas: 3, Roles: []string{"master", "data"}},
+				},
+			},
+		}
+	}
+
+	containerNames := func(containers []corev1.Container) []string {
+		var names []string
+		for _, container := range containers {
+			names = append(names, container.Name)
+		}
+		return names
+	}
+
+	When("When building the keystore commands", func() {
+		It("should add all keys of a secret without mappings", func() {
+			cr := newCluster(opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "s3-credentials"}})
+			commands := keystoreCommands(cr)
+			Expect(commands).To(ContainElement(`for key in $(ls /mnt/keystore-secrets/0); do ./bin/opensearch-keystore add -f -x "$key" < "/mnt/keystore-secrets/0/$key"; done`))
+			Expect(commands[:2]).To(Equal([]string{"rm -f config/opensearch.keystore", "./bin/opensearch-keystore create"}))
+			Expect(commands[len(commands)-1]).To(Equal("mv -f /mnt/keystore/opensearch.keystore.new /mnt/keystore/opensearch.keystore"))
+		})
+
+		It("should add the mapped keys in a stable order", func() {
+			cr := newCluster(opsterv1.KeystoreValue{
+				Secret: corev1.LocalObjectReference{Name: "s3-credentials"},
+				KeyMappings: map[string]string{
+					"secretKey": "s3.client.default.secret_key",
+					"accessKey": "s3.client.default.access_key",
+				},
+			})
+			commands := keystoreCommands(cr)
+			Expect(commands[2:4]).To(Equal([]string{
+				"./bin/opensearch-keystore add -f -x 's3.client.default.access_key' < '/mnt/keystore-secrets/0/accessKey'",
+				"./bin/opensearch-keystore add -f -x 's3.client.default.secret_key' < '/mnt/keystore-secrets/0/secretKey'",
+			}))
+		})
+
+		It("should quote key names", func() {
+			cr := newCluster(opsterv1.KeystoreValue{
+				Secret:      corev1.LocalObjectReference{Name: "odd"},
+				KeyMappings: map[string]string{"it's": "a key; rm -rf /"},
+			})
+			Expect(keystoreCommands(cr)).To(ContainElement(`./bin/opensearch-keystore add -f -x 'a key; rm -rf /' < '/mnt/keystore-secrets/0/it'\''s'`))
+		})
+	})
+
+	When("When building the keystore init container", func() {
+		It("should mount every keystore secret", func() {
+			cr := newCluster(
+				opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "first"}},
+				opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "second"}},
+			)
+			volumes := keystoreVolumes(cr)
+			Expect(volumes).To(HaveLen(3))
+			Expect(volumes[0].EmptyDir).ToNot(BeNil())
+			Expect(volumes[1].Name).To(Equal("keystore-secret-0"))
+			Expect(volumes[1].Secret.SecretName).To(Equal("first"))
+			Expect(volumes[2].Name).To(Equal("keystore-secret-1"))
+			Expect(volumes[2].Secret.SecretName).To(Equal("second"))
+
+			container := keystoreInitContainer(cr, opsterv1.ImageSpec{})
+			Expect(container.Command).To(Equal([]string{"/bin/bash", "-c"}))
+			Expect(container.Args[0]).To(HavePrefix("set -e\n"))
+			Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+				{Name: "keystore", MountPath: "/mnt/keystore"},
+				{Name: "keystore-secret-0", MountPath: "/mnt/keystore-secrets/0", ReadOnly: true},
+				{Name: "keystore-secret-1", MountPath: "/mnt/keystore-secrets/1", ReadOnly: true},
+			}))
+		})
+	})
+
+	When("When building the statefulset of a node pool with an unknown vendor", func() {
+		It("should return an error", func() {
+			cr := newCluster()
+			cr.Spec.General.Vendor = "elasticsearch"
+			_, err := NewSTSForNodePool("admin", cr, cr.Spec.NodePools[0], "", nil, nil, nil)
+			Expect(err).To(MatchError("vendor elasticsearch is not supported"))
+		})
+	})
+
+	When("When building the statefulset of a node pool with a keystore", func() {
+		It("should build the keystore in an init container", func() {
+			cr := newCluster(opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "static"}})
+			sts, err := NewSTSForNodePool("admin", cr, cr.Spec.NodePools[0], "", nil, nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(containerNames(sts.Spec.Template.Spec.InitContainers)).To(ContainElement("keystore"))
+			Expect(containerNames(sts.Spec.Template.Spec.Containers)).ToNot(ContainElement("keystore-reloader"))
+			Expect(sts.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      "keystore",
+				MountPath: "/usr/share/opensearch/config/keystore",
+			}))
+		})
+
+		It("should only add the reloader sidecar for reloadable secrets", func() {
+			cr := newCluster(
+				opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "static"}},
+				opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "s3-credentials"}, Reloadable: true},
+			)
+			sts, err := NewSTSForNodePool("admin", cr, cr.Spec.NodePools[0], "", nil, nil, nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(containerNames(sts.Spec.Template.Spec.Containers)).To(ContainElement("keystore-reloader"))
+
+			reloader := keystoreReloaderContainer(cr, opsterv1.ImageSpec{}, "admin")
+			Expect(reloader.Args[0]).To(ContainSubstring("_nodes/_local/reload_secure_settings"))
+			Expect(reloader.Args[0]).To(ContainSubstring("${OPENSEARCH_PASSWORD}"))
+			Expect(reloader.Env[0]).To(Equal(corev1.EnvVar{Name: "OPENSEARCH_USER", Value: "admin"}))
+			Expect(reloader.VolumeMounts).To(Equal(keystoreVolumeMounts(cr)))
+		})
+
+		It("should read generated passwords from the mounted secret in the reloader sidecar", func() {
+			cr := newCluster(opsterv1.KeystoreValue{Secret: corev1.LocalObjectReference{Name: "s3-credentials"}, Reloadable: true})
+			cr.Spec.Security = &opsterv1.Security{Config: &opsterv1.SecurityConfig{GeneratePasswords: true}}
+			reloader := keystoreReloaderContainer(cr, opsterv1.ImageSpec{}, "admin")
+			Expect(reloader.Args[0]).To(ContainSubstring(adminPasswordVariable(cr)))
+			Expect(reloader.VolumeMounts).To(ContainElement(adminPasswordVolumeMount()))
+		})
+	})
+})
@@ -15,9 +15,12 @@ import (
 
 /// Package that declare and build all the resources that related to the OpenSearch-Dashboard ///
 
+// DashboardsOidcClientSecretEnv is the env var of the dashboards container that contains the OIDC client secret
+const DashboardsOidcClientSecretEnv = "OPENSEARCH_SECURITY_OPENID_CLIENT_SECRET"
+
 func NewDashboardsDeploymentForCR(cr *opsterv1.OpenSearchCluster, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) *appsv1.Deployment {
 	var replicas int32 = cr.Spec.Dashboards.Replicas
-	var port int32 = 5601
+	port := DashboardsPort(cr)
 	var mode int32 = 420
 	resources := cr.Spec.Dashboards.Resources
 
@@ -56,12 +59,22 @@ func NewDashboardsDeploymentForCR(cr *opsterv1.OpenSearchCluster, volumes []core
 		// Custom credentials supplied
 		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: cr.Spec.Dashboards.OpensearchCredentialsSecret, Key: "username"}}})
 		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: cr.Spec.Dashboards.OpensearchCredentialsSecret, Key: "password"}}})
+	} else if generatedPasswords(cr) {
+		// Built-in kibanaserver user with the password generated by the operator
+		credentials := corev1.LocalObjectReference{Name: helpers.GeneratedCredentialsSecretName(cr, "kibanaserver")}
+		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_USERNAME", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: credentials, Key: "username"}}})
+		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_PASSWORD", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: credentials, Key: "password"}}})
 	} else {
 		// Default values from demo configuration
 		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_USERNAME", Value: "admin"})
 		env = append(env, corev1.EnvVar{Name: "OPENSEARCH_PASSWORD", Value: "admin"})
 	}
 
+	if cr.Spec.Security != nil && cr.Spec.Security.Authentication != nil && cr.Spec.Security.Authentication.OIDC != nil {
+		clientSecret := cr.Spec.Security.Authentication.OIDC.ClientSecret
+		env = append(env, corev1.EnvVar{Name: DashboardsOidcClientSecretEnv, ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &clientSecret}})
+	}
+
 	labels := map[string]string{
 		"opensearch.cluster.dashboards": cr.Name,
 	}
@@ -137,6 +150,7 @@ func NewDashboardsDeploymentForCR(cr *opsterv1.OpenSearchCluster, volumes []core
 
 func NewDashboardsConfigMapForCR(cr *opsterv1.OpenSearchCluster, name string, config map[string]string) *corev1.ConfigMap {
 	config["server.name"] = cr.Name + "-dashboards"
+	config["server.port"] = fmt.Sprint(DashboardsPort(cr))
 	config["opensearch.ssl.verificationMode"] = "none"
 
 	keys := make([]string, 0, len(config))
@@ -165,7 +179,7 @@ func NewDashboardsConfigMapForCR(cr *opsterv1.OpenSearchCluster, name string, co
 
 func NewDashboardsSvcForCr(cr *opsterv1.OpenSearchCluster) *corev1.Service {
 
-	var port int32 = 5601
+	port := DashboardsPort(cr)
 
 	labels := map[string]string{
 		"opensearch.cluster.dashboards": cr.Name,
@@ -195,3 +209,11 @@ func NewDashboardsSvcForCr(cr *opsterv1.OpenSearchCluster) *corev1.Service {
 		},
 	}
 }
+
+// DashboardsPort returns the port of the dashboards http interface
+func DashboardsPort(cr *opsterv1.OpenSearchCluster) int32 {
+	if cr.Spec.Dashboards.Port > 0 {
+		return cr.Spec.Dashboards.Port
+	}
+	return opsterv1.DefaultDashboardsPort
+}
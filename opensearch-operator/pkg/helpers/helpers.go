@@ -3,6 +3,7 @@ package helpers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/hashicorp/go-version"
@@ -75,11 +76,24 @@ func FindByPath(obj interface{}, keys []string) (interface{}, bool) {
 	return val, ok
 }
 
+// GeneratedCredentialsSecretName returns the name of the secret with the password the operator generated for a built-in user
+func GeneratedCredentialsSecretName(cr *opsterv1.OpenSearchCluster, username string) string {
+	return fmt.Sprintf("%s-%s-credentials", cr.Name, username)
+}
+
 func UsernameAndPassword(ctx context.Context, k8sClient client.Client, cr *opsterv1.OpenSearchCluster) (string, string, error) {
-	if cr.Spec.Security != nil && cr.Spec.Security.Config != nil && cr.Spec.Security.Config.AdminCredentialsSecret.Name != "" {
+	var credentialsSecretName string
+	if cr.Spec.Security != nil && cr.Spec.Security.Config != nil {
+		if cr.Spec.Security.Config.AdminCredentialsSecret.Name != "" {
+			credentialsSecretName = cr.Spec.Security.Config.AdminCredentialsSecret.Name
+		} else if cr.Spec.Security.Config.GeneratePasswords {
+			credentialsSecretName = GeneratedCredentialsSecretName(cr, "admin")
+		}
+	}
+	if credentialsSecretName != "" {
 		// Read credentials from secret
 		credentialsSecret := corev1.Secret{}
-		if err := k8sClient.Get(ctx, client.ObjectKey{Name: cr.Spec.Security.Config.AdminCredentialsSecret.Name, Namespace: cr.Namespace}, &credentialsSecret); err != nil {
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: credentialsSecretName, Namespace: cr.Namespace}, &credentialsSecret); err != nil {
 			return "", "", err
 		}
 		username, usernameExists := credentialsSecret.Data["username"]
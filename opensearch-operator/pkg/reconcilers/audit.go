@@ -0,0 +1,291 @@
+package reconcilers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const auditTrustPath = "tls-audit"
+
+// Categories and users the security plugin excludes from the audit log by default
+var (
+	defaultAuditDisabledCategories = []opsterv1.AuditCategory{"AUTHENTICATED", "GRANTED_PRIVILEGES"}
+	defaultAuditIgnoreUsers        = []string{"kibanaserver"}
+)
+
+// addAuditConfig adds the settings of the audit sink to opensearch.yml. For an external cluster managed by the
+// operator the CA of its http certificates is copied into the audit trust secret and mounted into the nodes.
+// Until the external cluster and its CA exist the events are stored in the cluster itself.
+func (r *ConfigurationReconciler) addAuditConfig() error {
+	var audit *opsterv1.AuditConfig
+	if r.instance.Spec.Security != nil {
+		audit = r.instance.Spec.Security.Audit
+	}
+	if audit == nil {
+		r.reconcilerContext.AddConfig("plugins.security.audit.type", "internal_opensearch")
+		return nil
+	}
+
+	var hosts []string
+	var trustedCas string
+	if audit.Type == opsterv1.AuditSinkExternal && audit.External != nil {
+		external := audit.External
+		if external.OpensearchRef != nil {
+			var err error
+			hosts, err = r.reconcileAuditTrust(*external.OpensearchRef)
+			if err != nil {
+				return err
+			}
+			if hosts != nil {
+				trustedCas = fmt.Sprintf("%s/%s", auditTrustPath, CaCertKey)
+			}
+		} else {
+			hosts = external.Hosts
+			if external.CaSecret != nil {
+				r.mountAuditTrust(external.CaSecret.Name)
+				trustedCas = fmt.Sprintf("%s/%s", auditTrustPath, CaCertKey)
+			}
+		}
+	}
+
+	settings, err := auditSinkSettings(audit, hosts, trustedCas)
+	if err != nil {
+		return err
+	}
+	for key, value := range settings {
+		r.reconcilerContext.AddConfig(key, value)
+	}
+	return nil
+}
+
+// reconcileAuditTrust copies the CA of the http certificates of an external cluster managed by the operator into the
+// audit trust secret and returns the http endpoint of the cluster, nil if the cluster or its CA don't exist yet or the
+// cluster doesn't allow references from the namespace
+func (r *ConfigurationReconciler) reconcileAuditTrust(ref opsterv1.ClusterReference) ([]string, error) {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	name := ref.NamespacedName(r.instance.Namespace)
+	external, err := util.FetchOpensearchCluster(r.ctx, r.Client, name)
+	if err != nil {
+		return nil, err
+	}
+	if external == nil {
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Waiting for opensearch cluster %s to store the audit log", name)
+		return nil, nil
+	}
+	// Clusters in other namespaces have to allow references from the namespace of this cluster
+	allowed, err := util.ReferenceAllowed(r.ctx, r.Client, external, r.instance.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "opensearch cluster %s does not allow references from namespace %s", name, r.instance.Namespace)
+		return nil, nil
+	}
+	caSecretName := httpCaSecretName(external)
+	if caSecretName == "" {
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Opensearch cluster %s has no http certificates the audit log can be sent to", name)
+		return nil, nil
+	}
+	caSecret := corev1.Secret{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: caSecretName, Namespace: external.Namespace}, &caSecret); err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return nil, err
+		}
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Waiting for the http CA of opensearch cluster %s to store the audit log", name)
+		return nil, nil
+	}
+
+	trustSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      r.instance.Name + "-audit-trust",
+			Namespace: r.instance.Namespace,
+		},
+		Data: map[string][]byte{CaCertKey: caSecret.Data[CaCertKey]},
+	}
+	if err := ctrl.SetControllerReference(r.instance, trustSecret, r.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	if _, err := r.ReconcileResource(trustSecret, reconciler.StatePresent); err != nil {
+		return nil, err
+	}
+	r.mountAuditTrust(trustSecret.Name)
+	return []string{fmt.Sprintf("%s.svc.cluster.local:%d", builders.DnsOfService(external), builders.PortForCluster(external))}, nil
+}
+
+func (r *ConfigurationReconciler) mountAuditTrust(secretName string) {
+	volume := corev1.Volume{Name: "audit-trust", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: secretName}}}
+	r.reconcilerContext.Volumes = append(r.reconcilerContext.Volumes, volume)
+	mount := corev1.VolumeMount{Name: "audit-trust", MountPath: "/usr/share/opensearch/config/" + auditTrustPath}
+	r.reconcilerContext.VolumeMounts = append(r.reconcilerContext.VolumeMounts, mount)
+}
+
+// httpCaSecretName returns the secret that contains the CA of the http certificates of the cluster as ca.crt
+func httpCaSecretName(cluster *opsterv1.OpenSearchCluster) string {
+	security := cluster.Spec.Security
+	if security == nil || security.Tls == nil || security.Tls.Http == nil {
+		return ""
+	}
+	http := security.Tls.Http
+	if http.CaSecret.Name != "" {
+		return http.CaSecret.Name
+	}
+	if http.Generate {
+		return cluster.Name + "-http-cert"
+	}
+	return http.Secret.Name
+}
+
+// auditSinkSettings returns the opensearch.yml settings of the audit sink. The hosts and the trusted CAs file are
+// only used by the external sink, the credentials are read from env vars set by the statefulset.
+func auditSinkSettings(audit *opsterv1.AuditConfig, hosts []string, trustedCas string) (map[string]string, error) {
+	settings := map[string]string{}
+	if audit.Index != "" {
+		settings["plugins.security.audit.config.index"] = fmt.Sprintf("%q", audit.Index)
+	}
+
+	// Sinks that are not configured (yet) fall back to the internal sink
+	switch audit.Type {
+	case opsterv1.AuditSinkExternal:
+		if len(hosts) == 0 {
+			settings["plugins.security.audit.type"] = "internal_opensearch"
+			return settings, nil
+		}
+		settings["plugins.security.audit.type"] = "external_opensearch"
+		endpoints, err := json.Marshal(hosts)
+		if err != nil {
+			return nil, err
+		}
+		settings["plugins.security.audit.config.http_endpoints"] = string(endpoints)
+		external := audit.External
+		if trustedCas != "" || external.EnableSSL {
+			settings["plugins.security.audit.config.enable_ssl"] = "true"
+		}
+		if trustedCas != "" {
+			settings["plugins.security.audit.config.pemtrustedcas_filepath"] = trustedCas
+		}
+		if external.OpensearchRef != nil {
+			// The CA of the cluster is trusted, its generated certificates don't contain the name of its service
+			settings["plugins.security.audit.config.verify_hostnames"] = "false"
+		}
+		if external.CredentialsSecret != nil {
+			settings["plugins.security.audit.config.username"] = fmt.Sprintf("${%s}", builders.AuditUsernameEnv)
+			settings["plugins.security.audit.config.password"] = fmt.Sprintf("${%s}", builders.AuditPasswordEnv)
+		}
+	case opsterv1.AuditSinkWebhook:
+		webhook := audit.Webhook
+		if webhook == nil || webhook.URL == "" {
+			settings["plugins.security.audit.type"] = "internal_opensearch"
+			return settings, nil
+		}
+		settings["plugins.security.audit.type"] = "webhook"
+		settings["plugins.security.audit.config.webhook.url"] = fmt.Sprintf("%q", webhook.URL)
+		format := webhook.Format
+		if format == "" {
+			format = "JSON"
+		}
+		settings["plugins.security.audit.config.webhook.format"] = format
+		if webhook.SSLVerify != nil {
+			settings["plugins.security.audit.config.webhook.ssl.verify"] = fmt.Sprint(*webhook.SSLVerify)
+		}
+	case opsterv1.AuditSinkLog4j:
+		settings["plugins.security.audit.type"] = "log4j"
+		if log4j := audit.Log4j; log4j != nil {
+			if log4j.LoggerName != "" {
+				settings["plugins.security.audit.config.log4j.logger_name"] = log4j.LoggerName
+			}
+			if log4j.Level != "" {
+				settings["plugins.security.audit.config.log4j.level"] = log4j.Level
+			}
+		}
+	default:
+		settings["plugins.security.audit.type"] = "internal_opensearch"
+	}
+	return settings, nil
+}
+
+// auditConfigYml renders the audit.yml of the securityconfig, settings that are not configured get the defaults of
+// the security plugin
+func auditConfigYml(audit *opsterv1.AuditConfig) ([]byte, error) {
+	disabledRest := audit.DisabledRestCategories
+	if len(disabledRest) == 0 {
+		disabledRest = defaultAuditDisabledCategories
+	}
+	disabledTransport := audit.DisabledTransportCategories
+	if len(disabledTransport) == 0 {
+		disabledTransport = defaultAuditDisabledCategories
+	}
+	ignoreUsers := audit.IgnoreUsers
+	if len(ignoreUsers) == 0 {
+		ignoreUsers = defaultAuditIgnoreUsers
+	}
+	ignoreRequests := audit.IgnoreRequests
+	if ignoreRequests == nil {
+		ignoreRequests = []string{}
+	}
+
+	compliance := audit.Compliance
+	if compliance == nil {
+		compliance = &opsterv1.AuditComplianceConfig{}
+	}
+	readWatchedFields := compliance.ReadWatchedFields
+	if readWatchedFields == nil {
+		readWatchedFields = map[string][]string{}
+	}
+	readIgnoreUsers := compliance.ReadIgnoreUsers
+	if len(readIgnoreUsers) == 0 {
+		readIgnoreUsers = defaultAuditIgnoreUsers
+	}
+	writeWatchedIndices := compliance.WriteWatchedIndices
+	if writeWatchedIndices == nil {
+		writeWatchedIndices = []string{}
+	}
+	writeIgnoreUsers := compliance.WriteIgnoreUsers
+	if len(writeIgnoreUsers) == 0 {
+		writeIgnoreUsers = defaultAuditIgnoreUsers
+	}
+
+	return yaml.Marshal(map[string]interface{}{
+		"_meta": map[string]interface{}{"type": "audit", "config_version": 2},
+		"config": map[string]interface{}{
+			"enabled": enabledOrDefault(audit.Enabled),
+			"audit": map[string]interface{}{
+				"enable_rest":                   enabledOrDefault(audit.EnableRest),
+				"disabled_rest_categories":      disabledRest,
+				"enable_transport":              enabledOrDefault(audit.EnableTransport),
+				"disabled_transport_categories": disabledTransport,
+				"ignore_users":                  ignoreUsers,
+				"ignore_requests":               ignoreRequests,
+				"log_request_body":              enabledOrDefault(audit.LogRequestBody),
+				"resolve_indices":               enabledOrDefault(audit.ResolveIndices),
+				"resolve_bulk_requests":         audit.ResolveBulkRequests,
+				"exclude_sensitive_headers":     enabledOrDefault(audit.ExcludeSensitiveHeaders),
+			},
+			"compliance": map[string]interface{}{
+				"enabled":               enabledOrDefault(compliance.Enabled),
+				"internal_config":       enabledOrDefault(compliance.InternalConfig),
+				"external_config":       compliance.ExternalConfig,
+				"read_metadata_only":    enabledOrDefault(compliance.ReadMetadataOnly),
+				"read_watched_fields":   readWatchedFields,
+				"read_ignore_users":     readIgnoreUsers,
+				"write_metadata_only":   enabledOrDefault(compliance.WriteMetadataOnly),
+				"write_log_diffs":       compliance.WriteLogDiffs,
+				"write_watched_indices": writeWatchedIndices,
+				"write_ignore_users":    writeIgnoreUsers,
+			},
+		},
+	})
+}
+
+func enabledOrDefault(enabled *bool) bool {
+	return enabled == nil || *enabled
+}
@@ -0,0 +1,77 @@
+package reconcilers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/builders"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit config", func() {
+
+	When("When rendering the settings of the audit sink", func() {
+		It("should configure an external cluster managed by the operator", func() {
+			audit := &opsterv1.AuditConfig{
+				Type: opsterv1.AuditSinkExternal,
+				External: &opsterv1.AuditExternalSink{
+					OpensearchRef:     &opsterv1.ClusterReference{Name: "audit", Namespace: "logging"},
+					CredentialsSecret: &corev1.LocalObjectReference{Name: "audit-credentials"},
+				},
+			}
+			settings, err := auditSinkSettings(audit, []string{"audit.logging.svc.cluster.local:9200"}, "tls-audit/ca.crt")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.type", "external_opensearch"))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.http_endpoints", `["audit.logging.svc.cluster.local:9200"]`))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.enable_ssl", "true"))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.pemtrustedcas_filepath", "tls-audit/ca.crt"))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.password", "${"+builders.AuditPasswordEnv+"}"))
+		})
+
+		It("should fall back to the internal sink until the external cluster exists", func() {
+			audit := &opsterv1.AuditConfig{
+				Type:     opsterv1.AuditSinkExternal,
+				Index:    "'audit-'YYYY.MM.dd",
+				External: &opsterv1.AuditExternalSink{OpensearchRef: &opsterv1.ClusterReference{Name: "audit"}},
+			}
+			settings, err := auditSinkSettings(audit, nil, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(Equal(map[string]string{
+				"plugins.security.audit.type":         "internal_opensearch",
+				"plugins.security.audit.config.index": `"'audit-'YYYY.MM.dd"`,
+			}))
+		})
+
+		It("should configure a webhook", func() {
+			audit := &opsterv1.AuditConfig{
+				Type:    opsterv1.AuditSinkWebhook,
+				Webhook: &opsterv1.AuditWebhookSink{URL: "https://audit.example.com/events"},
+			}
+			settings, err := auditSinkSettings(audit, nil, "")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.type", "webhook"))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.webhook.url", `"https://audit.example.com/events"`))
+			Expect(settings).To(HaveKeyWithValue("plugins.security.audit.config.webhook.format", "JSON"))
+		})
+	})
+
+	When("When rendering the audit.yml", func() {
+		It("should use the defaults of the security plugin for settings that are not configured", func() {
+			enabled := false
+			data, err := auditConfigYml(&opsterv1.AuditConfig{
+				EnableTransport: &enabled,
+				IgnoreUsers:     []string{"monitoring"},
+				Compliance:      &opsterv1.AuditComplianceConfig{ReadWatchedFields: map[string][]string{"customers": {"email"}}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).To(ContainSubstring("type: audit"))
+			Expect(string(data)).To(ContainSubstring("enable_rest: true"))
+			Expect(string(data)).To(ContainSubstring("enable_transport: false"))
+			Expect(string(data)).To(ContainSubstring("ignore_users:\n    - monitoring\n"))
+			Expect(string(data)).To(ContainSubstring("disabled_rest_categories:\n    - AUTHENTICATED\n    - GRANTED_PRIVILEGES\n"))
+			Expect(string(data)).To(ContainSubstring("read_watched_fields:\n      customers:\n      - email\n"))
+			Expect(string(data)).To(ContainSubstring("write_ignore_users:\n    - kibanaserver\n"))
+		})
+	})
+})
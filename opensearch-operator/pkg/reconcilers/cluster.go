@@ -2,13 +2,16 @@ package reconcilers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/banzaicloud/k8s-objectmatcher/patch"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -84,13 +87,30 @@ func (r *ClusterReconciler) Reconcile() (ctrl.Result, error) {
 		result.Combine(r.ReconcileResource(bootstrapPod, reconciler.StatePresent))
 	}
 
+	incompatibleNodePools, err := r.reconcilePluginCompatibility()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var invalidNodePools []string
 	for _, nodePool := range r.instance.Spec.NodePools {
 		headlessService := builders.NewHeadlessServiceForNodePool(r.instance, &nodePool)
 		result.CombineErr(ctrl.SetControllerReference(r.instance, headlessService, r.Client.Scheme()))
 		result.Combine(r.ReconcileResource(headlessService, reconciler.StatePresent))
 
-		result.Combine(r.reconcileNodeStatefulSet(nodePool, username))
+		// Don't roll out plugins that would prevent the nodes from starting
+		if helpers.ContainsString(incompatibleNodePools, nodePool.Component) {
+			continue
+		}
+		nodePoolResult, err := r.reconcileNodeStatefulSet(nodePool, username)
+		var invalid *invalidNodePoolError
+		if errors.As(err, &invalid) {
+			invalidNodePools = append(invalidNodePools, invalid.Error())
+			continue
+		}
+		result.Combine(nodePoolResult, err)
 	}
+	result.CombineErr(r.reconcileNodePoolsCondition(invalidNodePools))
 
 	// if Version isn't set we set it now to check for upgrades later.
 	if r.instance.Status.Version == "" {
@@ -119,7 +139,7 @@ func (r *ClusterReconciler) reconcileNodeStatefulSet(nodePool opsterv1.NodePool,
 
 	extraConfig := helpers.MergeConfigs(r.instance.Spec.General.AdditionalConfig, nodePool.AdditionalConfig)
 
-	sts := builders.NewSTSForNodePool(
+	sts, err := builders.NewSTSForNodePool(
 		username,
 		r.instance,
 		nodePool,
@@ -128,10 +148,22 @@ func (r *ClusterReconciler) reconcileNodeStatefulSet(nodePool opsterv1.NodePool,
 		r.reconcilerContext.VolumeMounts,
 		extraConfig,
 	)
+	if err != nil {
+		return &ctrl.Result{}, &invalidNodePoolError{nodePool: nodePool.Component, err: err}
+	}
 	if err := ctrl.SetControllerReference(r.instance, sts, r.Client.Scheme()); err != nil {
 		return &ctrl.Result{}, err
 	}
 
+	// Restart the nodes when keystore secrets change that can't be reloaded
+	keystoreChecksum, err := r.keystoreChecksum()
+	if err != nil {
+		return &ctrl.Result{}, err
+	}
+	if keystoreChecksum != "" {
+		sts.Spec.Template.Annotations[builders.KeystoreChecksumAnnotation] = keystoreChecksum
+	}
+
 	// First ensure that the statefulset exists
 	result, err := r.ReconcileResource(sts, reconciler.StateCreated)
 	if err != nil || result != nil {
@@ -200,18 +232,113 @@ func (r *ClusterReconciler) reconcileNodeStatefulSet(nodePool opsterv1.NodePool,
 	// This will allow the scaler reconciler to function correctly
 	sts.Spec.Replicas = existing.Spec.Replicas
 
-	// Don't update env vars on non data nodes while an upgrade is in progress
-	// as we don't want uncontrolled restarts while we're doing an upgrade
-	if r.instance.Status.Version != "" &&
-		r.instance.Status.Version != r.instance.Spec.General.Version &&
-		!helpers.ContainsString(nodePool.Roles, "data") {
-		sts.Spec.Template.Spec.Containers[0].Env = existing.Spec.Template.Spec.Containers[0].Env
-	}
-
 	// Finally we enforce the desired state
 	return r.ReconcileResource(sts, reconciler.StatePresent)
 }
 
+// keystoreChecksum returns a checksum of the keystore secrets that are not reloaded by the nodes
+func (r *ClusterReconciler) keystoreChecksum() (string, error) {
+	data := map[string][]byte{}
+	for _, value := range r.instance.Spec.General.Keystore {
+		if value.Reloadable {
+			continue
+		}
+		secret := &corev1.Secret{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      value.Secret.Name,
+			Namespace: r.instance.Namespace,
+		}, secret); err != nil {
+			return "", fmt.Errorf("failed to fetch keystore secret %s: %w", value.Secret.Name, err)
+		}
+		for key, val := range secret.Data {
+			data[fmt.Sprintf("%s/%s", value.Secret.Name, key)] = val
+		}
+	}
+	if len(data) == 0 {
+		return "", nil
+	}
+	return checksum(data)
+}
+
+// reconcilePluginCompatibility checks that the plugins of all node pools are built for the version of the cluster
+// and returns the node pools with incompatible plugins
+func (r *ClusterReconciler) reconcilePluginCompatibility() ([]string, error) {
+	version := r.instance.Spec.General.Version
+	configured := false
+	var nodePools []string
+	var failures []string
+	for _, nodePool := range r.instance.Spec.NodePools {
+		install, remove := builders.NodePoolPlugins(r.instance, &nodePool)
+		configured = configured || len(install) > 0 || len(remove) > 0
+		for _, plugin := range incompatiblePlugins(r.instance, &nodePool, version) {
+			failures = append(failures, fmt.Sprintf("plugin %s of node pool %s does not match version %s", plugin, nodePool.Component, version))
+			if !helpers.ContainsString(nodePools, nodePool.Component) {
+				nodePools = append(nodePools, nodePool.Component)
+			}
+		}
+	}
+
+	existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionPluginsCompatible)
+	if !configured && existing == nil {
+		return nil, nil
+	}
+	condition := metav1.Condition{
+		Type:    opsterv1.ConditionPluginsCompatible,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Compatible",
+		Message: fmt.Sprintf("All plugins match version %s", version),
+	}
+	if len(failures) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "IncompatiblePlugins"
+		condition.Message = strings.Join(failures, "; ")
+		if existing == nil || existing.Message != condition.Message {
+			annotations := map[string]string{"cluster-name": r.instance.GetName()}
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Plugins", "%s", condition.Message)
+		}
+	}
+	return nodePools, UpdateOpensearchCondition(r.ctx, r.Client, r.instance, condition)
+}
+
+// invalidNodePoolError is returned if the statefulset of a node pool can't be built from the spec
+type invalidNodePoolError struct {
+	nodePool string
+	err      error
+}
+
+func (e *invalidNodePoolError) Error() string {
+	return fmt.Sprintf("node pool %s: %s", e.nodePool, e.err)
+}
+
+func (e *invalidNodePoolError) Unwrap() error {
+	return e.err
+}
+
+// reconcileNodePoolsCondition reports the node pools whose statefulsets can't be built in the NodePoolsValid
+// condition. Retrying doesn't help until the spec is changed, so these are not returned as errors.
+func (r *ClusterReconciler) reconcileNodePoolsCondition(failures []string) error {
+	existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionNodePoolsValid)
+	if len(failures) == 0 && existing == nil {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:    opsterv1.ConditionNodePoolsValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  "Valid",
+		Message: "The statefulsets of all node pools are reconciled",
+	}
+	if len(failures) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidNodePool"
+		condition.Message = strings.Join(failures, "; ")
+		if existing == nil || existing.Message != condition.Message {
+			annotations := map[string]string{"cluster-name": r.instance.GetName()}
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "NodePool", "%s", condition.Message)
+		}
+	}
+	return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, condition)
+}
+
 func (r *ClusterReconciler) DeleteResources() (ctrl.Result, error) {
 	result := reconciler.CombinedResult{}
 	return result.Result, result.Err
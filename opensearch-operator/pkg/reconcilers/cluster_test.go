@@ -0,0 +1,83 @@
+package reconcilers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cluster Reconciler", func() {
+	const (
+		clusterName = "cluster-keystore"
+		timeout     = time.Second * 10
+		interval    = time.Second * 1
+	)
+
+	When("When calculating the checksum of the keystore secrets", func() {
+		It("should ignore reloadable secrets", func() {
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			static := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "static", Namespace: clusterName},
+				Data:       map[string][]byte{"key": []byte("static")},
+			}
+			reloadable := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "reloadable", Namespace: clusterName},
+				Data:       map[string][]byte{"key": []byte("reloadable")},
+			}
+			Expect(k8sClient.Create(context.Background(), static)).To(Succeed())
+			Expect(k8sClient.Create(context.Background(), reloadable)).To(Succeed())
+
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{
+						Keystore: []opsterv1.KeystoreValue{
+							{Secret: corev1.LocalObjectReference{Name: "static"}},
+							{Secret: corev1.LocalObjectReference{Name: "reloadable"}, Reloadable: true},
+						},
+					},
+				},
+			}
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewClusterReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+
+			before, err := underTest.keystoreChecksum()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(before).ToNot(BeEmpty())
+
+			// Changes of reloadable secrets don't restart the nodes
+			reloadable.Data["key"] = []byte("rotated")
+			Expect(k8sClient.Update(context.Background(), reloadable)).To(Succeed())
+			Eventually(func() string {
+				checksum, _ := underTest.keystoreChecksum()
+				return checksum
+			}, timeout, interval).Should(Equal(before))
+
+			static.Data["key"] = []byte("rotated")
+			Expect(k8sClient.Update(context.Background(), static)).To(Succeed())
+			Eventually(func() string {
+				checksum, _ := underTest.keystoreChecksum()
+				return checksum
+			}, timeout, interval).ShouldNot(Equal(before))
+
+			// Only reloadable secrets don't need a checksum
+			spec.Spec.General.Keystore = spec.Spec.General.Keystore[1:]
+			checksum, err := underTest.keystoreChecksum()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(checksum).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,440 @@
+package reconcilers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/helpers"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	operationStarted   = "OperationStarted"
+	operationSucceeded = "OperationSucceeded"
+	operationFailed    = "OperationFailed"
+)
+
+// errInvalidStep is returned for steps that can never succeed, e.g. because the pod doesn't exist
+var errInvalidStep = errors.New("invalid step")
+
+// podNotFoundError is the invalid step error for a pod that doesn't exist
+type podNotFoundError struct {
+	name string
+}
+
+func (e podNotFoundError) Error() string {
+	return fmt.Sprintf("%s: pod %s not found", errInvalidStep, e.name)
+}
+
+func (e podNotFoundError) Unwrap() error {
+	return errInvalidStep
+}
+
+type ClusterOperationReconciler struct {
+	client.Client
+	ReconcilerOptions
+	ctx      context.Context
+	osClient *services.OsClusterClient
+	recorder record.EventRecorder
+	instance *opsterv1.OpensearchClusterOperation
+	cluster  *opsterv1.OpenSearchCluster
+	logger   logr.Logger
+}
+
+func NewClusterOperationReconciler(
+	ctx context.Context,
+	client client.Client,
+	recorder record.EventRecorder,
+	instance *opsterv1.OpensearchClusterOperation,
+	opts ...ReconcilerOption,
+) *ClusterOperationReconciler {
+	options := ReconcilerOptions{}
+	options.apply(opts...)
+	return &ClusterOperationReconciler{
+		Client:            client,
+		ReconcilerOptions: options,
+		ctx:               ctx,
+		recorder:          recorder,
+		instance:          instance,
+		logger:            log.FromContext(ctx).WithValues("reconciler", "clusteroperation"),
+	}
+}
+
+// Reconcile executes the steps of the operation one after the other. An operation is executed
+// exactly once, once it has succeeded or failed it is never run again.
+func (r *ClusterOperationReconciler) Reconcile() (ctrl.Result, error) {
+	switch r.instance.Status.State {
+	case opsterv1.OpensearchClusterOperationStateSucceeded, opsterv1.OpensearchClusterOperationStateFailed:
+		return ctrl.Result{}, nil
+	}
+
+	var err error
+	r.cluster, err = util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
+		Name:      r.instance.Spec.OpensearchRef.Name,
+		Namespace: r.instance.Namespace,
+	})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.cluster == nil {
+		return r.waitForCluster("waiting for opensearch cluster to exist")
+	}
+	if r.instance.Status.ManagedCluster != nil && *r.instance.Status.ManagedCluster != r.cluster.UID {
+		return ctrl.Result{}, r.failOperation("cannot change the cluster an operation refers to")
+	}
+	if r.cluster.Status.Phase != opsterv1.PhaseRunning {
+		return r.waitForCluster("waiting for opensearch cluster status to be running")
+	}
+	if r.cluster.ReconcilePaused(opsterv1.PauseOperations) {
+		return r.waitForCluster(ErrClusterPaused.Error())
+	}
+	if r.cluster.Status.Version != r.cluster.Spec.General.Version || upgradeStarted(r.cluster) {
+		return r.waitForCluster("waiting for the upgrade of the opensearch cluster to finish")
+	}
+
+	// Record the start of the operation and of each step before doing any work so
+	// steps can tell which pods they have already restarted
+	if r.instance.Status.State != opsterv1.OpensearchClusterOperationStateRunning {
+		r.recorder.Event(r.instance, "Normal", operationStarted, "starting operation")
+		return ctrl.Result{Requeue: true}, r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+			now := metav1.Now()
+			status.State = opsterv1.OpensearchClusterOperationStateRunning
+			status.Reason = ""
+			status.ManagedCluster = &r.cluster.UID
+			status.StartTime = &now
+			status.Steps = nil
+			for _, step := range r.instance.Spec.Steps {
+				status.Steps = append(status.Steps, opsterv1.OperationStepStatus{
+					Action: step.Action,
+					State:  opsterv1.OpensearchClusterOperationStatePending,
+				})
+			}
+		})
+	}
+
+	if timeout := r.instance.Spec.Timeout; timeout != nil && r.instance.Status.StartTime != nil &&
+		time.Since(r.instance.Status.StartTime.Time) > timeout.Duration {
+		return ctrl.Result{}, r.failOperation(fmt.Sprintf("operation did not finish within %s", timeout.Duration))
+	}
+
+	index := -1
+	for i, stepStatus := range r.instance.Status.Steps {
+		if stepStatus.State != opsterv1.OpensearchClusterOperationStateSucceeded {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		r.recorder.Event(r.instance, "Normal", operationSucceeded, "operation succeeded")
+		return ctrl.Result{}, r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+			now := metav1.Now()
+			status.State = opsterv1.OpensearchClusterOperationStateSucceeded
+			status.CompletionTime = &now
+		})
+	}
+	if r.instance.Status.Steps[index].State == opsterv1.OpensearchClusterOperationStatePending {
+		return ctrl.Result{Requeue: true}, r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+			now := metav1.Now()
+			status.Steps[index].State = opsterv1.OpensearchClusterOperationStateRunning
+			status.Steps[index].StartTime = &now
+		})
+	}
+
+	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	step := r.instance.Spec.Steps[index]
+	stepStatus := r.instance.Status.Steps[index]
+	done, message, err := r.executeStep(step, stepStatus)
+	if errors.Is(err, errInvalidStep) {
+		return ctrl.Result{}, r.failOperation(fmt.Sprintf("step %d (%s) failed: %s", index+1, step.Action, err))
+	}
+	if err != nil {
+		r.logger.Error(err, "failed to execute step", "step", index+1, "action", step.Action)
+		return ctrl.Result{}, err
+	}
+
+	if done {
+		r.logger.Info("finished step", "step", index+1, "action", step.Action)
+		return ctrl.Result{Requeue: true}, r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+			now := metav1.Now()
+			status.Steps[index].State = opsterv1.OpensearchClusterOperationStateSucceeded
+			status.Steps[index].Message = message
+			status.Steps[index].CompletionTime = &now
+		})
+	}
+	if message != stepStatus.Message {
+		if err := r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+			status.Steps[index].Message = message
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{
+		Requeue:      true,
+		RequeueAfter: 10 * time.Second,
+	}, nil
+}
+
+func (r *ClusterOperationReconciler) waitForCluster(reason string) (ctrl.Result, error) {
+	r.logger.Info(reason)
+	err := r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+		if status.State == "" {
+			status.State = opsterv1.OpensearchClusterOperationStatePending
+		}
+		status.Reason = reason
+	})
+	return ctrl.Result{
+		Requeue:      true,
+		RequeueAfter: 10 * time.Second,
+	}, err
+}
+
+func (r *ClusterOperationReconciler) failOperation(reason string) error {
+	r.recorder.Event(r.instance, "Warning", operationFailed, reason)
+	return r.patchStatus(func(status *opsterv1.OpensearchClusterOperationStatus) {
+		now := metav1.Now()
+		status.State = opsterv1.OpensearchClusterOperationStateFailed
+		status.Reason = reason
+		status.CompletionTime = &now
+		for i := range status.Steps {
+			if status.Steps[i].State == opsterv1.OpensearchClusterOperationStateRunning {
+				status.Steps[i].State = opsterv1.OpensearchClusterOperationStateFailed
+				status.Steps[i].CompletionTime = &now
+			}
+		}
+	})
+}
+
+func (r *ClusterOperationReconciler) patchStatus(mutate func(*opsterv1.OpensearchClusterOperationStatus)) error {
+	if !pointer.BoolDeref(r.updateStatus, true) {
+		mutate(&r.instance.Status)
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		mutate(&r.instance.Status)
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+// executeStep performs the next part of a step and returns whether the step is done and its progress
+func (r *ClusterOperationReconciler) executeStep(step opsterv1.OperationStep, stepStatus opsterv1.OperationStepStatus) (bool, string, error) {
+	switch step.Action {
+	case opsterv1.OperationRestartNodePool:
+		return r.restartNodePool(step, stepStatus)
+	case opsterv1.OperationRestartPod:
+		return r.restartSinglePod(step, stepStatus)
+	case opsterv1.OperationDrainNode:
+		pod, _, err := r.fetchClusterPod(step.Pod)
+		if err != nil {
+			return false, "", err
+		}
+		if _, err := services.AppendExcludeNodeHost(r.osClient, pod.Name); err != nil {
+			return false, "", err
+		}
+		hasShards, err := services.HasShardsOnNode(r.osClient, pod.Name)
+		if err != nil || hasShards {
+			return false, fmt.Sprintf("waiting for the shards to move away from %s", pod.Name), err
+		}
+		return true, fmt.Sprintf("%s is drained and excluded from shard allocation", pod.Name), nil
+	case opsterv1.OperationClearAllocationExcludes:
+		return true, "cleared the shard allocation excludes", services.ClearExcludeNodeHosts(r.osClient)
+	case opsterv1.OperationSetShardAllocation:
+		if step.Allocation == "" {
+			return false, "", fmt.Errorf("%w: allocation is required", errInvalidStep)
+		}
+		err := services.SetClusterShardAllocation(r.osClient, services.ClusterSettingsAllocation(step.Allocation))
+		return true, fmt.Sprintf("set shard allocation to %s", step.Allocation), err
+	case opsterv1.OperationRerouteShards:
+		return true, "retried the allocation of failed shards", r.osClient.RetryFailedShards()
+	case opsterv1.OperationFlush:
+		return true, "flushed all indices", r.osClient.FlushIndices()
+	default:
+		return false, "", fmt.Errorf("%w: unknown action %s", errInvalidStep, step.Action)
+	}
+}
+
+// restartNodePool restarts all pods of the node pool that were created before the step started,
+// highest ordinal first, waiting for the cluster to recover after each pod
+func (r *ClusterOperationReconciler) restartNodePool(step opsterv1.OperationStep, stepStatus opsterv1.OperationStepStatus) (bool, string, error) {
+	var nodePool *opsterv1.NodePool
+	for i := range r.cluster.Spec.NodePools {
+		if r.cluster.Spec.NodePools[i].Component == step.NodePool {
+			nodePool = &r.cluster.Spec.NodePools[i]
+		}
+	}
+	if nodePool == nil {
+		return false, "", fmt.Errorf("%w: node pool %s not found", errInvalidStep, step.NodePool)
+	}
+
+	sts := &appsv1.StatefulSet{}
+	if err := r.Get(r.ctx, types.NamespacedName{
+		Name:      builders.StsName(r.cluster, nodePool),
+		Namespace: r.cluster.Namespace,
+	}, sts); err != nil {
+		return false, "", err
+	}
+	replicas := pointer.Int32Deref(sts.Spec.Replicas, 1)
+	if sts.Status.ReadyReplicas != replicas {
+		return false, "waiting for all pods to be ready", nil
+	}
+	var nodeNames []string
+	for i := int32(0); i < replicas; i++ {
+		nodeNames = append(nodeNames, builders.ReplicaHostName(*sts, i))
+	}
+	joined, err := services.NodesJoined(r.osClient, nodeNames)
+	if err != nil || !joined {
+		return false, "waiting for all nodes to join the cluster", err
+	}
+
+	clusterManager := builders.IsClusterManagerSts(*sts)
+	restarted := 0
+	var pending []string
+	for i := replicas - 1; i >= 0; i-- {
+		pod := &corev1.Pod{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      builders.ReplicaHostName(*sts, i),
+			Namespace: sts.Namespace,
+		}, pod); err != nil {
+			return false, "", err
+		}
+		if !pod.CreationTimestamp.Before(stepStatus.StartTime) {
+			restarted++
+			// The restarted node has rejoined the cluster and can vote again. Like for the scaler, the exclusions of
+			// other nodes are briefly missing and those of unknown nodes are lost, see clearVotingExclusions.
+			if clusterManager {
+				if _, err := services.ClearVotingConfigExclusion(r.osClient, pod.Name); err != nil {
+					return false, "", err
+				}
+			}
+			continue
+		}
+		pending = append(pending, pod.Name)
+	}
+	if len(pending) == 0 {
+		return true, fmt.Sprintf("restarted %d pods", restarted), nil
+	}
+
+	// Like the rolling restart the elected cluster manager is restarted last. The node is excluded from voting first
+	// so a new cluster manager is elected before it leaves the cluster.
+	if clusterManager {
+		electedClusterManager, err := services.GetElectedClusterManager(r.osClient)
+		if err != nil {
+			return false, "", err
+		}
+		pending = electedClusterManagerLast(pending, electedClusterManager)
+		if _, err := services.AppendVotingConfigExclusion(r.osClient, pending[0]); err != nil {
+			return false, "", err
+		}
+	}
+	dataNode := helpers.ContainsString(nodePool.Roles, "data")
+	message, err := r.restartPod(pending[0], dataNode, step.Drain)
+	return false, message, err
+}
+
+// restartSinglePod restarts the pod if it was created before the step started and waits for it to rejoin the cluster
+func (r *ClusterOperationReconciler) restartSinglePod(step opsterv1.OperationStep, stepStatus opsterv1.OperationStepStatus) (bool, string, error) {
+	pod, dataNode, err := r.fetchClusterPod(step.Pod)
+	// The step only records a message after it found the pod, so a missing pod was deleted by the step
+	// and is not recreated by the statefulset yet
+	if errors.As(err, &podNotFoundError{}) && stepStatus.Message != "" {
+		return false, fmt.Sprintf("waiting for %s to be recreated", step.Pod), nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	if pod.CreationTimestamp.Before(stepStatus.StartTime) {
+		message, err := r.restartPod(pod.Name, dataNode, step.Drain)
+		return false, message, err
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			joined, err := services.NodesJoined(r.osClient, []string{pod.Name})
+			if err != nil || !joined {
+				return false, fmt.Sprintf("waiting for %s to join the cluster", pod.Name), err
+			}
+			return true, fmt.Sprintf("restarted %s", pod.Name), nil
+		}
+	}
+	return false, fmt.Sprintf("waiting for %s to be ready", pod.Name), nil
+}
+
+// restartPod deletes the pod once the cluster is healthy, data nodes are prepared or drained first
+func (r *ClusterOperationReconciler) restartPod(podName string, dataNode bool, drain bool) (string, error) {
+	ready, err := services.CheckClusterStatusForRestart(r.osClient, drain)
+	if err != nil || !ready {
+		return "waiting for the cluster to be healthy", err
+	}
+
+	if dataNode {
+		dataCount := builders.DataNodesCount(r.ctx, r.Client, r.cluster)
+		ready, err = services.PreparePodForDelete(r.osClient, podName, drain, dataCount)
+		if err != nil || !ready {
+			return fmt.Sprintf("waiting for the shards to move away from %s", podName), err
+		}
+	}
+
+	r.logger.Info("Restarting pod", "pod", podName)
+	if err := r.Delete(r.ctx, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: r.cluster.Namespace,
+		},
+	}); err != nil {
+		return "", err
+	}
+
+	// If the node was drained remove the exclusion after the pod is deleted
+	if dataNode && drain {
+		if _, err := services.RemoveExcludeNodeHost(r.osClient, podName); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("restarting %s", podName), nil
+}
+
+// fetchClusterPod returns the pod if it belongs to the cluster and whether it is a data node
+func (r *ClusterOperationReconciler) fetchClusterPod(name string) (*corev1.Pod, bool, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(r.ctx, types.NamespacedName{
+		Name:      name,
+		Namespace: r.cluster.Namespace,
+	}, pod); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, false, podNotFoundError{name: name}
+		}
+		return nil, false, err
+	}
+	if pod.Labels[builders.ClusterLabel] != r.cluster.Name {
+		return nil, false, fmt.Errorf("%w: pod %s does not belong to cluster %s", errInvalidStep, name, r.cluster.Name)
+	}
+	for _, nodePool := range r.cluster.Spec.NodePools {
+		if nodePool.Component == pod.Labels[builders.NodePoolLabel] {
+			return pod, helpers.ContainsString(nodePool.Roles, "data"), nil
+		}
+	}
+	return pod, false, nil
+}
@@ -0,0 +1,287 @@
+package reconcilers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("cluster operation reconciler", func() {
+	var (
+		transport  *httpmock.MockTransport
+		reconciler *ClusterOperationReconciler
+		instance   *opsterv1.OpensearchClusterOperation
+		recorder   *record.FakeRecorder
+
+		// Objects
+		ns      *corev1.Namespace
+		cluster *opsterv1.OpenSearchCluster
+	)
+
+	BeforeEach(func() {
+		transport = httpmock.NewMockTransport()
+		transport.RegisterNoResponder(httpmock.NewNotFoundResponder(failMessage))
+		recorder = record.NewFakeRecorder(10)
+		instance = &opsterv1.OpensearchClusterOperation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-operation",
+				Namespace: "test-clusteroperation",
+				UID:       types.UID("testuid"),
+			},
+			Spec: opsterv1.OpensearchClusterOperationSpec{
+				OpensearchRef: corev1.LocalObjectReference{
+					Name: "test-cluster",
+				},
+				Steps: []opsterv1.OperationStep{
+					{
+						Action:     opsterv1.OperationSetShardAllocation,
+						Allocation: "primaries",
+					},
+				},
+			},
+		}
+
+		// Sleep for cache to start
+		time.Sleep(time.Second)
+		// Set up prereq-objects
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-clusteroperation",
+			},
+		}
+		Expect(func() error {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ns), &corev1.Namespace{})
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return k8sClient.Create(context.Background(), ns)
+				}
+				return err
+			}
+			return nil
+		}()).To(Succeed())
+		cluster = &opsterv1.OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-clusteroperation",
+			},
+			Spec: opsterv1.ClusterSpec{
+				General: opsterv1.GeneralConfig{
+					ServiceName: "test-cluster",
+				},
+				NodePools: []opsterv1.NodePool{
+					{
+						Component: "node",
+						Roles: []string{
+							"master",
+							"data",
+						},
+					},
+				},
+			},
+		}
+		Expect(func() error {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), &opsterv1.OpenSearchCluster{})
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return k8sClient.Create(context.Background(), cluster)
+				}
+				return err
+			}
+			return nil
+		}()).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		reconciler = NewClusterOperationReconciler(
+			context.Background(),
+			k8sClient,
+			recorder,
+			instance,
+			WithOSClientTransport(transport),
+			WithUpdateStatus(false),
+		)
+	})
+
+	When("operation has already finished", func() {
+		BeforeEach(func() {
+			instance.Status.State = opsterv1.OpensearchClusterOperationStateSucceeded
+		})
+		It("should not execute it again", func() {
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+			Expect(transport.GetTotalCallCount()).To(Equal(0))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+	When("cluster doesn't exist", func() {
+		BeforeEach(func() {
+			instance.Spec.OpensearchRef.Name = "doesnotexist"
+		})
+		It("should wait for the cluster to exist", func() {
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStatePending))
+			Expect(instance.Status.Reason).To(Equal("waiting for opensearch cluster to exist"))
+		})
+	})
+	When("cluster is not ready", func() {
+		It("should wait for the cluster to be running", func() {
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStatePending))
+			Expect(instance.Status.Reason).To(Equal("waiting for opensearch cluster status to be running"))
+			Expect(transport.GetTotalCallCount()).To(Equal(0))
+		})
+	})
+	Context("cluster is ready", func() {
+		BeforeEach(func() {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			cluster.Status.Phase = opsterv1.PhaseRunning
+			cluster.Status.ComponentsStatus = []opsterv1.ComponentStatus{}
+			Expect(k8sClient.Status().Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() string {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				if err != nil {
+					return "failed"
+				}
+				return cluster.Status.Phase
+			}).Should(Equal(opsterv1.PhaseRunning))
+
+			transport.RegisterResponder(
+				http.MethodGet,
+				fmt.Sprintf(
+					"https://%s.%s.svc.cluster.local:9200/",
+					cluster.Spec.General.ServiceName,
+					cluster.Namespace,
+				),
+				httpmock.NewStringResponder(200, "OK"),
+			)
+			transport.RegisterResponder(
+				http.MethodHead,
+				fmt.Sprintf(
+					"https://%s.%s.svc.cluster.local:9200/",
+					cluster.Spec.General.ServiceName,
+					cluster.Namespace,
+				),
+				httpmock.NewStringResponder(200, "OK"),
+			)
+		})
+
+		It("should execute the steps and record the results", func() {
+			transport.RegisterResponder(
+				http.MethodPut,
+				fmt.Sprintf(
+					"https://%s.%s.svc.cluster.local:9200/_cluster/settings",
+					cluster.Spec.General.ServiceName,
+					cluster.Namespace,
+				),
+				httpmock.NewStringResponder(200, `{"acknowledged": true}`).Once(failMessage),
+			)
+
+			// Starts the operation
+			_, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStateRunning))
+			Expect(instance.Status.Steps).To(HaveLen(1))
+			Expect(instance.Status.Steps[0].State).To(Equal(opsterv1.OpensearchClusterOperationStatePending))
+
+			// Starts and executes the step
+			for i := 0; i < 2; i++ {
+				_, err = reconciler.Reconcile()
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(instance.Status.Steps[0].State).To(Equal(opsterv1.OpensearchClusterOperationStateSucceeded))
+			Expect(instance.Status.Steps[0].StartTime).NotTo(BeNil())
+			Expect(instance.Status.Steps[0].CompletionTime).NotTo(BeNil())
+
+			// Finishes the operation
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+			Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStateSucceeded))
+			Expect(instance.Status.CompletionTime).NotTo(BeNil())
+			Expect(transport.GetCallCountInfo()[fmt.Sprintf(
+				"PUT https://%s.%s.svc.cluster.local:9200/_cluster/settings",
+				cluster.Spec.General.ServiceName,
+				cluster.Namespace,
+			)]).To(Equal(1))
+		})
+
+		When("the pod does not exist", func() {
+			BeforeEach(func() {
+				instance.Spec.Steps = []opsterv1.OperationStep{
+					{
+						Action: opsterv1.OperationRestartPod,
+						Pod:    "doesnotexist",
+					},
+				}
+			})
+			It("should fail the operation", func() {
+				for i := 0; i < 3; i++ {
+					_, err := reconciler.Reconcile()
+					Expect(err).NotTo(HaveOccurred())
+				}
+				Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStateFailed))
+				Expect(instance.Status.Reason).To(ContainSubstring("pod doesnotexist not found"))
+				Expect(instance.Status.Steps[0].State).To(Equal(opsterv1.OpensearchClusterOperationStateFailed))
+			})
+		})
+
+		When("the pod was deleted by the step", func() {
+			BeforeEach(func() {
+				now := metav1.Now()
+				instance.Spec.Steps = []opsterv1.OperationStep{
+					{
+						Action: opsterv1.OperationRestartPod,
+						Pod:    "test-cluster-node-0",
+					},
+				}
+				instance.Status = opsterv1.OpensearchClusterOperationStatus{
+					State:          opsterv1.OpensearchClusterOperationStateRunning,
+					ManagedCluster: &cluster.UID,
+					StartTime:      &now,
+					Steps: []opsterv1.OperationStepStatus{
+						{
+							Action:    opsterv1.OperationRestartPod,
+							State:     opsterv1.OpensearchClusterOperationStateRunning,
+							Message:   "restarting test-cluster-node-0",
+							StartTime: &now,
+						},
+					},
+				}
+			})
+			It("should wait for the pod to be recreated", func() {
+				result, err := reconciler.Reconcile()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+				Expect(instance.Status.State).To(Equal(opsterv1.OpensearchClusterOperationStateRunning))
+				Expect(instance.Status.Steps[0].State).To(Equal(opsterv1.OpensearchClusterOperationStateRunning))
+				Expect(instance.Status.Steps[0].Message).To(Equal("waiting for test-cluster-node-0 to be recreated"))
+			})
+		})
+	})
+
+	When("ordering the restarts of cluster manager pods", func() {
+		It("should restart the elected cluster manager last", func() {
+			pods := []string{"masters-2", "masters-1", "masters-0"}
+			Expect(electedClusterManagerLast(pods, "masters-1")).To(Equal([]string{"masters-2", "masters-0", "masters-1"}))
+			Expect(electedClusterManagerLast(pods, "other-0")).To(Equal(pods))
+			Expect(pods).To(Equal([]string{"masters-2", "masters-1", "masters-0"}))
+		})
+	})
+})
@@ -66,7 +66,9 @@ func (r *ConfigurationReconciler) Reconcile() (ctrl.Result, error) {
 
 	if len(r.reconcilerContext.OpenSearchConfig) > 0 {
 		// Add some default config for the security plugin
-		r.reconcilerContext.AddConfig("plugins.security.audit.type", "internal_opensearch")
+		if err := r.addAuditConfig(); err != nil {
+			return ctrl.Result{}, err
+		}
 		r.reconcilerContext.AddConfig("plugins.security.enable_snapshot_restore_privilege", "true")
 		r.reconcilerContext.AddConfig("plugins.security.check_snapshot_restore_write_privileges", "true")
 		r.reconcilerContext.AddConfig("plugins.security.restapi.roles_enabled", `["all_access", "security_rest_api_access"]`)
@@ -2,6 +2,7 @@ package reconcilers
 
 import (
 	"context"
+	"crypto/sha1"
 	"fmt"
 
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
@@ -11,6 +12,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/helpers"
 	"opensearch.opster.io/pkg/reconcilers/util"
 	"opensearch.opster.io/pkg/tls"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -61,6 +63,8 @@ func (r *DashboardsReconciler) Reconcile() (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
+	r.handleAuthentication()
+
 	// add any aditional dashboard config to the reconciler context
 	for key, value := range r.instance.Spec.Dashboards.AdditionalConfig {
 		r.reconcilerContext.AddDashboardsConfig(key, value)
@@ -85,6 +89,14 @@ func (r *DashboardsReconciler) Reconcile() (ctrl.Result, error) {
 	result.Combine(r.ReconcileResource(cm, reconciler.StatePresent))
 
 	deployment := builders.NewDashboardsDeploymentForCR(r.instance, volumes, volumeMounts)
+	credentialsChecksum, err := r.generatedCredentialsChecksum()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if credentialsChecksum != "" {
+		// Restart dashboards when the generated password is rotated
+		deployment.Spec.Template.Annotations = map[string]string{builders.CredentialsChecksumAnnotation: credentialsChecksum}
+	}
 	result.CombineErr(ctrl.SetControllerReference(r.instance, deployment, r.Client.Scheme()))
 	result.Combine(r.ReconcileResource(deployment, reconciler.StatePresent))
 
@@ -168,6 +180,44 @@ func (r *DashboardsReconciler) handleTls() ([]corev1.Volume, []corev1.VolumeMoun
 	return volumes, volumeMounts, nil
 }
 
+// handleAuthentication configures the login of dashboards for the OIDC or SAML authentication of the cluster
+func (r *DashboardsReconciler) handleAuthentication() {
+	if r.instance.Spec.Security == nil || r.instance.Spec.Security.Authentication == nil {
+		return
+	}
+	auth := r.instance.Spec.Security.Authentication
+	if oidc := auth.OIDC; oidc != nil {
+		r.reconcilerContext.AddDashboardsConfig("opensearch_security.auth.type", "openid")
+		r.reconcilerContext.AddDashboardsConfig("opensearch_security.openid.connect_url", oidc.ConnectURL)
+		r.reconcilerContext.AddDashboardsConfig("opensearch_security.openid.client_id", oidc.ClientID)
+		// The secret is passed as env var so it does not end up in the configmap
+		r.reconcilerContext.AddDashboardsConfig("opensearch_security.openid.client_secret", fmt.Sprintf("${%s}", builders.DashboardsOidcClientSecretEnv))
+		if oidc.Scope != "" {
+			r.reconcilerContext.AddDashboardsConfig("opensearch_security.openid.scope", oidc.Scope)
+		}
+		if oidc.BaseRedirectURL != "" {
+			r.reconcilerContext.AddDashboardsConfig("opensearch_security.openid.base_redirect_url", oidc.BaseRedirectURL)
+		}
+	} else if auth.SAML != nil {
+		r.reconcilerContext.AddDashboardsConfig("opensearch_security.auth.type", "saml")
+		r.reconcilerContext.AddDashboardsConfig("server.xsrf.allowlist", `["/_opendistro/_security/saml/acs", "/_opendistro/_security/saml/logout"]`)
+	}
+}
+
+// generatedCredentialsChecksum returns a checksum of the password generated for the kibanaserver user, empty if
+// dashboards does not use a generated password
+func (r *DashboardsReconciler) generatedCredentialsChecksum() (string, error) {
+	security := r.instance.Spec.Security
+	if security == nil || security.Config == nil || !security.Config.GeneratePasswords || r.instance.Spec.Dashboards.OpensearchCredentialsSecret.Name != "" {
+		return "", nil
+	}
+	secret := corev1.Secret{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: helpers.GeneratedCredentialsSecretName(r.instance, "kibanaserver"), Namespace: r.instance.Namespace}, &secret); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha1.Sum(secret.Data["password"])), nil
+}
+
 func (r *DashboardsReconciler) providedCaCert(secretName string, namespace string) (tls.Cert, error) {
 	var ca tls.Cert
 	caSecret := corev1.Secret{}
@@ -227,6 +227,68 @@ var _ = Describe("Dashboards Reconciler", func() {
 		})
 	})
 
+	When("running the dashboards reconciler with OIDC authentication", func() {
+		It("should configure the login and pass the client secret as env var", func() {
+			clusterName := "dashboards-oidc"
+			clientSecret := corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: clusterName + "-oidc"},
+				Key:                  "clientSecret",
+			}
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName, UID: "dummyuid"},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{ServiceName: clusterName},
+					Security: &opsterv1.Security{
+						Authentication: &opsterv1.AuthenticationConfig{
+							OIDC: &opsterv1.OIDCAuthConfig{
+								ConnectURL:   "https://idp.example.com/.well-known/openid-configuration",
+								ClientID:     "dashboards",
+								ClientSecret: clientSecret,
+							},
+						},
+					},
+					Dashboards: opsterv1.DashboardsConfig{
+						Enable: true,
+					},
+				}}
+			ns := corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: clusterName,
+				},
+			}
+			err := k8sClient.Create(context.Background(), &ns)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, underTest := newDashboardsReconciler(&spec)
+			_, err = underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			configMap := corev1.ConfigMap{}
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-dashboards-config", Namespace: clusterName}, &configMap)
+				return err == nil
+			}, timeout, interval).Should(BeTrue())
+
+			data := configMap.Data["opensearch_dashboards.yml"]
+			Expect(data).To(ContainSubstring("opensearch_security.auth.type: openid\n"))
+			Expect(data).To(ContainSubstring("opensearch_security.openid.client_id: dashboards\n"))
+			Expect(data).To(ContainSubstring("opensearch_security.openid.client_secret: ${OPENSEARCH_SECURITY_OPENID_CLIENT_SECRET}\n"))
+
+			Eventually(Object(&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      clusterName + "-dashboards",
+					Namespace: clusterName,
+				},
+			}, k8sClient), timeout, interval).Should(ExistAnd(
+				HaveMatchingContainer(
+					HaveEnv(
+						"OPENSEARCH_SECURITY_OPENID_CLIENT_SECRET",
+						clientSecret,
+					),
+				),
+			))
+		})
+	})
+
 	When("running the dashboards reconciler with envs supplied", func() {
 		It("should populate the dashboard env vars", func() {
 			clusterName := "dashboards-add-env"
@@ -0,0 +1,178 @@
+package reconcilers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// parseSchedule parses a cron schedule in the given time zone, UTC if empty
+func parseSchedule(schedule string, timezone string) (cron.Schedule, error) {
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	return cron.ParseStandard(fmt.Sprintf("CRON_TZ=%s %s", timezone, schedule))
+}
+
+// parseMaintenanceWindow parses the cron schedule of a maintenance window in its time zone
+func parseMaintenanceWindow(window opsterv1.MaintenanceWindow) (cron.Schedule, error) {
+	schedule, err := parseSchedule(window.Schedule, window.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window %q: %w", window.Schedule, err)
+	}
+	return schedule, nil
+}
+
+// inMaintenanceWindow checks if the given time is inside one of the maintenance windows.
+// Without any maintenance windows all times are allowed.
+func inMaintenanceWindow(windows []opsterv1.MaintenanceWindow, now time.Time) (bool, error) {
+	if len(windows) == 0 {
+		return true, nil
+	}
+	for _, window := range windows {
+		schedule, err := parseMaintenanceWindow(window)
+		if err != nil {
+			return false, err
+		}
+		// The window is open if it started within its duration before now
+		if !schedule.Next(now.Add(-window.Duration.Duration)).After(now) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// nextMaintenanceWindow returns the start of the next maintenance window after the given time
+func nextMaintenanceWindow(windows []opsterv1.MaintenanceWindow, now time.Time) (time.Time, error) {
+	var next time.Time
+	for _, window := range windows {
+		schedule, err := parseMaintenanceWindow(window)
+		if err != nil {
+			return time.Time{}, err
+		}
+		start := schedule.Next(now)
+		if next.IsZero() || start.Before(next) {
+			next = start
+		}
+	}
+	return next, nil
+}
+
+// UpdateMaintenanceStatus shows the start of the next maintenance window in the cluster status
+func UpdateMaintenanceStatus(ctx context.Context, k8sClient client.Client, instance *opsterv1.OpenSearchCluster) error {
+	var nextWindow *metav1.Time
+	// Invalid maintenance windows are reported when an action is deferred
+	if next, err := nextMaintenanceWindow(instance.Spec.MaintenanceWindows, time.Now()); err == nil && !next.IsZero() {
+		nextWindow = &metav1.Time{Time: next}
+	}
+
+	current := instance.Status.Maintenance
+	if current == nil && nextWindow == nil {
+		return nil
+	}
+	if current != nil && (current.NextWindow == nil) == (nextWindow == nil) &&
+		(nextWindow == nil || current.NextWindow.Equal(nextWindow)) {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		if instance.Status.Maintenance == nil {
+			instance.Status.Maintenance = &opsterv1.MaintenanceStatus{}
+		}
+		instance.Status.Maintenance.NextWindow = nextWindow
+		if nextWindow == nil && len(instance.Status.Maintenance.DeferredActions) == 0 {
+			instance.Status.Maintenance = nil
+		}
+		return k8sClient.Status().Update(ctx, instance)
+	})
+}
+
+// deferToMaintenanceWindow returns true if a disruptive action has to wait for the next maintenance window.
+// Deferred actions are shown in the status until they are started.
+func deferToMaintenanceWindow(ctx context.Context, k8sClient client.Client, instance *opsterv1.OpenSearchCluster, action opsterv1.DeferredAction) (bool, error) {
+	inWindow, err := inMaintenanceWindow(instance.Spec.MaintenanceWindows, time.Now())
+	if err != nil {
+		return true, err
+	}
+	matches := deferredNodePoolAction(action.Action, action.NodePool)
+
+	if inWindow {
+		return false, clearDeferredActions(ctx, k8sClient, instance, matches)
+	}
+	if instance.Status.Maintenance != nil {
+		for _, deferred := range instance.Status.Maintenance.DeferredActions {
+			if matches(deferred) {
+				return true, nil
+			}
+		}
+	}
+
+	return true, retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		if instance.Status.Maintenance == nil {
+			instance.Status.Maintenance = &opsterv1.MaintenanceStatus{}
+		}
+		action.Since = metav1.Now()
+		instance.Status.Maintenance.DeferredActions = append(instance.Status.Maintenance.DeferredActions, action)
+		return k8sClient.Status().Update(ctx, instance)
+	})
+}
+
+// clearDeferredActions removes the matching actions from the deferred actions, e.g. once they are no longer needed
+func clearDeferredActions(ctx context.Context, k8sClient client.Client, instance *opsterv1.OpenSearchCluster, matches func(opsterv1.DeferredAction) bool) error {
+	if instance.Status.Maintenance == nil {
+		return nil
+	}
+	found := false
+	for _, deferred := range instance.Status.Maintenance.DeferredActions {
+		found = found || matches(deferred)
+	}
+	if !found {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		removeDeferredActions(&instance.Status, matches)
+		return k8sClient.Status().Update(ctx, instance)
+	})
+}
+
+// removeDeferredActions removes the matching actions from the status, the caller has to update the status
+func removeDeferredActions(status *opsterv1.ClusterStatus, matches func(opsterv1.DeferredAction) bool) {
+	if status.Maintenance == nil {
+		return
+	}
+	var remaining []opsterv1.DeferredAction
+	for _, deferred := range status.Maintenance.DeferredActions {
+		if !matches(deferred) {
+			remaining = append(remaining, deferred)
+		}
+	}
+	status.Maintenance.DeferredActions = remaining
+}
+
+func deferredActionType(action string) func(opsterv1.DeferredAction) bool {
+	return func(deferred opsterv1.DeferredAction) bool {
+		return deferred.Action == action
+	}
+}
+
+func deferredNodePoolAction(action string, nodePool string) func(opsterv1.DeferredAction) bool {
+	return func(deferred opsterv1.DeferredAction) bool {
+		return deferred.Action == action && deferred.NodePool == nodePool
+	}
+}
@@ -2,12 +2,15 @@ package reconcilers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"k8s.io/client-go/tools/record"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,15 +18,18 @@ import (
 )
 
 const (
-	opensearchPending     = "OpensearchPending"
-	opensearchError       = "OpensearchError"
-	opensearchAPIError    = "OpensearchAPIError"
-	opensearchRefMismatch = "OpensearchRefMismatch"
-	opensearchAPIUpdated  = "OpensearchAPIUpdated"
-	passwordError         = "PasswordError"
-	statusError           = "StatusUpdateError"
+	opensearchPending       = "OpensearchPending"
+	opensearchError         = "OpensearchError"
+	opensearchAPIError      = "OpensearchAPIError"
+	opensearchRefMismatch   = "OpensearchRefMismatch"
+	opensearchRefNotAllowed = "OpensearchRefNotAllowed"
+	opensearchAPIUpdated    = "OpensearchAPIUpdated"
+	passwordError           = "PasswordError"
+	statusError             = "StatusUpdateError"
 )
 
+var ErrClusterPaused = errors.New("reconciliation of the opensearch cluster is paused")
+
 type ComponentReconciler func() (reconcile.Result, error)
 
 type ReconcilerOptions struct {
@@ -151,3 +157,27 @@ func UpdateOpensearchStatus(
 	}
 	return nil
 }
+
+// UpdateOpensearchCondition sets the condition on the cluster status, the status is only
+// updated if the condition changed
+func UpdateOpensearchCondition(
+	ctx context.Context,
+	k8sClient client.Client,
+	instance *opsterv1.OpenSearchCluster,
+	condition metav1.Condition,
+) error {
+	existing := meta.FindStatusCondition(instance.Status.Conditions, condition.Type)
+	if existing != nil &&
+		existing.Status == condition.Status &&
+		existing.Reason == condition.Reason &&
+		existing.Message == condition.Message {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(instance), instance); err != nil {
+			return err
+		}
+		meta.SetStatusCondition(&instance.Status.Conditions, condition)
+		return k8sClient.Status().Update(ctx, instance)
+	})
+}
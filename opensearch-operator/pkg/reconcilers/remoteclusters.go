@@ -0,0 +1,181 @@
+package reconcilers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type RemoteClustersReconciler struct {
+	client.Client
+	reconciler.ResourceReconciler
+	ctx               context.Context
+	osClient          *services.OsClusterClient
+	recorder          record.EventRecorder
+	reconcilerContext *ReconcilerContext
+	instance          *opsterv1.OpenSearchCluster
+}
+
+func NewRemoteClustersReconciler(
+	client client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	reconcilerContext *ReconcilerContext,
+	instance *opsterv1.OpenSearchCluster,
+	opts ...reconciler.ResourceReconcilerOption,
+) *RemoteClustersReconciler {
+	return &RemoteClustersReconciler{
+		Client: client,
+		ResourceReconciler: reconciler.NewReconcilerWith(client,
+			append(opts, reconciler.WithLog(log.FromContext(ctx).WithValues("reconciler", "remoteclusters")))...),
+		ctx:               ctx,
+		recorder:          recorder,
+		reconcilerContext: reconcilerContext,
+		instance:          instance,
+	}
+}
+
+// Reconcile configures the remote cluster connections of the spec, removes the connections that were
+// removed from it and reports the connection state of the remote clusters in the status
+func (r *RemoteClustersReconciler) Reconcile() (ctrl.Result, error) {
+	if len(r.instance.Spec.RemoteClusters) == 0 && len(r.instance.Status.RemoteClusters) == 0 {
+		return ctrl.Result{}, nil
+	}
+	if !r.instance.Status.Initialized {
+		return ctrl.Result{}, nil
+	}
+	lg := log.FromContext(r.ctx).WithValues("reconciler", "remoteclusters")
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+
+	var err error
+	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.instance, nil)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	statuses := make([]opsterv1.RemoteClusterStatus, 0, len(r.instance.Spec.RemoteClusters))
+	configured := make(map[string]bool, len(r.instance.Spec.RemoteClusters))
+	for _, remote := range r.instance.Spec.RemoteClusters {
+		configured[remote.Alias] = true
+		status := opsterv1.RemoteClusterStatus{Alias: remote.Alias}
+
+		seeds, message, err := r.remoteSeeds(remote)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if message != "" {
+			status.Message = message
+			statuses = append(statuses, status)
+			continue
+		}
+		status.Seeds = seeds
+
+		updated, err := services.EnsureRemoteCluster(r.ctx, r.osClient, remote.Alias, seeds)
+		if err != nil {
+			lg.Error(err, "failed to configure remote cluster", "alias", remote.Alias)
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", opensearchAPIError, "Failed to configure remote cluster %s", remote.Alias)
+			status.Message = err.Error()
+		} else if updated {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", opensearchAPIUpdated, "Remote cluster %s configured", remote.Alias)
+		}
+		statuses = append(statuses, status)
+	}
+
+	// Remove the connections that were removed from the spec unless a replication rule still uses them,
+	// those are kept in the status until they can be removed
+	for _, status := range r.instance.Status.RemoteClusters {
+		if configured[status.Alias] {
+			continue
+		}
+		rule, err := r.replicationRuleUsingAlias(status.Alias)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if rule != "" {
+			statuses = append(statuses, opsterv1.RemoteClusterStatus{
+				Alias:   status.Alias,
+				Seeds:   status.Seeds,
+				Message: fmt.Sprintf("kept for replication rule %s", rule),
+			})
+			continue
+		}
+		if err := services.RemoveRemoteCluster(r.ctx, r.osClient, status.Alias); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", opensearchAPIUpdated, "Remote cluster %s removed", status.Alias)
+	}
+
+	info, err := r.osClient.GetRemoteInfo()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range statuses {
+		if remote, ok := info[statuses[i].Alias]; ok {
+			statuses[i].Connected = remote.Connected
+			statuses[i].NumNodesConnected = remote.NumNodesConnected
+		}
+	}
+	if len(statuses) == 0 {
+		statuses = nil
+	}
+	if reflect.DeepEqual(statuses, r.instance.Status.RemoteClusters) {
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{}, retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		r.instance.Status.RemoteClusters = statuses
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+// remoteSeeds returns the seeds of a remote cluster. If the seeds can't be determined yet, e.g. because the
+// referenced cluster does not exist, a message explaining why is returned instead.
+func (r *RemoteClustersReconciler) remoteSeeds(remote opsterv1.RemoteCluster) ([]string, string, error) {
+	if remote.OpensearchRef == nil {
+		return remote.Seeds, "", nil
+	}
+	name := remote.OpensearchRef.NamespacedName(r.instance.Namespace)
+	remoteCluster, err := util.FetchOpensearchCluster(r.ctx, r.Client, name)
+	if err != nil {
+		return nil, "", err
+	}
+	if remoteCluster == nil {
+		return nil, fmt.Sprintf("waiting for opensearch cluster %s to exist", name), nil
+	}
+	allowed, err := util.ReferenceAllowed(r.ctx, r.Client, remoteCluster, r.instance.Namespace)
+	if err != nil {
+		return nil, "", err
+	}
+	if !allowed {
+		return nil, fmt.Sprintf("opensearch cluster %s does not allow references from namespace %s", name, r.instance.Namespace), nil
+	}
+	return []string{builders.TransportSeedForCluster(remoteCluster, r.instance.Namespace)}, "", nil
+}
+
+// replicationRuleUsingAlias returns the name of a replication rule of the cluster that uses the remote cluster
+// connection, an empty string if no rule uses it
+func (r *RemoteClustersReconciler) replicationRuleUsingAlias(alias string) (string, error) {
+	rules := &opsterv1.OpensearchReplicationRuleList{}
+	if err := r.List(r.ctx, rules, client.InNamespace(r.instance.Namespace)); err != nil {
+		return "", err
+	}
+	for _, rule := range rules.Items {
+		if rule.Spec.OpensearchRef.Name == r.instance.Name && rule.Spec.Leader.Alias == alias {
+			return rule.Name, nil
+		}
+	}
+	return "", nil
+}
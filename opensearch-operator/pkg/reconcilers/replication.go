@@ -0,0 +1,543 @@
+package reconcilers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/requests"
+	"opensearch.opster.io/opensearch-gateway/responses"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/helpers"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	replicationUpdated = "ReplicationUpdated"
+	replicationFailed  = "ReplicationFailed"
+)
+
+type ReplicationReconciler struct {
+	client.Client
+	ReconcilerOptions
+	ctx      context.Context
+	osClient *services.OsClusterClient
+	recorder record.EventRecorder
+	instance *opsterv1.OpensearchReplicationRule
+	cluster  *opsterv1.OpenSearchCluster
+	logger   logr.Logger
+}
+
+func NewReplicationReconciler(
+	ctx context.Context,
+	client client.Client,
+	recorder record.EventRecorder,
+	instance *opsterv1.OpensearchReplicationRule,
+	opts ...ReconcilerOption,
+) *ReplicationReconciler {
+	options := ReconcilerOptions{}
+	options.apply(opts...)
+	return &ReplicationReconciler{
+		Client:            client,
+		ReconcilerOptions: options,
+		ctx:               ctx,
+		recorder:          recorder,
+		instance:          instance,
+		logger:            log.FromContext(ctx).WithValues("reconciler", "replication"),
+	}
+}
+
+// Reconcile configures the remote cluster connection in the follower cluster and brings the replication
+// of the indices and the auto-follow rules to the requested state. While the replication is not stopped
+// the rule is requeued regularly to keep the lag in the status up to date.
+func (r *ReplicationReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
+	var reason string
+	var pending bool
+	var autoFollowRules []string
+	var indices []opsterv1.ReplicatedIndexStatus
+	synced := false
+
+	defer func() {
+		// Skip status updates when option is set
+		if !pointer.BoolDeref(r.updateStatus, true) {
+			return
+		}
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+				return err
+			}
+			r.instance.Status.Reason = reason
+			switch {
+			case retErr != nil:
+				r.instance.Status.State = opsterv1.OpensearchReplicationRuleStateError
+			case pending:
+				r.instance.Status.State = opsterv1.OpensearchReplicationRuleStatePending
+			default:
+				r.instance.Status.State = replicationRuleState(r.desiredState())
+			}
+			// Only replace what is known about the replication once it has been synced
+			if synced {
+				r.instance.Status.AutoFollowRules = autoFollowRules
+				r.instance.Status.Indices = indices
+			}
+			return r.Status().Update(r.ctx, r.instance)
+		})
+
+		if err != nil {
+			r.logger.Error(err, "failed to update status")
+		}
+	}()
+
+	r.cluster, retErr = util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
+		Name:      r.instance.Spec.OpensearchRef.Name,
+		Namespace: r.instance.Namespace,
+	})
+	if retErr != nil {
+		reason = "error fetching opensearch cluster"
+		r.logger.Error(retErr, "failed to fetch opensearch cluster")
+		r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+		return
+	}
+	if r.cluster == nil {
+		r.logger.Info("opensearch cluster does not exist, requeueing")
+		reason = "waiting for opensearch cluster to exist"
+		pending = true
+		r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
+		retResult = ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 10 * time.Second,
+		}
+		return
+	}
+
+	// Don't touch the cluster while reconciliation is paused
+	if r.cluster.ReconcilePaused(opsterv1.PauseReplication) {
+		r.logger.Info("opensearch cluster is paused, requeueing")
+		reason = ErrClusterPaused.Error()
+		pending = true
+		retResult = ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 30 * time.Second,
+		}
+		return
+	}
+
+	// Check cluster ref has not changed
+	if r.instance.Status.ManagedCluster != nil {
+		if *r.instance.Status.ManagedCluster != r.cluster.UID {
+			reason = "cannot change the cluster a replication rule refers to"
+			retErr = fmt.Errorf("%s", reason)
+			r.recorder.Event(r.instance, "Warning", opensearchRefMismatch, reason)
+			return
+		}
+	} else {
+		if pointer.BoolDeref(r.updateStatus, true) {
+			retErr = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+				if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+					return err
+				}
+				r.instance.Status.ManagedCluster = &r.cluster.UID
+				return r.Status().Update(r.ctx, r.instance)
+			})
+			if retErr != nil {
+				reason = fmt.Sprintf("failed to update status: %s", retErr)
+				r.recorder.Event(r.instance, "Warning", statusError, reason)
+				return
+			}
+		}
+	}
+
+	// Check cluster is ready
+	if r.cluster.Status.Phase != opsterv1.PhaseRunning {
+		r.logger.Info("opensearch cluster is not running, requeueing")
+		reason = "waiting for opensearch cluster status to be running"
+		pending = true
+		r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
+		retResult = ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 10 * time.Second,
+		}
+		return
+	}
+
+	seeds, err := r.leaderSeeds()
+	if err != nil {
+		reason = err.Error()
+		if errors.Is(err, errLeaderNotReady) {
+			pending = true
+			r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
+			retResult = ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}
+			return
+		}
+		retErr = err
+		r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+		return
+	}
+
+	r.osClient, retErr = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	if retErr != nil {
+		reason = "error creating opensearch client"
+		r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+		return
+	}
+
+	updated, retErr := services.EnsureRemoteCluster(r.ctx, r.osClient, r.instance.Spec.Leader.Alias, seeds)
+	if retErr != nil {
+		reason = "failed to configure the remote cluster connection"
+		r.logger.Error(retErr, reason)
+		r.recorder.Event(r.instance, "Warning", opensearchAPIError, reason)
+		return
+	}
+	if updated {
+		r.recorder.Event(r.instance, "Normal", opensearchAPIUpdated, "remote cluster connection configured")
+	}
+
+	if r.desiredState() == opsterv1.ReplicationRunning {
+		autoFollowRules, retErr = r.reconcileAutoFollowRules()
+	} else {
+		autoFollowRules, retErr = r.deleteAutoFollowRules(r.instance.Status.AutoFollowRules)
+	}
+	if retErr != nil {
+		reason = "failed to update the auto-follow rules"
+		r.logger.Error(retErr, reason)
+		r.recorder.Event(r.instance, "Warning", opensearchAPIError, reason)
+		return
+	}
+
+	indices, retErr = r.reconcileIndices()
+	if retErr != nil {
+		reason = retErr.Error()
+		r.logger.Error(retErr, "failed to update the replication of the indices")
+		r.recorder.Event(r.instance, "Warning", replicationFailed, reason)
+		return
+	}
+	synced = true
+
+	if r.desiredState() == opsterv1.ReplicationStopped {
+		return
+	}
+	retResult = ctrl.Result{
+		Requeue:      true,
+		RequeueAfter: 30 * time.Second,
+	}
+	return
+}
+
+// Delete stops the replication of all indices and removes the auto-follow rules. The follower indices
+// are kept as regular indices. The remote cluster connection is removed if no other rule uses it.
+func (r *ReplicationReconciler) Delete() error {
+	var err error
+	r.cluster, err = util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
+		Name:      r.instance.Spec.OpensearchRef.Name,
+		Namespace: r.instance.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.cluster == nil || !r.cluster.DeletionTimestamp.IsZero() {
+		// If the opensearch cluster doesn't exist, we don't need to delete anything
+		return nil
+	}
+	if r.cluster.ReconcilePaused(opsterv1.PauseReplication) {
+		return ErrClusterPaused
+	}
+
+	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.deleteAutoFollowRules(r.instance.Status.AutoFollowRules); err != nil {
+		return err
+	}
+
+	indices, err := r.replicatedIndices()
+	if err != nil {
+		return err
+	}
+	for _, index := range indices {
+		status, err := services.GetReplicationStatus(r.ctx, r.osClient, index)
+		if err != nil {
+			return err
+		}
+		if status.Status == responses.ReplicationStatusNotRunning {
+			continue
+		}
+		if err := services.StopReplication(r.ctx, r.osClient, index); err != nil {
+			return err
+		}
+	}
+
+	inUse, err := r.aliasUsedByOtherRules()
+	if err != nil || inUse {
+		return err
+	}
+	return services.RemoveRemoteCluster(r.ctx, r.osClient, r.instance.Spec.Leader.Alias)
+}
+
+var errLeaderNotReady = errors.New("waiting for leader cluster to exist")
+
+// leaderSeeds returns the seeds of the remote cluster connection to the leader
+func (r *ReplicationReconciler) leaderSeeds() ([]string, error) {
+	leader := r.instance.Spec.Leader
+	if leader.OpensearchRef == nil {
+		if len(leader.Seeds) == 0 {
+			return nil, errors.New("either leader.opensearchCluster or leader.seeds must be set")
+		}
+		return leader.Seeds, nil
+	}
+
+	leaderCluster, err := util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
+		Name:      leader.OpensearchRef.Name,
+		Namespace: r.instance.Namespace,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if leaderCluster == nil {
+		return nil, errLeaderNotReady
+	}
+	return []string{builders.TransportSeedForCluster(leaderCluster, r.instance.Namespace)}, nil
+}
+
+// reconcileAutoFollowRules creates the auto-follow rules of the spec and deletes the rules that were
+// removed from it. It returns the names of the rules that exist in the follower cluster.
+func (r *ReplicationReconciler) reconcileAutoFollowRules() ([]string, error) {
+	if len(r.instance.Spec.AutoFollow) == 0 {
+		return r.deleteAutoFollowRules(r.instance.Status.AutoFollowRules)
+	}
+	existing, err := services.GetAutoFollowRules(r.ctx, r.osClient)
+	if err != nil {
+		return nil, err
+	}
+	existingPatterns := make(map[string]string, len(existing))
+	for _, rule := range existing {
+		existingPatterns[rule.Name] = rule.Pattern
+	}
+
+	var created []string
+	for _, rule := range r.instance.Spec.AutoFollow {
+		pattern, ok := existingPatterns[rule.Name]
+		if ok && pattern == rule.Pattern {
+			created = append(created, rule.Name)
+			continue
+		}
+		// Auto-follow rules can't be updated, a changed rule is recreated
+		if ok {
+			if err := services.DeleteAutoFollowRule(r.ctx, r.osClient, r.instance.Spec.Leader.Alias, rule.Name); err != nil {
+				return nil, err
+			}
+		}
+		err := services.CreateAutoFollowRule(r.ctx, r.osClient, requests.AutoFollowRule{
+			LeaderAlias: r.instance.Spec.Leader.Alias,
+			Name:        rule.Name,
+			Pattern:     rule.Pattern,
+			UseRoles:    r.useRoles(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.recorder.Event(r.instance, "Normal", replicationUpdated, fmt.Sprintf("auto-follow rule %s created", rule.Name))
+		created = append(created, rule.Name)
+	}
+
+	var removed []string
+	for _, name := range r.instance.Status.AutoFollowRules {
+		if !helpers.ContainsString(created, name) {
+			removed = append(removed, name)
+		}
+	}
+	if _, err := r.deleteAutoFollowRules(removed); err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// deleteAutoFollowRules deletes the given auto-follow rules if they exist and returns the rules that are left
+func (r *ReplicationReconciler) deleteAutoFollowRules(names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	existing, err := services.GetAutoFollowRules(r.ctx, r.osClient)
+	if err != nil {
+		return names, err
+	}
+	for _, rule := range existing {
+		if !helpers.ContainsString(names, rule.Name) {
+			continue
+		}
+		if err := services.DeleteAutoFollowRule(r.ctx, r.osClient, r.instance.Spec.Leader.Alias, rule.Name); err != nil {
+			return names, err
+		}
+		r.recorder.Event(r.instance, "Normal", replicationUpdated, fmt.Sprintf("auto-follow rule %s deleted", rule.Name))
+	}
+	return nil, nil
+}
+
+// reconcileIndices brings the replication of every replicated index to the requested state
+func (r *ReplicationReconciler) reconcileIndices() ([]opsterv1.ReplicatedIndexStatus, error) {
+	indices, err := r.replicatedIndices()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]opsterv1.ReplicatedIndexStatus, 0, len(indices))
+	for _, index := range indices {
+		status, err := services.GetReplicationStatus(r.ctx, r.osClient, index)
+		if err != nil {
+			return nil, err
+		}
+
+		var action string
+		switch r.desiredState() {
+		case opsterv1.ReplicationRunning:
+			switch status.Status {
+			case responses.ReplicationStatusNotRunning:
+				// Indices created by auto-follow rules are started by opensearch
+				if !helpers.ContainsString(r.instance.Spec.Indices, index) {
+					break
+				}
+				err = services.StartReplication(r.ctx, r.osClient, index, requests.StartReplication{
+					LeaderAlias: r.instance.Spec.Leader.Alias,
+					LeaderIndex: index,
+					UseRoles:    r.useRoles(),
+				})
+				status.Status = responses.ReplicationStatusBootstrapping
+				action = "started"
+			case responses.ReplicationStatusPaused:
+				err = services.ResumeReplication(r.ctx, r.osClient, index)
+				status.Status = responses.ReplicationStatusSyncing
+				action = "resumed"
+			}
+		case opsterv1.ReplicationPaused:
+			switch status.Status {
+			case responses.ReplicationStatusBootstrapping, responses.ReplicationStatusSyncing:
+				err = services.PauseReplication(r.ctx, r.osClient, index)
+				status.Status = responses.ReplicationStatusPaused
+				action = "paused"
+			}
+		case opsterv1.ReplicationStopped:
+			if status.Status != responses.ReplicationStatusNotRunning {
+				err = services.StopReplication(r.ctx, r.osClient, index)
+				status.Status = responses.ReplicationStatusNotRunning
+				action = "stopped"
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to update the replication of index %s: %w", index, err)
+		}
+		if action != "" {
+			r.recorder.Event(r.instance, "Normal", replicationUpdated, fmt.Sprintf("replication of index %s %s", index, action))
+		}
+
+		indexStatus := opsterv1.ReplicatedIndexStatus{
+			Index:  index,
+			Status: status.Status,
+		}
+		if status.SyncingDetails != nil {
+			indexStatus.LeaderCheckpoint = status.SyncingDetails.LeaderCheckpoint
+			indexStatus.FollowerCheckpoint = status.SyncingDetails.FollowerCheckpoint
+			if lag := indexStatus.LeaderCheckpoint - indexStatus.FollowerCheckpoint; lag > 0 {
+				indexStatus.Lag = lag
+			}
+		}
+		statuses = append(statuses, indexStatus)
+	}
+	return statuses, nil
+}
+
+// replicatedIndices returns the indices of the spec, the follower indices created by the auto-follow rules
+// and the indices still replicating from an earlier reconcile, e.g. those of a removed auto-follow rule
+func (r *ReplicationReconciler) replicatedIndices() ([]string, error) {
+	var patterns []string
+	for _, rule := range r.instance.Spec.AutoFollow {
+		patterns = append(patterns, rule.Pattern)
+	}
+	followed, err := services.GetFollowerIndicesMatching(r.ctx, r.osClient, patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	indices := append([]string{}, r.instance.Spec.Indices...)
+	for _, index := range followed {
+		if !helpers.ContainsString(indices, index) {
+			indices = append(indices, index)
+		}
+	}
+	for _, status := range r.instance.Status.Indices {
+		if status.Status == responses.ReplicationStatusNotRunning {
+			continue
+		}
+		if !helpers.ContainsString(indices, status.Index) {
+			indices = append(indices, status.Index)
+		}
+	}
+	return indices, nil
+}
+
+// aliasUsedByOtherRules returns true if another rule of the same follower cluster or the remote clusters of the
+// follower cluster use the same remote cluster connection
+func (r *ReplicationReconciler) aliasUsedByOtherRules() (bool, error) {
+	for _, remote := range r.cluster.Spec.RemoteClusters {
+		if remote.Alias == r.instance.Spec.Leader.Alias {
+			return true, nil
+		}
+	}
+	rules := &opsterv1.OpensearchReplicationRuleList{}
+	if err := r.List(r.ctx, rules, client.InNamespace(r.instance.Namespace)); err != nil {
+		return false, err
+	}
+	for _, rule := range rules.Items {
+		if rule.UID == r.instance.UID || !rule.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if rule.Spec.OpensearchRef.Name == r.instance.Spec.OpensearchRef.Name && rule.Spec.Leader.Alias == r.instance.Spec.Leader.Alias {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *ReplicationReconciler) useRoles() *requests.ReplicationRoles {
+	if r.instance.Spec.UseRoles == nil {
+		return nil
+	}
+	return &requests.ReplicationRoles{
+		LeaderClusterRole:   r.instance.Spec.UseRoles.LeaderClusterRole,
+		FollowerClusterRole: r.instance.Spec.UseRoles.FollowerClusterRole,
+	}
+}
+
+// desiredState returns the requested state of the replication, which is running unless set otherwise
+func (r *ReplicationReconciler) desiredState() opsterv1.ReplicationState {
+	if r.instance.Spec.State == "" {
+		return opsterv1.ReplicationRunning
+	}
+	return r.instance.Spec.State
+}
+
+func replicationRuleState(state opsterv1.ReplicationState) opsterv1.OpensearchReplicationRuleState {
+	switch state {
+	case opsterv1.ReplicationPaused:
+		return opsterv1.OpensearchReplicationRuleStatePaused
+	case opsterv1.ReplicationStopped:
+		return opsterv1.OpensearchReplicationRuleStateStopped
+	default:
+		return opsterv1.OpensearchReplicationRuleStateRunning
+	}
+}
@@ -0,0 +1,327 @@
+package reconcilers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/responses"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("replication reconciler", func() {
+	var (
+		transport  *httpmock.MockTransport
+		reconciler *ReplicationReconciler
+		instance   *opsterv1.OpensearchReplicationRule
+		recorder   *record.FakeRecorder
+
+		// Objects
+		ns      *corev1.Namespace
+		cluster *opsterv1.OpenSearchCluster
+	)
+
+	clusterURL := func(path string) string {
+		return fmt.Sprintf(
+			"https://%s.%s.svc.cluster.local:9200/%s",
+			cluster.Spec.General.ServiceName,
+			cluster.Namespace,
+			path,
+		)
+	}
+
+	BeforeEach(func() {
+		transport = httpmock.NewMockTransport()
+		transport.RegisterNoResponder(httpmock.NewNotFoundResponder(failMessage))
+		instance = &opsterv1.OpensearchReplicationRule{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-replication",
+				Namespace: "test-replication",
+				UID:       types.UID("testuid"),
+			},
+			Spec: opsterv1.OpensearchReplicationRuleSpec{
+				OpensearchRef: corev1.LocalObjectReference{
+					Name: "test-cluster",
+				},
+				Leader: opsterv1.ReplicationLeader{
+					Alias: "leader",
+					Seeds: []string{"leader.example.com:9300"},
+				},
+				Indices: []string{"movies"},
+				State:   opsterv1.ReplicationRunning,
+			},
+		}
+
+		// Sleep for cache to start
+		time.Sleep(time.Second)
+		// Set up prereq-objects
+		ns = &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-replication",
+			},
+		}
+		Expect(func() error {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(ns), &corev1.Namespace{})
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return k8sClient.Create(context.Background(), ns)
+				}
+				return err
+			}
+			return nil
+		}()).To(Succeed())
+		cluster = &opsterv1.OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-replication",
+			},
+			Spec: opsterv1.ClusterSpec{
+				General: opsterv1.GeneralConfig{
+					ServiceName: "test-cluster",
+				},
+				NodePools: []opsterv1.NodePool{
+					{
+						Component: "node",
+						Roles: []string{
+							"master",
+							"data",
+						},
+					},
+				},
+			},
+		}
+		Expect(func() error {
+			err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), &opsterv1.OpenSearchCluster{})
+			if err != nil {
+				if k8serrors.IsNotFound(err) {
+					return k8sClient.Create(context.Background(), cluster)
+				}
+				return err
+			}
+			return nil
+		}()).To(Succeed())
+	})
+
+	JustBeforeEach(func() {
+		reconciler = NewReplicationReconciler(
+			context.Background(),
+			k8sClient,
+			recorder,
+			instance,
+			WithOSClientTransport(transport),
+			WithUpdateStatus(false),
+		)
+	})
+
+	When("cluster doesn't exist", func() {
+		BeforeEach(func() {
+			instance.Spec.OpensearchRef.Name = "doesnotexist"
+			recorder = record.NewFakeRecorder(1)
+		})
+		It("should wait for the cluster to exist", func() {
+			go func() {
+				defer GinkgoRecover()
+				defer close(recorder.Events)
+				result, err := reconciler.Reconcile()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeTrue())
+			}()
+			var events []string
+			for msg := range recorder.Events {
+				events = append(events, msg)
+			}
+			Expect(len(events)).To(Equal(1))
+			Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s waiting for opensearch cluster to exist", opensearchPending)))
+		})
+	})
+	When("cluster is paused", func() {
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(1)
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			cluster.Annotations = map[string]string{
+				opsterv1.PauseReconcileAnnotation: opsterv1.PauseReplication,
+			}
+			Expect(k8sClient.Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				return err == nil && cluster.ReconcilePaused(opsterv1.PauseReplication)
+			}).Should(BeTrue())
+		})
+		AfterEach(func() {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			delete(cluster.Annotations, opsterv1.PauseReconcileAnnotation)
+			Expect(k8sClient.Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				return err == nil && !cluster.ReconcilePaused(opsterv1.PauseReplication)
+			}).Should(BeTrue())
+		})
+		It("should not touch the cluster", func() {
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(transport.GetTotalCallCount()).To(Equal(0))
+		})
+	})
+	Context("cluster is ready", func() {
+		extraContextCalls := 1
+		BeforeEach(func() {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			cluster.Status.Phase = opsterv1.PhaseRunning
+			cluster.Status.ComponentsStatus = []opsterv1.ComponentStatus{}
+			Expect(k8sClient.Status().Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() string {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				if err != nil {
+					return "failed"
+				}
+				return cluster.Status.Phase
+			}).Should(Equal(opsterv1.PhaseRunning))
+
+			transport.RegisterResponder(
+				http.MethodGet,
+				clusterURL(""),
+				httpmock.NewStringResponder(200, "OK").Times(2, failMessage),
+			)
+			transport.RegisterResponder(
+				http.MethodHead,
+				clusterURL(""),
+				httpmock.NewStringResponder(200, "OK").Once(failMessage),
+			)
+		})
+
+		When("the leader is not set", func() {
+			BeforeEach(func() {
+				instance.Spec.Leader.Seeds = nil
+				recorder = record.NewFakeRecorder(1)
+			})
+			It("should error", func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(recorder.Events)
+					_, err := reconciler.Reconcile()
+					Expect(err).To(HaveOccurred())
+				}()
+				var events []string
+				for msg := range recorder.Events {
+					events = append(events, msg)
+				}
+				Expect(len(events)).To(Equal(1))
+				Expect(events[0]).To(Equal(fmt.Sprintf("Warning %s either leader.opensearchCluster or leader.seeds must be set", opensearchError)))
+			})
+		})
+		When("the replication is not started yet", func() {
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(2)
+				transport.RegisterResponder(
+					http.MethodGet,
+					clusterURL("_remote/info"),
+					httpmock.NewStringResponder(200, "{}").Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodPut,
+					clusterURL("_cluster/settings"),
+					httpmock.NewStringResponder(200, "{}").Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodGet,
+					clusterURL("_plugins/_replication/movies/_status"),
+					httpmock.NewJsonResponderOrPanic(200, responses.ReplicationStatusResponse{
+						Status: responses.ReplicationStatusNotRunning,
+					}).Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodPut,
+					clusterURL("_plugins/_replication/movies/_start"),
+					httpmock.NewStringResponder(200, `{"acknowledged":true}`).Once(failMessage),
+				)
+			})
+			It("should configure the remote cluster and start the replication", func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(recorder.Events)
+					result, err := reconciler.Reconcile()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+					// Confirm all responders have been called
+					Expect(transport.GetTotalCallCount()).To(Equal(transport.NumResponders() + extraContextCalls))
+				}()
+				var events []string
+				for msg := range recorder.Events {
+					events = append(events, msg)
+				}
+				Expect(len(events)).To(Equal(2))
+				Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s remote cluster connection configured", opensearchAPIUpdated)))
+				Expect(events[1]).To(Equal(fmt.Sprintf("Normal %s replication of index movies started", replicationUpdated)))
+			})
+		})
+		When("the replication is paused", func() {
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(1)
+				instance.Spec.State = opsterv1.ReplicationPaused
+				transport.RegisterResponder(
+					http.MethodGet,
+					clusterURL("_remote/info"),
+					httpmock.NewJsonResponderOrPanic(200, responses.RemoteInfoResponse{
+						"leader": responses.RemoteClusterInfo{
+							Connected: true,
+							Seeds:     []string{"leader.example.com:9300"},
+						},
+					}).Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodGet,
+					clusterURL("_plugins/_replication/movies/_status"),
+					httpmock.NewJsonResponderOrPanic(200, responses.ReplicationStatusResponse{
+						Status: responses.ReplicationStatusSyncing,
+						SyncingDetails: &responses.ReplicationSyncingDetails{
+							LeaderCheckpoint:   10,
+							FollowerCheckpoint: 7,
+						},
+					}).Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodPost,
+					clusterURL("_plugins/_replication/movies/_pause"),
+					httpmock.NewStringResponder(200, `{"acknowledged":true}`).Once(failMessage),
+				)
+			})
+			It("should pause the replication", func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(recorder.Events)
+					_, err := reconciler.Reconcile()
+					Expect(err).ToNot(HaveOccurred())
+					// Confirm all responders have been called
+					Expect(transport.GetTotalCallCount()).To(Equal(transport.NumResponders() + extraContextCalls))
+				}()
+				var events []string
+				for msg := range recorder.Events {
+					events = append(events, msg)
+				}
+				Expect(len(events)).To(Equal(1))
+				Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s replication of index movies paused", replicationUpdated)))
+			})
+		})
+	})
+	Context("deletions", func() {
+		When("the opensearch cluster does not exist", func() {
+			BeforeEach(func() {
+				instance.Spec.OpensearchRef.Name = "doesnotexist"
+			})
+			It("should do nothing", func() {
+				Expect(reconciler.Delete()).To(Succeed())
+			})
+		})
+	})
+})
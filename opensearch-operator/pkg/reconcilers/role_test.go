@@ -43,7 +43,7 @@ var _ = Describe("roles reconciler", func() {
 				UID:       types.UID("testuid"),
 			},
 			Spec: opsterv1.OpensearchRoleSpec{
-				OpensearchRef: corev1.LocalObjectReference{
+				OpensearchRef: &opsterv1.ClusterReference{
 					Name: "test-cluster",
 				},
 				ClusterPermissions: []string{
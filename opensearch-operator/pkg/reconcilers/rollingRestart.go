@@ -54,40 +54,54 @@ func (r *RollingRestartReconciler) Reconcile() (ctrl.Result, error) {
 	lg := log.FromContext(r.ctx).WithValues("reconciler", "restart")
 	// We should never get to this while an upgrade is in progress
 	// but put a defensive check in
-	if r.instance.Status.Version != "" && r.instance.Status.Version != r.instance.Spec.General.Version {
+	if (r.instance.Status.Version != "" && r.instance.Status.Version != r.instance.Spec.General.Version) || upgradeStarted(r.instance) {
 		lg.V(1).Info("Upgrade in progress, skipping rolling restart")
 		return ctrl.Result{}, nil
 	}
 
 	var pendingUpdate bool
-	// Check that all data nodes are ready before doing work
+	var nodeNames []string
+	var dataSts, otherSts, clusterManagerSts []*appsv1.StatefulSet
+	dataPools := map[string]bool{}
+	// Check that all nodes are ready before doing work
 	// Also check if there are pending updates
 	for _, nodePool := range r.instance.Spec.NodePools {
-		if helpers.ContainsString(nodePool.Roles, "data") {
-			sts := &appsv1.StatefulSet{}
-			if err := r.Get(r.ctx, types.NamespacedName{
-				Name:      builders.StsName(r.instance, &nodePool),
-				Namespace: r.instance.Namespace,
-			}, sts); err != nil {
-				return ctrl.Result{}, err
-			}
-			if sts.Status.ReadyReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
-				return ctrl.Result{
-					Requeue:      true,
-					RequeueAfter: 10 * time.Second,
-				}, nil
-			}
-
-			if sts.Status.UpdateRevision != "" &&
-				sts.Status.UpdatedReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
-				pendingUpdate = true
-			}
+		sts := &appsv1.StatefulSet{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      builders.StsName(r.instance, &nodePool),
+			Namespace: r.instance.Namespace,
+		}, sts); err != nil {
+			return ctrl.Result{}, err
+		}
+		if sts.Status.ReadyReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}, nil
+		}
+
+		if sts.Status.UpdateRevision != "" &&
+			sts.Status.UpdatedReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
+			pendingUpdate = true
+		}
+
+		for i := int32(0); i < pointer.Int32Deref(sts.Spec.Replicas, 1); i++ {
+			nodeNames = append(nodeNames, builders.ReplicaHostName(*sts, i))
+		}
+		dataPools[sts.Name] = helpers.ContainsString(nodePool.Roles, "data")
+		switch {
+		case builders.IsClusterManagerSts(*sts):
+			clusterManagerSts = append(clusterManagerSts, sts)
+		case dataPools[sts.Name]:
+			dataSts = append(dataSts, sts)
+		default:
+			otherSts = append(otherSts, sts)
 		}
 	}
 
 	if !pendingUpdate {
 		lg.V(1).Info("No pods pending restart")
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, clearDeferredActions(r.ctx, r.Client, r.instance, deferredActionType(opsterv1.DeferredRestart))
 	}
 	r.recorder.AnnotatedEventf(r.instance, map[string]string{"cluster-name": r.instance.GetName()}, "Normal", "RollingRestart", "Starting to rolling restart")
 
@@ -99,47 +113,93 @@ func (r *RollingRestartReconciler) Reconcile() (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
-	// Restart statefulset pod.  Order is not important so we just pick the first we find
-	for _, nodePool := range r.instance.Spec.NodePools {
-		if helpers.ContainsString(nodePool.Roles, "data") {
-			sts := &appsv1.StatefulSet{}
-			if err := r.Get(r.ctx, types.NamespacedName{
-				Name:      builders.StsName(r.instance, &nodePool),
-				Namespace: r.instance.Namespace,
-			}, sts); err != nil {
-				return ctrl.Result{}, err
-			}
-			if sts.Status.UpdateRevision != "" &&
-				sts.Status.UpdatedReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
-				return r.restartStatefulSetPod(sts)
-			}
+	// Wait for the previously restarted node to rejoin the cluster
+	joined, err := services.NodesJoined(r.osClient, nodeNames)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !joined {
+		lg.Info("Waiting for all nodes to join the cluster")
+		return ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 10 * time.Second,
+		}, nil
+	}
+
+	// Restart data nodes first, then coordinating and ingest nodes
+	for _, sts := range append(dataSts, otherSts...) {
+		pendingPods, err := builders.PodsPendingUpdate(r.ctx, r.Client, sts)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(pendingPods) > 0 {
+			return r.restartStatefulSetPod(sts, pendingPods[0], dataPools[sts.Name])
 		}
 	}
 
+	// Cluster manager nodes are restarted last and the elected cluster manager last of all
+	electedClusterManager, err := services.GetElectedClusterManager(r.osClient)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var clusterManagerPods []string
+	podSts := map[string]*appsv1.StatefulSet{}
+	for _, sts := range clusterManagerSts {
+		pendingPods, err := builders.PodsPendingUpdate(r.ctx, r.Client, sts)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		for _, pod := range pendingPods {
+			clusterManagerPods = append(clusterManagerPods, pod)
+			podSts[pod] = sts
+		}
+	}
+	if pods := electedClusterManagerLast(clusterManagerPods, electedClusterManager); len(pods) > 0 {
+		sts := podSts[pods[0]]
+		return r.restartStatefulSetPod(sts, pods[0], dataPools[sts.Name])
+	}
+
 	return ctrl.Result{}, nil
 }
 
-func (r *RollingRestartReconciler) restartStatefulSetPod(sts *appsv1.StatefulSet) (ctrl.Result, error) {
+// electedClusterManagerLast returns the cluster manager pods in the order they are restarted: the other pods in their
+// current order and the elected cluster manager last, so the cluster only has to elect a new one once
+func electedClusterManagerLast(pods []string, electedClusterManager string) []string {
+	ordered := make([]string, 0, len(pods))
+	elected := false
+	for _, pod := range pods {
+		if pod == electedClusterManager {
+			elected = true
+			continue
+		}
+		ordered = append(ordered, pod)
+	}
+	if elected {
+		ordered = append(ordered, electedClusterManager)
+	}
+	return ordered
+}
+
+func (r *RollingRestartReconciler) restartStatefulSetPod(sts *appsv1.StatefulSet, workingPod string, dataNode bool) (ctrl.Result, error) {
 	lg := log.FromContext(r.ctx).WithValues("reconciler", "restart")
 	dataCount := builders.DataNodesCount(r.ctx, r.Client, r.instance)
 	if dataCount == 2 && r.instance.Spec.General.DrainDataNodes {
 		lg.Info("only 2 data nodes and drain is set, some shards may not drain")
 	}
 
-	ready, err := services.CheckClusterStatusForRestart(r.osClient, r.instance.Spec.General.DrainDataNodes)
-	if err != nil {
+	// Pods are only restarted within a maintenance window
+	deferred, err := deferToMaintenanceWindow(r.ctx, r.Client, r.instance, opsterv1.DeferredAction{
+		Action:   opsterv1.DeferredRestart,
+		NodePool: sts.Labels[builders.NodePoolLabel],
+	})
+	if err != nil || deferred {
+		if deferred {
+			lg.V(1).Info("Restart deferred to the next maintenance window", "pod", workingPod)
+		}
 		return ctrl.Result{}, err
 	}
-	if !ready {
-		return ctrl.Result{
-			Requeue:      true,
-			RequeueAfter: 10 * time.Second,
-		}, nil
-	}
-
-	workingPod := builders.WorkingPodForRollingRestart(sts)
 
-	ready, err = services.PreparePodForDelete(r.osClient, workingPod, r.instance.Spec.General.DrainDataNodes, dataCount)
+	ready, err := services.CheckClusterStatusForRestart(r.osClient, r.instance.Spec.General.DrainDataNodes)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -150,6 +210,21 @@ func (r *RollingRestartReconciler) restartStatefulSetPod(sts *appsv1.StatefulSet
 		}, nil
 	}
 
+	// Shard allocation only needs to be prepared for nodes holding data
+	if dataNode {
+		ready, err = services.PreparePodForDelete(r.osClient, workingPod, r.instance.Spec.General.DrainDataNodes, dataCount)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !ready {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}, nil
+		}
+	}
+
+	lg.Info("Restarting pod", "pod", workingPod)
 	err = r.Delete(r.ctx, &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      workingPod,
@@ -161,7 +236,7 @@ func (r *RollingRestartReconciler) restartStatefulSetPod(sts *appsv1.StatefulSet
 	}
 
 	// If we are draining nodes remove the exclusion after the pod is deleted
-	if r.instance.Spec.General.DrainDataNodes {
+	if dataNode && r.instance.Spec.General.DrainDataNodes {
 		_, err = services.RemoveExcludeNodeHost(r.osClient, workingPod)
 		return ctrl.Result{}, err
 	}
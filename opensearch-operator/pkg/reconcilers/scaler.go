@@ -3,6 +3,7 @@ package reconcilers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"k8s.io/utils/pointer"
@@ -12,6 +13,7 @@ import (
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"opensearch.opster.io/pkg/helpers"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -19,6 +21,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// votingExclusionComponent is the component of the statuses that record the nodes the operator excluded from voting
+const votingExclusionComponent = "VotingExclusion"
+
+// nodePoolRemovalComponent is the component of the statuses that record the node pools whose removal was started
+const nodePoolRemovalComponent = "NodePoolRemoval"
+
 type ScalerReconciler struct {
 	client.Client
 	reconciler.ResourceReconciler
@@ -74,26 +82,47 @@ func (r *ScalerReconciler) reconcileNodePool(nodePool *opsterv1.NodePool) (bool,
 		return false, err
 	}
 
-	var desireReplicaDiff = *currentSts.Spec.Replicas - nodePool.Replicas
-	if desireReplicaDiff == 0 {
-		return false, nil
-	}
 	componentStatus := opsterv1.ComponentStatus{
 		Component:   "Scaler",
 		Description: nodePool.Component,
 	}
 	comp := r.instance.Status.ComponentsStatus
 	currentStatus, found := helpers.FindFirstPartial(comp, componentStatus, helpers.GetByDescriptionAndGroup)
+	// The voting exclusion of a removed cluster manager node must be cleared even if the desired replicas are reached
+	if found && currentStatus.Status == "VotingExclusionCleanup" {
+		return r.removeVotingExclusions(currentStatus, nodePool.Component)
+	}
+
+	var desireReplicaDiff = *currentSts.Spec.Replicas - nodePool.Replicas
+	if desireReplicaDiff <= 0 {
+		if err := clearDeferredActions(r.ctx, r.Client, r.instance, deferredNodePoolAction(opsterv1.DeferredScaleDown, nodePool.Component)); err != nil {
+			return false, err
+		}
+	}
+	if desireReplicaDiff == 0 {
+		return false, nil
+	}
 	if !found {
 		if desireReplicaDiff > 0 {
+			// Scale downs are only started within a maintenance window, started scale downs are finished
+			deferred, err := deferToMaintenanceWindow(r.ctx, r.Client, r.instance, opsterv1.DeferredAction{
+				Action:   opsterv1.DeferredScaleDown,
+				NodePool: nodePool.Component,
+			})
+			if err != nil || deferred {
+				return false, err
+			}
 			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Starting to scaling")
+			if builders.IsClusterManagerSts(currentSts) {
+				return r.excludeClusterManagerNode(currentStatus, currentSts, nodePool.Component)
+			}
 			if !r.instance.Spec.ConfMgmt.SmartScaler {
 				requeue, err := r.decreaseOneNode(currentStatus, currentSts, nodePool.Component, r.instance.Spec.ConfMgmt.SmartScaler)
 				r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Notice - your SmartScaler is not enable")
 				r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Starting to decrease node")
 				return requeue, err
 			}
-			err := r.excludeNode(currentStatus, currentSts, nodePool.Component)
+			err = r.excludeNode(currentStatus, currentSts, nodePool.Component)
 			return true, err
 
 		}
@@ -102,6 +131,13 @@ func (r *ScalerReconciler) reconcileNodePool(nodePool *opsterv1.NodePool) (bool,
 			return requeue, err
 		}
 	}
+	if currentStatus.Status == "VotingExcluded" {
+		if !r.instance.Spec.ConfMgmt.SmartScaler {
+			return r.decreaseOneNode(currentStatus, currentSts, nodePool.Component, false)
+		}
+		err := r.excludeNode(currentStatus, currentSts, nodePool.Component)
+		return true, err
+	}
 	if currentStatus.Status == "Excluded" {
 		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Start to Exclude %s/%s", r.instance.Namespace, r.instance.Name)
 		err := r.drainNode(currentStatus, currentSts, nodePool.Component)
@@ -145,7 +181,17 @@ func (r *ScalerReconciler) decreaseOneNode(currentStatus opsterv1.ComponentStatu
 		return true, err
 	}
 	lg.Info(fmt.Sprintf("Group-%s . removed node %s", nodePoolGroupName, lastReplicaNodeName))
-	r.instance.Status.ComponentsStatus = helpers.RemoveIt(currentStatus, r.instance.Status.ComponentsStatus)
+	if builders.IsClusterManagerSts(currentSts) {
+		// Keep the voting exclusion until the node has left the cluster
+		componentStatus := opsterv1.ComponentStatus{
+			Component:   "Scaler",
+			Status:      "VotingExclusionCleanup",
+			Description: nodePoolGroupName,
+		}
+		r.instance.Status.ComponentsStatus = helpers.Replace(currentStatus, componentStatus, r.instance.Status.ComponentsStatus)
+	} else {
+		r.instance.Status.ComponentsStatus = helpers.RemoveIt(currentStatus, r.instance.Status.ComponentsStatus)
+	}
 	err = r.Status().Update(r.ctx, r.instance)
 	if err != nil {
 		lg.Error(err, "failed to update status")
@@ -234,6 +280,176 @@ func (r *ScalerReconciler) excludeNode(currentStatus opsterv1.ComponentStatus, c
 	return err
 }
 
+// clusterManagerQuorumSafe checks that after removing one cluster manager eligible node
+// the remaining ready cluster manager nodes still form a quorum
+func (r *ScalerReconciler) clusterManagerQuorumSafe() (bool, error) {
+	replicas, ready, err := builders.ClusterManagerNodesCount(r.ctx, r.Client, r.instance)
+	if err != nil {
+		return false, err
+	}
+	return quorumSafe(replicas, ready), nil
+}
+
+// quorumSafe checks that the ready nodes left after removing one of the cluster manager nodes are a majority of the
+// remaining cluster manager nodes
+func quorumSafe(replicas int32, ready int32) bool {
+	remaining := replicas - 1
+	remainingReady := ready - 1
+	return remaining > 0 && remainingReady >= remaining/2+1
+}
+
+func (r *ScalerReconciler) excludeClusterManagerNode(currentStatus opsterv1.ComponentStatus, currentSts appsv1.StatefulSet, nodePoolGroupName string) (bool, error) {
+	lg := log.FromContext(r.ctx)
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	lastReplicaNodeName := builders.ReplicaHostName(currentSts, *currentSts.Spec.Replicas-1)
+
+	safe, err := r.clusterManagerQuorumSafe()
+	if err != nil {
+		return true, err
+	}
+	if !safe {
+		lg.Info(fmt.Sprintf("Group-%s . not removing cluster manager node %s, quorum would be lost", nodePoolGroupName, lastReplicaNodeName))
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Group-%s . refusing to remove cluster manager node %s, not enough ready cluster manager nodes to keep a quorum", nodePoolGroupName, lastReplicaNodeName)
+		return true, nil
+	}
+
+	username, password, err := helpers.UsernameAndPassword(r.ctx, r.Client, r.instance)
+	if err != nil {
+		return true, err
+	}
+	clusterClient, err := services.NewOsClusterClient(builders.URLForCluster(r.instance), username, password)
+	if err != nil {
+		lg.Error(err, "failed to create os client")
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Failed to create os client for scaling")
+		return true, err
+	}
+
+	excluded, err := services.AppendVotingConfigExclusion(clusterClient, lastReplicaNodeName)
+	if !excluded || err != nil {
+		lg.Error(err, fmt.Sprintf("failed to exclude node %s from voting", lastReplicaNodeName))
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Group-%s . failed to exclude node %s from voting", nodePoolGroupName, lastReplicaNodeName)
+		return true, err
+	}
+
+	componentStatus := opsterv1.ComponentStatus{
+		Component:   "Scaler",
+		Status:      "VotingExcluded",
+		Description: nodePoolGroupName,
+	}
+	lg.Info(fmt.Sprintf("Group-%s . excluded node %s from voting", nodePoolGroupName, lastReplicaNodeName))
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Group-%s . excluded node %s from voting", nodePoolGroupName, lastReplicaNodeName)
+	r.instance.Status.ComponentsStatus = helpers.Replace(currentStatus, componentStatus, r.instance.Status.ComponentsStatus)
+	r.recordVotingExclusion(lastReplicaNodeName)
+	err = r.Status().Update(r.ctx, r.instance)
+	if err != nil {
+		lg.Error(err, "failed to update status")
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Failed to update operator status")
+	}
+	return true, err
+}
+
+// recordVotingExclusion remembers a node the operator excluded from voting, only the exclusions of these nodes are
+// cleared by the operator. The status has to be updated by the caller.
+func (r *ScalerReconciler) recordVotingExclusion(nodeName string) {
+	exclusion := opsterv1.ComponentStatus{
+		Component:   votingExclusionComponent,
+		Status:      "Excluded",
+		Description: nodeName,
+	}
+	r.instance.Status.ComponentsStatus = helpers.Replace(exclusion, exclusion, r.instance.Status.ComponentsStatus)
+}
+
+// clearVotingExclusions removes the voting config exclusions the operator added once the excluded nodes have left the
+// cluster. It returns false while an excluded node is still part of the cluster. Exclusions added by others are kept
+// and reported in an event.
+func (r *ScalerReconciler) clearVotingExclusions(clusterClient *services.OsClusterClient) (bool, error) {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	var exclusions []opsterv1.ComponentStatus
+	for _, status := range r.instance.Status.ComponentsStatus {
+		if status.Component == votingExclusionComponent {
+			exclusions = append(exclusions, status)
+		}
+	}
+
+	cleared := true
+	updated := false
+	for _, exclusion := range exclusions {
+		// The API can only clear all exclusions. Exclusions added by others are added again right after, until then
+		// they are briefly missing, and exclusions of nodes unknown to the cluster (_absent_) can't be added again
+		// and are lost. Waiting for all foreign exclusions to go away instead could block the scale down forever.
+		removed, others, err := services.RemoveVotingConfigExclusion(clusterClient, exclusion.Description)
+		if err != nil {
+			return false, err
+		}
+		if !removed {
+			cleared = false
+			continue
+		}
+		if foreign := foreignVotingExclusions(others, exclusions); len(foreign) > 0 {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Voting config exclusions not added by the operator are kept: %s", strings.Join(foreign, ", "))
+		}
+		r.instance.Status.ComponentsStatus = helpers.RemoveIt(exclusion, r.instance.Status.ComponentsStatus)
+		updated = true
+	}
+	if updated {
+		if err := r.Status().Update(r.ctx, r.instance); err != nil {
+			return false, err
+		}
+	}
+	return cleared, nil
+}
+
+// foreignVotingExclusions returns the excluded nodes that were not excluded by the operator
+func foreignVotingExclusions(excludedNodes []string, recorded []opsterv1.ComponentStatus) []string {
+	var foreign []string
+	for _, node := range excludedNodes {
+		found := false
+		for _, exclusion := range recorded {
+			if exclusion.Description == node {
+				found = true
+				break
+			}
+		}
+		if !found {
+			foreign = append(foreign, node)
+		}
+	}
+	return foreign
+}
+
+func (r *ScalerReconciler) removeVotingExclusions(currentStatus opsterv1.ComponentStatus, nodePoolGroupName string) (bool, error) {
+	lg := log.FromContext(r.ctx)
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	username, password, err := helpers.UsernameAndPassword(r.ctx, r.Client, r.instance)
+	if err != nil {
+		return true, err
+	}
+	clusterClient, err := services.NewOsClusterClient(builders.URLForCluster(r.instance), username, password)
+	if err != nil {
+		lg.Error(err, "failed to create os client")
+		return true, err
+	}
+
+	removed, err := r.clearVotingExclusions(clusterClient)
+	if err != nil {
+		lg.Error(err, "failed to remove voting config exclusions")
+		return true, err
+	}
+	if !removed {
+		lg.Info(fmt.Sprintf("Group-%s . waiting for excluded node to leave the cluster", nodePoolGroupName))
+		return true, nil
+	}
+
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Group-%s . cleared voting config exclusion", nodePoolGroupName)
+	r.instance.Status.ComponentsStatus = helpers.RemoveIt(currentStatus, r.instance.Status.ComponentsStatus)
+	err = r.Status().Update(r.ctx, r.instance)
+	if err != nil {
+		lg.Error(err, "failed to update status")
+		return true, err
+	}
+	return false, nil
+}
+
 func (r *ScalerReconciler) drainNode(currentStatus opsterv1.ComponentStatus, currentSts appsv1.StatefulSet, nodePoolGroupName string) error {
 	lg := log.FromContext(r.ctx)
 	annotations := map[string]string{"cluster-name": r.instance.GetName()}
@@ -286,16 +502,95 @@ func (r *ScalerReconciler) cleanupStatefulSets(result *reconciler.CombinedResult
 		return
 	}
 
+	removing := map[string]bool{}
 	for _, sts := range stsList.Items {
 		if !builders.STSInNodePools(sts, r.instance.Spec.NodePools) {
+			removing[sts.Labels[builders.NodePoolLabel]] = true
 			result.Combine(r.removeStatefulSet(sts))
 		}
 	}
+	result.Combine(&ctrl.Result{}, clearDeferredActions(r.ctx, r.Client, r.instance, func(deferred opsterv1.DeferredAction) bool {
+		return deferred.Action == opsterv1.DeferredRemoveNodePool && !removing[deferred.NodePool]
+	}))
+	result.Combine(&ctrl.Result{}, r.clearRemovals(func(status opsterv1.ComponentStatus) bool {
+		return !removing[status.Description]
+	}))
+}
 
+// removalStarted returns true if the removal of the node pool was started in an earlier reconcile
+func (r *ScalerReconciler) removalStarted(nodePool string) bool {
+	for _, status := range r.instance.Status.ComponentsStatus {
+		if status.Component == nodePoolRemovalComponent && status.Description == nodePool {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ScalerReconciler) recordRemovalStarted(nodePool string) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		if r.removalStarted(nodePool) {
+			return nil
+		}
+		r.instance.Status.ComponentsStatus = append(r.instance.Status.ComponentsStatus, opsterv1.ComponentStatus{
+			Component:   nodePoolRemovalComponent,
+			Status:      "Removing",
+			Description: nodePool,
+		})
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+// clearRemovals removes the matching node pools from the started removals
+func (r *ScalerReconciler) clearRemovals(matches func(opsterv1.ComponentStatus) bool) error {
+	found := false
+	for _, status := range r.instance.Status.ComponentsStatus {
+		found = found || (status.Component == nodePoolRemovalComponent && matches(status))
+	}
+	if !found {
+		return nil
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		var remaining []opsterv1.ComponentStatus
+		for _, status := range r.instance.Status.ComponentsStatus {
+			if status.Component != nodePoolRemovalComponent || !matches(status) {
+				remaining = append(remaining, status)
+			}
+		}
+		r.instance.Status.ComponentsStatus = remaining
+		return r.Status().Update(r.ctx, r.instance)
+	})
 }
 
 func (r *ScalerReconciler) removeStatefulSet(sts appsv1.StatefulSet) (*ctrl.Result, error) {
-	if !r.instance.Spec.ConfMgmt.SmartScaler {
+	nodePool := sts.Labels[builders.NodePoolLabel]
+	clusterManager := builders.IsClusterManagerSts(sts)
+	gracefully := r.instance.Spec.ConfMgmt.SmartScaler || clusterManager
+	if !r.removalStarted(nodePool) {
+		// Removals are only started within a maintenance window, started removals are finished
+		deferred, err := deferToMaintenanceWindow(r.ctx, r.Client, r.instance, opsterv1.DeferredAction{
+			Action:   opsterv1.DeferredRemoveNodePool,
+			NodePool: nodePool,
+		})
+		if err != nil || deferred {
+			return &ctrl.Result{}, err
+		}
+		// Graceful removals take several reconciles, remember that the removal was started
+		if gracefully {
+			if err := r.recordRemovalStarted(nodePool); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !gracefully {
 		return r.ReconcileResource(&sts, reconciler.StateAbsent)
 	}
 
@@ -314,29 +609,73 @@ func (r *ScalerReconciler) removeStatefulSet(sts appsv1.StatefulSet) (*ctrl.Resu
 	}
 	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Scaler", "Finished os client for scaling ")
 
+	if clusterManager {
+		// Cluster manager nodes are removed one at a time, the voting exclusion of the
+		// previous node is cleared once it has left the cluster
+		removed, err := r.clearVotingExclusions(clusterClient)
+		if err != nil {
+			lg.Error(err, "failed to remove voting config exclusions")
+			return nil, err
+		}
+		if !removed {
+			return &ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 15 * time.Second,
+			}, nil
+		}
+		if pointer.Int32Deref(sts.Spec.Replicas, 1) == 0 {
+			return r.ReconcileResource(&sts, reconciler.StateAbsent)
+		}
+		safe, err := r.clusterManagerQuorumSafe()
+		if err != nil {
+			return nil, err
+		}
+		if !safe {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Refusing to remove cluster manager nodes of %s, not enough ready cluster manager nodes to keep a quorum", sts.Name)
+			return &ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 30 * time.Second,
+			}, nil
+		}
+	}
+
 	workingOrdinal := pointer.Int32Deref(sts.Spec.Replicas, 1) - 1
 	lastReplicaNodeName := builders.ReplicaHostName(sts, workingOrdinal)
-	_, err = services.AppendExcludeNodeHost(clusterClient, lastReplicaNodeName)
-	if err != nil {
-		lg.Error(err, fmt.Sprintf("failed to exclude node %s", lastReplicaNodeName))
-		return nil, err
+	if clusterManager {
+		_, err = services.AppendVotingConfigExclusion(clusterClient, lastReplicaNodeName)
+		if err != nil {
+			lg.Error(err, fmt.Sprintf("failed to exclude node %s from voting", lastReplicaNodeName))
+			return nil, err
+		}
+		r.recordVotingExclusion(lastReplicaNodeName)
+		if err := r.Status().Update(r.ctx, r.instance); err != nil {
+			return nil, err
+		}
 	}
 
-	nodeNotEmpty, err := services.HasShardsOnNode(clusterClient, lastReplicaNodeName)
-	if err != nil {
-		lg.Error(err, "failed to check shards on node")
-		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Failed to check shards on node")
-		return nil, err
-	}
+	if r.instance.Spec.ConfMgmt.SmartScaler {
+		_, err = services.AppendExcludeNodeHost(clusterClient, lastReplicaNodeName)
+		if err != nil {
+			lg.Error(err, fmt.Sprintf("failed to exclude node %s", lastReplicaNodeName))
+			return nil, err
+		}
 
-	if nodeNotEmpty {
-		return &ctrl.Result{
-			Requeue:      true,
-			RequeueAfter: 15 * time.Second,
-		}, nil
+		nodeNotEmpty, err := services.HasShardsOnNode(clusterClient, lastReplicaNodeName)
+		if err != nil {
+			lg.Error(err, "failed to check shards on node")
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Scaler", "Failed to check shards on node")
+			return nil, err
+		}
+
+		if nodeNotEmpty {
+			return &ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 15 * time.Second,
+			}, nil
+		}
 	}
 
-	if workingOrdinal == 0 {
+	if workingOrdinal == 0 && !clusterManager {
 		result, err := r.ReconcileResource(&sts, reconciler.StateAbsent)
 		if err != nil {
 			return result, err
@@ -354,9 +693,11 @@ func (r *ScalerReconciler) removeStatefulSet(sts appsv1.StatefulSet) (*ctrl.Resu
 		return result, err
 	}
 
-	_, err = services.RemoveExcludeNodeHost(clusterClient, lastReplicaNodeName)
-	if err != nil {
-		lg.Error(err, fmt.Sprintf("failed to remove node exclusion for %s", lastReplicaNodeName))
+	if r.instance.Spec.ConfMgmt.SmartScaler {
+		_, err = services.RemoveExcludeNodeHost(clusterClient, lastReplicaNodeName)
+		if err != nil {
+			lg.Error(err, fmt.Sprintf("failed to remove node exclusion for %s", lastReplicaNodeName))
+		}
 	}
 	r.recorder.AnnotatedEventf(r.instance, annotations, "Noraml", "Scaler", "Finished scaling")
 	return result, err
@@ -0,0 +1,41 @@
+package reconcilers
+
+import (
+	opsterv1 "opensearch.opster.io/api/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scaler Reconciler", func() {
+
+	When("When removing a cluster manager node", func() {
+		It("should allow scaling down from 3 to 2 ready nodes", func() {
+			Expect(quorumSafe(3, 3)).To(BeTrue())
+		})
+
+		It("should allow scaling down from 2 to 1 ready nodes", func() {
+			Expect(quorumSafe(2, 2)).To(BeTrue())
+		})
+
+		It("should not remove the last cluster manager node", func() {
+			Expect(quorumSafe(1, 1)).To(BeFalse())
+		})
+
+		It("should not remove a node if the remaining nodes are not ready", func() {
+			Expect(quorumSafe(3, 2)).To(BeFalse())
+			Expect(quorumSafe(5, 3)).To(BeFalse())
+			Expect(quorumSafe(5, 4)).To(BeTrue())
+		})
+	})
+
+	When("When clearing voting config exclusions", func() {
+		It("should only report the exclusions not added by the operator", func() {
+			recorded := []opsterv1.ComponentStatus{
+				{Component: votingExclusionComponent, Status: "Excluded", Description: "cluster-masters-2"},
+			}
+			Expect(foreignVotingExclusions([]string{"cluster-masters-2", "other-node"}, recorded)).To(Equal([]string{"other-node"}))
+			Expect(foreignVotingExclusions([]string{"cluster-masters-2"}, recorded)).To(BeEmpty())
+		})
+	})
+})
@@ -4,8 +4,10 @@ import (
 	"context"
 	"crypto/sha1"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"sort"
+	"strconv"
 	"time"
 
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
@@ -13,20 +15,50 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
 	"opensearch.opster.io/pkg/builders"
 	"opensearch.opster.io/pkg/helpers"
+	"opensearch.opster.io/pkg/reconcilers/util"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
 )
 
 const (
-	checksumAnnotation = "securityconfig/checksum"
+	checksumAnnotation          = "securityconfig/checksum"
+	attemptAnnotation           = "securityconfig/attempt"
+	attemptedChecksumAnnotation = "securityconfig/attempted-checksum"
+	lastAttemptAnnotation       = "securityconfig/last-attempt"
+	configYml                   = "config.yml"
+	internalUsersYml            = "internal_users.yml"
+	auditYml                    = "audit.yml"
+
+	securityconfigMinBackoff = 30 * time.Second
+	securityconfigMaxBackoff = 30 * time.Minute
 )
 
+// requiredSecurityconfigFiles are the files a securityconfig needs to initialize the security index
+var requiredSecurityconfigFiles = []string{configYml, internalUsersYml, "roles.yml", "roles_mapping.yml", "action_groups.yml", "tenants.yml"}
+
+// securityconfigFiles maps the files of the securityconfig to the document types of the security index
+var securityconfigFiles = map[string]string{
+	"config.yml":         "config",
+	"internal_users.yml": "internalusers",
+	"roles.yml":          "roles",
+	"roles_mapping.yml":  "rolesmapping",
+	"action_groups.yml":  "actiongroups",
+	"tenants.yml":        "tenants",
+	"nodes_dn.yml":       "nodesdn",
+	"whitelist.yml":      "whitelist",
+	"allowlist.yml":      "allowlist",
+	"audit.yml":          "audit",
+}
+
 type SecurityconfigReconciler struct {
 	reconciler.ResourceReconciler
 	client.Client
@@ -65,22 +97,22 @@ func (r *SecurityconfigReconciler) Reconcile() (ctrl.Result, error) {
 	annotations := map[string]string{"cluster-name": r.instance.GetName()}
 	var configSecretName string
 	var checksumval string
-	adminCertName := r.determineAdminSecret()
+	adminCertName := determineAdminSecret(r.instance)
 	namespace := r.instance.Namespace
 	clusterName := r.instance.Name
-	jobName := clusterName + "-securityconfig-update"
 	if adminCertName == "" {
 		r.logger.Info("Cluster is running with demo certificates.")
 		r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Notice - Cluster is running with demo certificates")
 		return ctrl.Result{}, nil
 	}
 	//Checking if Security Config values are empty and creates a default-securityconfig secret
+	var configSecret *corev1.Secret
 	if r.instance.Spec.Security.Config != nil && r.instance.Spec.Security.Config.SecurityconfigSecret.Name != "" {
 		//Use a user passed value of SecurityconfigSecret name
 		configSecretName = r.instance.Spec.Security.Config.SecurityconfigSecret.Name
 		// Wait for secret to be available
-		configSecret := corev1.Secret{}
-		if err := r.Get(r.ctx, client.ObjectKey{Name: configSecretName, Namespace: namespace}, &configSecret); err != nil {
+		configSecret = &corev1.Secret{}
+		if err := r.Get(r.ctx, client.ObjectKey{Name: configSecretName, Namespace: namespace}, configSecret); err != nil {
 			if apierrors.IsNotFound(err) {
 				r.logger.Info(fmt.Sprintf("Waiting for secret '%s' that contains the securityconfig to be created", configSecretName))
 				r.recorder.AnnotatedEventf(r.instance, annotations, "Info", "Security", "Notice - Waiting for secret '%s' that contains the securityconfig to be created", configSecretName)
@@ -88,59 +120,301 @@ func (r *SecurityconfigReconciler) Reconcile() (ctrl.Result, error) {
 			}
 			return ctrl.Result{Requeue: true, RequeueAfter: time.Second * 10}, err
 		}
+	} else {
+		r.logger.Info("Not passed any SecurityconfigSecret")
+	}
 
+	// Render the authentication backends, the generated passwords and the audit config into the securityconfig
+	generatePasswords := r.instance.Spec.Security.Config != nil && r.instance.Spec.Security.Config.GeneratePasswords
+	if r.instance.Spec.Security.Authentication != nil || generatePasswords || r.instance.Spec.Security.Audit != nil {
+		var hashes map[string]string
+		if generatePasswords {
+			var err error
+			if hashes, err = r.reconcileGeneratedPasswords(); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		generated, err := r.reconcileGeneratedSecret(configSecret, hashes)
+		if err != nil {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Failed to render the securityconfig: %s", err)
+			return ctrl.Result{}, err
+		}
+		configSecret = generated
+	}
+
+	if configSecret != nil {
 		// Calculate checksum and check for changes
 		var checksumerr error
 		checksumval, checksumerr = checksum(configSecret.Data)
 		if checksumerr != nil {
 			return ctrl.Result{}, checksumerr
 		}
-		if err := r.securityconfigSubpaths(r.instance, &configSecret); err != nil {
+		if err := r.securityconfigSubpaths(r.instance, configSecret); err != nil {
 			return ctrl.Result{}, err
 		}
-	} else {
-		r.logger.Info("Not passed any SecurityconfigSecret")
 	}
 
-	job := batchv1.Job{}
-	if err := r.Get(r.ctx, client.ObjectKey{Name: jobName, Namespace: namespace}, &job); err == nil {
-		value, exists := job.ObjectMeta.Annotations[checksumAnnotation]
-		if exists && value == checksumval {
-			// Nothing to do, current securityconfig already applied
+	// Remove the update job of previous operator versions, the securityconfig is applied by the operator itself
+	var configData map[string][]byte
+	if configSecret != nil {
+		configData = configSecret.Data
+	}
+	migrated, err := r.migrateUpdateJob(clusterName+"-securityconfig-update", configData, checksumval)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if configSecret == nil || !completeSecurityconfig(configSecret.Data) {
+		// The nodes initialize the security index with the default securityconfig of the image, the files of a
+		// partial securityconfig replace the corresponding parts of it afterwards
+		r.reconcilerContext.AddConfig("plugins.security.allow_default_init_securityindex", "true")
+	}
+	if configSecret == nil {
+		return ctrl.Result{}, nil
+	}
+
+	state, err := r.appliedSecurityconfig()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if migrated || (state != nil && state.Annotations[checksumAnnotation] == checksumval) {
+		// Nothing to do, current securityconfig already applied
+		if generatePasswords {
+			if err := r.activatePendingPasswords(); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		return ctrl.Result{}, r.setAppliedCondition(metav1.ConditionTrue, "Applied", "securityconfig applied")
+	}
+	if state != nil && state.Annotations[attemptedChecksumAnnotation] == checksumval {
+		// Retry a failed update after a backoff, the cluster is reconciled periodically
+		attempt, _ := strconv.Atoi(state.Annotations[attemptAnnotation])
+		lastAttempt, _ := time.Parse(time.RFC3339, state.Annotations[lastAttemptAnnotation])
+		if time.Since(lastAttempt) < securityconfigBackoff(attempt-1) {
 			return ctrl.Result{}, nil
 		}
-		// Delete old job
-		r.logger.Info("Deleting old update job")
-		opts := client.DeleteOptions{}
-		// Add this so pods of the job are deleted as well, otherwise they would remain as orphaned pods
-		client.PropagationPolicy(metav1.DeletePropagationForeground).ApplyToDelete(&opts)
-		err = r.Delete(r.ctx, &job, &opts)
-		if err != nil {
-			return ctrl.Result{}, err
+	}
+
+	r.logger.Info("Applying securityconfig")
+	if err := r.applySecurityconfig(configSecret.Data, state, adminCertName); err != nil {
+		r.logger.Error(err, "Failed to apply securityconfig")
+		if !r.instance.Status.Initialized {
+			// The nodes are still starting, the update is retried with the next reconcile without a backoff
+			return ctrl.Result{}, r.setAppliedCondition(metav1.ConditionFalse, "Applying", fmt.Sprintf("waiting for the cluster: %s", err))
 		}
-		// Make sure job is completely deleted (when r.Delete returns deletion sometimes is not yet complete)
-		_, err = r.ReconcileResource(&job, reconciler.StateAbsent)
-		if err != nil {
+		if err := r.recordFailedAttempt(state, checksumval); err != nil {
 			return ctrl.Result{}, err
 		}
+		// Failures don't block the reconciliation of the other components
+		return ctrl.Result{}, r.setAppliedCondition(metav1.ConditionFalse, "Failed", err.Error())
 	}
-	r.logger.Info("Starting securityconfig update job")
-	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Security", "Starting to securityconfig update job")
-	job = builders.NewSecurityconfigUpdateJob(
-		r.instance,
-		jobName,
-		namespace,
-		checksumval,
-		adminCertName,
-		clusterName,
-		r.reconcilerContext.Volumes,
-		r.reconcilerContext.VolumeMounts,
-	)
-	if err := ctrl.SetControllerReference(r.instance, &job, r.Client.Scheme()); err != nil {
+	if err := r.recordAppliedSecurityconfig(configSecret.Data, checksumval); err != nil {
 		return ctrl.Result{}, err
 	}
-	_, err := r.ReconcileResource(&job, reconciler.StateCreated)
-	return ctrl.Result{}, err
+	if generatePasswords {
+		// The new hashes are applied, the pending passwords can be handed out now
+		if err := r.activatePendingPasswords(); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{}, r.setAppliedCondition(metav1.ConditionTrue, "Applied", "securityconfig applied")
+}
+
+// applySecurityconfig applies the securityconfig with the admin certificate. In the incremental update mode only the
+// changed entries are applied if the previously applied securityconfig is known, otherwise all files are uploaded.
+func (r *SecurityconfigReconciler) applySecurityconfig(data map[string][]byte, state *corev1.Secret, adminCertName string) error {
+	osClient, err := util.CreateAdminClientForCluster(r.ctx, r.Client, r.instance, adminCertName, nil)
+	if err != nil {
+		return err
+	}
+	if !completeSecurityconfig(data) {
+		exists, err := osClient.SecurityIndexExists(r.ctx)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("waiting for the nodes to initialize the security index")
+		}
+	}
+	if r.incrementalUpdate() && state != nil && state.Annotations[checksumAnnotation] != "" {
+		changes, uploads, err := securityconfigChanges(state.Data, data)
+		if err != nil {
+			return err
+		}
+		if len(uploads) > 0 {
+			if err := r.uploadSecurityconfig(osClient, uploads); err != nil {
+				return err
+			}
+		}
+		return r.applyChanges(osClient, changes)
+	}
+
+	return r.uploadSecurityconfig(osClient, data)
+}
+
+// uploadSecurityconfig replaces the documents of the security index for the given files as a whole
+func (r *SecurityconfigReconciler) uploadSecurityconfig(osClient *services.OsClusterClient, data map[string][]byte) error {
+	configs, err := securityconfigDocuments(data)
+	if err != nil {
+		return err
+	}
+	if len(configs) == 0 {
+		return nil
+	}
+	if err := services.UploadSecurityconfig(r.ctx, osClient, configs); err != nil {
+		return err
+	}
+	r.recorder.AnnotatedEventf(r.instance, map[string]string{"cluster-name": r.instance.GetName()}, "Normal", "Security", "Uploaded securityconfig")
+	return nil
+}
+
+// securityconfigDocuments converts the files of the securityconfig to the JSON documents of the security index.
+// Files that are not part of the securityconfig are ignored.
+func securityconfigDocuments(data map[string][]byte) (map[string][]byte, error) {
+	configs := map[string][]byte{}
+	for file, content := range data {
+		configType, ok := securityconfigFiles[file]
+		if !ok {
+			continue
+		}
+		document, err := yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+		}
+		configs[configType] = document
+	}
+	return configs, nil
+}
+
+// completeSecurityconfig returns true if the securityconfig contains all files needed to initialize the security index
+func completeSecurityconfig(data map[string][]byte) bool {
+	for _, file := range requiredSecurityconfigFiles {
+		if _, ok := data[file]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// appliedSecurityconfig returns the secret with the last applied securityconfig and the failed update attempts, nil
+// if the securityconfig was not applied yet
+func (r *SecurityconfigReconciler) appliedSecurityconfig() (*corev1.Secret, error) {
+	state := &corev1.Secret{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: builders.AppliedSecurityconfigSecretName(r.instance), Namespace: r.instance.Namespace}, state); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return state, nil
+}
+
+// recordAppliedSecurityconfig stores the applied securityconfig, the next incremental update is calculated against it
+func (r *SecurityconfigReconciler) recordAppliedSecurityconfig(data map[string][]byte, checksumval string) error {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        builders.AppliedSecurityconfigSecretName(r.instance),
+			Namespace:   r.instance.Namespace,
+			Annotations: map[string]string{checksumAnnotation: checksumval},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(r.instance, secret, r.Client.Scheme()); err != nil {
+		return err
+	}
+	_, err := r.ReconcileResource(secret, reconciler.StatePresent)
+	return err
+}
+
+// recordFailedAttempt counts the failed updates of the securityconfig to calculate the backoff of the next attempt
+func (r *SecurityconfigReconciler) recordFailedAttempt(state *corev1.Secret, checksumval string) error {
+	attempt := 1
+	if state == nil {
+		state = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      builders.AppliedSecurityconfigSecretName(r.instance),
+				Namespace: r.instance.Namespace,
+			},
+			Type: corev1.SecretTypeOpaque,
+		}
+		if err := ctrl.SetControllerReference(r.instance, state, r.Client.Scheme()); err != nil {
+			return err
+		}
+	} else if state.Annotations[attemptedChecksumAnnotation] == checksumval {
+		previous, _ := strconv.Atoi(state.Annotations[attemptAnnotation])
+		attempt = previous + 1
+	}
+	if state.Annotations == nil {
+		state.Annotations = map[string]string{}
+	}
+	state.Annotations[attemptedChecksumAnnotation] = checksumval
+	state.Annotations[attemptAnnotation] = strconv.Itoa(attempt)
+	state.Annotations[lastAttemptAnnotation] = time.Now().UTC().Format(time.RFC3339)
+	if state.ResourceVersion == "" {
+		return r.Create(r.ctx, state)
+	}
+	return r.Update(r.ctx, state)
+}
+
+// migrateUpdateJob deletes the securityconfig update job created by previous versions of the operator. If the job
+// already applied the current securityconfig it is recorded as applied and true is returned, so it isn't uploaded
+// again and the users and roles created through the API are kept.
+func (r *SecurityconfigReconciler) migrateUpdateJob(jobName string, data map[string][]byte, checksumval string) (bool, error) {
+	job := batchv1.Job{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: jobName, Namespace: r.instance.Namespace}, &job); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+	migrated := false
+	if data != nil && job.Status.Succeeded > 0 && job.Annotations[checksumAnnotation] == checksumval {
+		state, err := r.appliedSecurityconfig()
+		if err != nil {
+			return false, err
+		}
+		if state == nil {
+			r.logger.Info("Recording the securityconfig applied by the old update job")
+			if err := r.recordAppliedSecurityconfig(data, checksumval); err != nil {
+				return false, err
+			}
+			migrated = true
+		}
+	}
+	r.logger.Info("Deleting old update job")
+	opts := client.DeleteOptions{}
+	// Add this so pods of the job are deleted as well, otherwise they would remain as orphaned pods
+	client.PropagationPolicy(metav1.DeletePropagationForeground).ApplyToDelete(&opts)
+	return migrated, client.IgnoreNotFound(r.Delete(r.ctx, &job, &opts))
+}
+
+// setAppliedCondition sets the SecurityConfigApplied condition and emits an event if the condition changed
+func (r *SecurityconfigReconciler) setAppliedCondition(status metav1.ConditionStatus, reason string, message string) error {
+	existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionSecurityconfigApplied)
+	if existing == nil || existing.Status != status || existing.Reason != reason || existing.Message != message {
+		annotations := map[string]string{"cluster-name": r.instance.GetName()}
+		switch reason {
+		case "Applied":
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Security", "Securityconfig applied")
+		case "Failed":
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Failed to apply the securityconfig: %s", message)
+		}
+	}
+	return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+		Type:    opsterv1.ConditionSecurityconfigApplied,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// securityconfigBackoff returns how long to wait before retrying a failed update of the securityconfig
+func securityconfigBackoff(attempt int) time.Duration {
+	backoff := securityconfigMinBackoff
+	for i := 0; i < attempt && backoff < securityconfigMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > securityconfigMaxBackoff {
+		return securityconfigMaxBackoff
+	}
+	return backoff
 }
 
 func checksum(data map[string][]byte) (string, error) {
@@ -164,16 +438,272 @@ func checksum(data map[string][]byte) (string, error) {
 	return base64.StdEncoding.EncodeToString(hash.Sum(nil)), nil
 }
 
-func (r *SecurityconfigReconciler) determineAdminSecret() string {
-	if r.instance.Spec.Security.Config != nil && r.instance.Spec.Security.Config.AdminSecret.Name != "" {
-		return r.instance.Spec.Security.Config.AdminSecret.Name
-	} else if r.instance.Spec.Security.Tls != nil && r.instance.Spec.Security.Tls.Transport != nil && r.instance.Spec.Security.Tls.Transport.Generate {
-		return fmt.Sprintf("%s-admin-cert", r.instance.Name)
+func determineAdminSecret(instance *opsterv1.OpenSearchCluster) string {
+	if instance.Spec.Security.Config != nil && instance.Spec.Security.Config.AdminSecret.Name != "" {
+		return instance.Spec.Security.Config.AdminSecret.Name
+	} else if instance.Spec.Security.Tls != nil && instance.Spec.Security.Tls.Transport != nil && instance.Spec.Security.Tls.Transport.Generate {
+		return fmt.Sprintf("%s-admin-cert", instance.Name)
 	} else {
 		return ""
 	}
 }
 
+// reconcileGeneratedSecret creates the secret with the securityconfig that is applied to the cluster. It contains the
+// files of the user provided securityconfig with the rendered authentication backends merged into its config.yml,
+// the hashes of the generated passwords merged into its internal_users.yml and the rendered audit config as audit.yml
+func (r *SecurityconfigReconciler) reconcileGeneratedSecret(configSecret *corev1.Secret, hashes map[string]string) (*corev1.Secret, error) {
+	data := map[string][]byte{}
+	config := map[string]interface{}{}
+	if configSecret != nil {
+		for key, value := range configSecret.Data {
+			data[key] = value
+		}
+		if existing, ok := data[configYml]; ok {
+			if err := yaml.Unmarshal(existing, &config); err != nil {
+				return nil, fmt.Errorf("failed to parse %s of secret %s: %w", configYml, configSecret.Name, err)
+			}
+			if config == nil {
+				config = map[string]interface{}{}
+			}
+		}
+	}
+
+	if r.instance.Spec.Security.Authentication != nil {
+		rendered, err := r.authenticationConfig()
+		if err != nil {
+			return nil, err
+		}
+		merged, err := yaml.Marshal(mergeConfig(config, rendered))
+		if err != nil {
+			return nil, err
+		}
+		data[configYml] = merged
+	}
+	if hashes != nil {
+		users, err := mergeInternalUsers(data[internalUsersYml], hashes)
+		if err != nil {
+			return nil, err
+		}
+		data[internalUsersYml] = users
+	}
+	if audit := r.instance.Spec.Security.Audit; audit != nil {
+		rendered, err := auditConfigYml(audit)
+		if err != nil {
+			return nil, err
+		}
+		data[auditYml] = rendered
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      builders.GeneratedSecurityconfigSecretName(r.instance),
+			Namespace: r.instance.Namespace,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: data,
+	}
+	if err := ctrl.SetControllerReference(r.instance, secret, r.Client.Scheme()); err != nil {
+		return nil, err
+	}
+	if _, err := r.ReconcileResource(secret, reconciler.StatePresent); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
+
+// authenticationConfig renders the config.yml of the authentication backends configured in the spec
+func (r *SecurityconfigReconciler) authenticationConfig() (map[string]interface{}, error) {
+	auth := r.instance.Spec.Security.Authentication
+	// With OIDC or SAML dashboards shows its own login page, a basic auth challenge would only get in the way
+	sso := auth.OIDC != nil || auth.SAML != nil
+	authc := map[string]interface{}{}
+	authz := map[string]interface{}{}
+
+	// The operator and dashboards log in with internal users via HTTP Basic, so the internal domain is kept even if
+	// basic auth is disabled. Disabling it only removes the login prompt for the internal users.
+	basicEnabled := auth.Basic == nil || auth.Basic.Enabled
+	authc["basic_internal_auth_domain"] = map[string]interface{}{
+		"description":       "Authenticate via HTTP Basic against internal users database",
+		"http_enabled":      true,
+		"transport_enabled": true,
+		"order":             0,
+		"http_authenticator": map[string]interface{}{
+			"type":      "basic",
+			"challenge": basicEnabled && !sso && auth.LDAP == nil,
+		},
+		"authentication_backend": map[string]interface{}{
+			"type": "intern",
+		},
+	}
+
+	if ldap := auth.LDAP; ldap != nil {
+		ldapConfig := map[string]interface{}{
+			"enable_ssl": ldap.EnableSSL,
+			"hosts":      ldap.Hosts,
+			"userbase":   ldap.UserBase,
+			"usersearch": valueOrDefault(ldap.UserSearch, "(sAMAccountName={0})"),
+		}
+		if ldap.UsernameAttribute != "" {
+			ldapConfig["username_attribute"] = ldap.UsernameAttribute
+		}
+		if ldap.BindSecret != nil {
+			bindDn, err := r.secretValue(ldap.BindSecret.Name, "username")
+			if err != nil {
+				return nil, err
+			}
+			password, err := r.secretValue(ldap.BindSecret.Name, "password")
+			if err != nil {
+				return nil, err
+			}
+			ldapConfig["bind_dn"] = bindDn
+			ldapConfig["password"] = password
+		}
+		authc["ldap_auth_domain"] = map[string]interface{}{
+			"description":       "Authenticate via HTTP Basic against LDAP",
+			"http_enabled":      true,
+			"transport_enabled": true,
+			"order":             1,
+			"http_authenticator": map[string]interface{}{
+				"type":      "basic",
+				"challenge": !sso,
+			},
+			"authentication_backend": map[string]interface{}{
+				"type":   "ldap",
+				"config": ldapConfig,
+			},
+		}
+
+		if ldap.RoleBase != "" {
+			rolesConfig := map[string]interface{}{
+				"rolebase":   ldap.RoleBase,
+				"rolesearch": valueOrDefault(ldap.RoleSearch, "(member={0})"),
+				"rolename":   valueOrDefault(ldap.RoleName, "cn"),
+			}
+			for key, value := range ldapConfig {
+				rolesConfig[key] = value
+			}
+			authz["ldap_roles"] = map[string]interface{}{
+				"description":       "Authorize via LDAP",
+				"http_enabled":      true,
+				"transport_enabled": true,
+				"authorization_backend": map[string]interface{}{
+					"type":   "ldap",
+					"config": rolesConfig,
+				},
+			}
+		}
+	}
+
+	if oidc := auth.OIDC; oidc != nil {
+		oidcConfig := map[string]interface{}{
+			"openid_connect_url": oidc.ConnectURL,
+		}
+		if oidc.SubjectKey != "" {
+			oidcConfig["subject_key"] = oidc.SubjectKey
+		}
+		if oidc.RolesKey != "" {
+			oidcConfig["roles_key"] = oidc.RolesKey
+		}
+		authc["openid_auth_domain"] = map[string]interface{}{
+			"description":       "Authenticate via OpenID Connect",
+			"http_enabled":      true,
+			"transport_enabled": true,
+			"order":             2,
+			"http_authenticator": map[string]interface{}{
+				"type":      "openid",
+				"challenge": false,
+				"config":    oidcConfig,
+			},
+			"authentication_backend": map[string]interface{}{
+				"type": "noop",
+			},
+		}
+	}
+
+	if saml := auth.SAML; saml != nil {
+		exchangeKey, err := r.secretValue(saml.ExchangeKey.Name, saml.ExchangeKey.Key)
+		if err != nil {
+			return nil, err
+		}
+		samlConfig := map[string]interface{}{
+			"idp": map[string]interface{}{
+				"metadata_url": saml.IdpMetadataURL,
+				"entity_id":    saml.IdpEntityID,
+			},
+			"sp": map[string]interface{}{
+				"entity_id": saml.SpEntityID,
+			},
+			"kibana_url":   saml.DashboardsURL,
+			"exchange_key": exchangeKey,
+		}
+		if saml.RolesKey != "" {
+			samlConfig["roles_key"] = saml.RolesKey
+		}
+		authc["saml_auth_domain"] = map[string]interface{}{
+			"description":       "Authenticate via SAML",
+			"http_enabled":      true,
+			"transport_enabled": false,
+			"order":             3,
+			"http_authenticator": map[string]interface{}{
+				"type":      "saml",
+				"challenge": true,
+				"config":    samlConfig,
+			},
+			"authentication_backend": map[string]interface{}{
+				"type": "noop",
+			},
+		}
+	}
+
+	dynamic := map[string]interface{}{"authc": authc}
+	if len(authz) > 0 {
+		dynamic["authz"] = authz
+	}
+	return map[string]interface{}{
+		"_meta": map[string]interface{}{
+			"type":           "config",
+			"config_version": 2,
+		},
+		"config": map[string]interface{}{
+			"dynamic": dynamic,
+		},
+	}, nil
+}
+
+// secretValue reads a key of a secret in the namespace of the cluster
+func (r *SecurityconfigReconciler) secretValue(name string, key string) (string, error) {
+	secret := corev1.Secret{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: name, Namespace: r.instance.Namespace}, &secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data[key]
+	if !ok {
+		return "", fmt.Errorf("secret %s does not contain %s", name, key)
+	}
+	return string(value), nil
+}
+
+// mergeConfig recursively merges overlay into base, values of overlay take precedence
+func mergeConfig(base map[string]interface{}, overlay map[string]interface{}) map[string]interface{} {
+	for key, value := range overlay {
+		if overlayMap, ok := value.(map[string]interface{}); ok {
+			if baseMap, ok := base[key].(map[string]interface{}); ok {
+				base[key] = mergeConfig(baseMap, overlayMap)
+				continue
+			}
+		}
+		base[key] = value
+	}
+	return base
+}
+
+func valueOrDefault(value string, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 func (r *SecurityconfigReconciler) DeleteResources() (ctrl.Result, error) {
 	result := reconciler.CombinedResult{}
 	return result.Result, result.Err
@@ -0,0 +1,190 @@
+package reconcilers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/banzaicloud/operator-tools/pkg/reconciler"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+)
+
+const defaultSecurityconfigBackupVersions = 7
+
+// securityconfigBackupTypes are the types of the security config that are exported
+var securityconfigBackupTypes = []string{"config", "internalusers", "roles", "rolesmapping", "actiongroups", "tenants"}
+
+type SecurityconfigBackupReconciler struct {
+	client.Client
+	reconciler.ResourceReconciler
+	ctx               context.Context
+	recorder          record.EventRecorder
+	reconcilerContext *ReconcilerContext
+	instance          *opsterv1.OpenSearchCluster
+	logger            logr.Logger
+}
+
+func NewSecurityconfigBackupReconciler(
+	client client.Client,
+	ctx context.Context,
+	recorder record.EventRecorder,
+	reconcilerContext *ReconcilerContext,
+	instance *opsterv1.OpenSearchCluster,
+	opts ...reconciler.ResourceReconcilerOption,
+) *SecurityconfigBackupReconciler {
+	return &SecurityconfigBackupReconciler{
+		Client: client,
+		ResourceReconciler: reconciler.NewReconcilerWith(client,
+			append(opts, reconciler.WithLog(log.FromContext(ctx).WithValues("reconciler", "securityconfigbackup")))...),
+		ctx:               ctx,
+		recorder:          recorder,
+		reconcilerContext: reconcilerContext,
+		instance:          instance,
+		logger:            log.FromContext(ctx).WithValues("reconciler", "securityconfigbackup"),
+	}
+}
+
+// Reconcile exports the security config of the cluster into a new secret when the backup schedule is due and
+// deletes the backups exceeding the number of versions to keep
+func (r *SecurityconfigBackupReconciler) Reconcile() (ctrl.Result, error) {
+	if r.instance.Spec.Security == nil || r.instance.Spec.Security.Config == nil || r.instance.Spec.Security.Config.Backup == nil {
+		return ctrl.Result{}, nil
+	}
+	if !r.instance.Status.Initialized {
+		return ctrl.Result{}, nil
+	}
+	backup := r.instance.Spec.Security.Config.Backup
+
+	schedule, err := parseSchedule(backup.Schedule, backup.Timezone)
+	if err != nil {
+		return ctrl.Result{}, r.setBackupCondition(metav1.ConditionFalse, "InvalidSchedule", fmt.Sprintf("invalid backup schedule %q: %s", backup.Schedule, err))
+	}
+	backups, err := r.listBackups()
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	// The first backup is taken right away, later ones when the schedule is due after the last backup
+	if len(backups) > 0 && schedule.Next(backups[len(backups)-1].CreationTimestamp.Time).After(time.Now()) {
+		return ctrl.Result{}, nil
+	}
+
+	adminCertName := determineAdminSecret(r.instance)
+	if adminCertName == "" {
+		return ctrl.Result{}, r.setBackupCondition(metav1.ConditionFalse, "Failed", "an admin certificate is required to read the security config")
+	}
+	osClient, err := util.CreateAdminClientForCluster(r.ctx, r.Client, r.instance, adminCertName, nil)
+	if err != nil {
+		r.logger.Error(err, "Failed to create the admin client")
+		return ctrl.Result{}, r.setBackupCondition(metav1.ConditionFalse, "Failed", err.Error())
+	}
+	configs, err := services.ExportSecurityconfig(r.ctx, osClient, securityconfigBackupTypes)
+	if err != nil {
+		r.logger.Error(err, "Failed to export the security config")
+		return ctrl.Result{}, r.setBackupCondition(metav1.ConditionFalse, "Failed", err.Error())
+	}
+	data, err := securityconfigBackupData(configs)
+	if err != nil {
+		return ctrl.Result{}, r.setBackupCondition(metav1.ConditionFalse, "Failed", err.Error())
+	}
+
+	secret := builders.NewSecurityconfigBackupSecret(r.instance, time.Now(), data)
+	if err := r.Create(r.ctx, secret); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.logger.Info("Backed up the security config", "secret", secret.Name)
+	if err := r.deleteOldBackups(append(backups, *secret)); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, r.setBackupCondition(metav1.ConditionTrue, "BackedUp", fmt.Sprintf("last backup saved in secret %s", secret.Name))
+}
+
+// listBackups returns the backup secrets of the cluster from the oldest to the newest
+func (r *SecurityconfigBackupReconciler) listBackups() ([]corev1.Secret, error) {
+	secrets := corev1.SecretList{}
+	if err := r.List(r.ctx, &secrets, client.InNamespace(r.instance.Namespace), client.MatchingLabels{
+		builders.ClusterLabel:              r.instance.Name,
+		builders.SecurityconfigBackupLabel: "true",
+	}); err != nil {
+		return nil, err
+	}
+	backups := secrets.Items
+	// The names end with the time the backup was taken
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Name < backups[j].Name })
+	return backups, nil
+}
+
+// deleteOldBackups deletes the oldest backups exceeding the number of versions to keep
+func (r *SecurityconfigBackupReconciler) deleteOldBackups(backups []corev1.Secret) error {
+	for _, backup := range expiredBackups(backups, r.instance.Spec.Security.Config.Backup.KeepVersions) {
+		r.logger.Info("Deleting old security config backup", "secret", backup.Name)
+		if err := r.Delete(r.ctx, &backup); client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setBackupCondition sets the SecurityConfigBackedUp condition and emits an event if the condition changed
+func (r *SecurityconfigBackupReconciler) setBackupCondition(status metav1.ConditionStatus, reason string, message string) error {
+	existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionSecurityconfigBackup)
+	if existing == nil || existing.Status != status || existing.Reason != reason || existing.Message != message {
+		annotations := map[string]string{"cluster-name": r.instance.GetName()}
+		if status == metav1.ConditionTrue {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Security", "Backed up the security config, %s", message)
+		} else {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Security", "Failed to back up the security config: %s", message)
+		}
+	}
+	return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+		Type:    opsterv1.ConditionSecurityconfigBackup,
+		Status:  status,
+		Reason:  reason,
+		Message: message,
+	})
+}
+
+// securityconfigBackupData converts the exported JSON configs to the yml files of a securityconfig secret
+func securityconfigBackupData(configs map[string][]byte) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(configs))
+	for file, configType := range securityconfigFiles {
+		config, ok := configs[configType]
+		if !ok {
+			continue
+		}
+		content, err := yaml.JSONToYAML(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", configType, err)
+		}
+		data[file] = content
+	}
+	if _, ok := data[configYml]; !ok {
+		return nil, errors.New("the security index does not contain a config")
+	}
+	return data, nil
+}
+
+// expiredBackups returns the oldest backups exceeding the number of versions to keep, the backups are sorted from
+// the oldest to the newest
+func expiredBackups(backups []corev1.Secret, keep int32) []corev1.Secret {
+	if keep <= 0 {
+		keep = defaultSecurityconfigBackupVersions
+	}
+	if len(backups) <= int(keep) {
+		return nil
+	}
+	return backups[:len(backups)-int(keep)]
+}
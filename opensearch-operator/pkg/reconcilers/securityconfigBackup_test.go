@@ -0,0 +1,80 @@
+package reconcilers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Securityconfig Backup Reconciler", func() {
+
+	When("When Reconciling the securityconfig backup reconciler before the cluster is initialized", func() {
+		It("should not do anything", func() {
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "securityconfig-backup", Namespace: "securityconfig-backup"},
+				Spec: opsterv1.ClusterSpec{
+					Security: &opsterv1.Security{
+						Config: &opsterv1.SecurityConfig{
+							AdminSecret: corev1.LocalObjectReference{Name: "admin-cert"},
+							Backup:      &opsterv1.SecurityconfigBackup{Schedule: "0 3 * * *"},
+						},
+					},
+				}}
+
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigBackupReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+			result, err := underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result.IsZero()).To(BeTrue())
+			Expect(spec.Status.Conditions).To(BeEmpty())
+		})
+	})
+
+	When("When converting an exported security config", func() {
+		It("should store the configs as the files of a securityconfig secret", func() {
+			data, err := securityconfigBackupData(map[string][]byte{
+				"config":        []byte(`{"_meta":{"type":"config","config_version":2},"config":{"dynamic":{}}}`),
+				"internalusers": []byte(`{"admin":{"hash":"foo"}}`),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(data).To(HaveLen(2))
+			Expect(string(data["internal_users.yml"])).To(Equal("admin:\n  hash: foo\n"))
+			Expect(data).To(HaveKey("config.yml"))
+		})
+
+		It("should fail without the config", func() {
+			_, err := securityconfigBackupData(map[string][]byte{"roles": []byte(`{}`)})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("When cleaning up old backups", func() {
+		backups := []corev1.Secret{
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-securityconfig-backup-20260101030000"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-securityconfig-backup-20260102030000"}},
+			{ObjectMeta: metav1.ObjectMeta{Name: "cluster-securityconfig-backup-20260103030000"}},
+		}
+
+		It("should delete the oldest backups exceeding the versions to keep", func() {
+			expired := expiredBackups(backups, 2)
+			Expect(expired).To(HaveLen(1))
+			Expect(expired[0].Name).To(Equal("cluster-securityconfig-backup-20260101030000"))
+		})
+
+		It("should keep all backups within the versions to keep", func() {
+			Expect(expiredBackups(backups, 0)).To(BeEmpty())
+		})
+	})
+})
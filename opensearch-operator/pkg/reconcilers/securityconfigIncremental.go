@@ -0,0 +1,157 @@
+package reconcilers
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"sigs.k8s.io/yaml"
+)
+
+// incrementalSecurityconfigFiles are the files of the securityconfig that can be applied entry by entry through
+// the security REST API, in the order they are applied so that referenced entries exist first
+var incrementalSecurityconfigFiles = []struct {
+	file       string
+	configType string
+}{
+	{"action_groups.yml", "actiongroups"},
+	{"tenants.yml", "tenants"},
+	{"roles.yml", "roles"},
+	{"internal_users.yml", "internalusers"},
+	{"roles_mapping.yml", "rolesmapping"},
+}
+
+// Fields of the securityconfig files that are managed by the security plugin and rejected by the REST API
+var readOnlyEntryFields = []string{"reserved", "hidden", "static"}
+
+type securityconfigChange struct {
+	configType string
+	name       string
+	// Body of the entry, nil if the entry was removed
+	entry interface{}
+}
+
+func (r *SecurityconfigReconciler) incrementalUpdate() bool {
+	config := r.instance.Spec.Security.Config
+	return config != nil && config.UpdateMode == opsterv1.SecurityconfigUpdateIncremental
+}
+
+// applyChanges applies the changes of the securityconfig since the last update through the security REST API
+func (r *SecurityconfigReconciler) applyChanges(osClient *services.OsClusterClient, changes []securityconfigChange) error {
+	for _, change := range changes {
+		var err error
+		if change.entry == nil {
+			err = services.DeleteSecurityconfigEntry(r.ctx, osClient, change.configType, change.name)
+		} else {
+			err = services.PutSecurityconfigEntry(r.ctx, osClient, change.configType, change.name, change.entry)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	r.logger.Info("Applied securityconfig changes incrementally", "changes", len(changes))
+	r.recorder.AnnotatedEventf(r.instance, map[string]string{"cluster-name": r.instance.GetName()}, "Normal", "Security", "Applied %d securityconfig changes incrementally", len(changes))
+	return nil
+}
+
+// securityconfigChanges calculates the REST API calls needed to get from the applied to the current securityconfig.
+// Entries that are not part of either version are not touched. Files that were added or that changed but can't be
+// applied entry by entry are returned separately, these have to be uploaded as a whole. Documents of removed files
+// are left as they are, the same as with a full upload.
+func securityconfigChanges(applied map[string][]byte, current map[string][]byte) ([]securityconfigChange, map[string][]byte, error) {
+	uploads := map[string][]byte{}
+	for file, data := range current {
+		if old, ok := applied[file]; !ok || (!bytes.Equal(old, data) && !incrementalFile(file)) {
+			uploads[file] = data
+		}
+	}
+
+	var changes []securityconfigChange
+	for _, f := range incrementalSecurityconfigFiles {
+		data, exists := current[f.file]
+		if _, upload := uploads[f.file]; !exists || upload || bytes.Equal(applied[f.file], data) {
+			continue
+		}
+		oldEntries, err := securityconfigEntries(applied[f.file])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse applied %s: %w", f.file, err)
+		}
+		newEntries, err := securityconfigEntries(data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", f.file, err)
+		}
+		for _, name := range sortedKeys(newEntries) {
+			if old, ok := oldEntries[name]; !ok || !reflect.DeepEqual(old, newEntries[name]) {
+				changes = append(changes, securityconfigChange{configType: f.configType, name: name, entry: newEntries[name]})
+			}
+		}
+		for _, name := range sortedKeys(oldEntries) {
+			if _, ok := newEntries[name]; !ok {
+				changes = append(changes, securityconfigChange{configType: f.configType, name: name})
+			}
+		}
+	}
+
+	_, upload := uploads[configYml]
+	_, exists := current[configYml]
+	if exists && !upload && !bytes.Equal(applied[configYml], current[configYml]) {
+		config := map[string]interface{}{}
+		if err := yaml.Unmarshal(current[configYml], &config); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s: %w", configYml, err)
+		}
+		dynamic, ok := config["config"]
+		if !ok {
+			return nil, nil, fmt.Errorf("%s does not contain a config section", configYml)
+		}
+		changes = append(changes, securityconfigChange{configType: "securityconfig", name: "config", entry: dynamic})
+	}
+	return changes, uploads, nil
+}
+
+func incrementalFile(file string) bool {
+	if file == configYml {
+		return true
+	}
+	for _, f := range incrementalSecurityconfigFiles {
+		if f.file == file {
+			return true
+		}
+	}
+	return false
+}
+
+// securityconfigEntries parses a file of the securityconfig into its entries without the _meta section
+func securityconfigEntries(data []byte) (map[string]interface{}, error) {
+	entries := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	delete(entries, "_meta")
+	for name, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			if entry != nil {
+				return nil, fmt.Errorf("entry %s is not an object", name)
+			}
+			fields = map[string]interface{}{}
+		}
+		for _, field := range readOnlyEntryFields {
+			delete(fields, field)
+		}
+		entries[name] = fields
+	}
+	return entries, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
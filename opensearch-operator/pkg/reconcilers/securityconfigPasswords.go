@@ -0,0 +1,199 @@
+package reconcilers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/bcrypt"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/helpers"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Value of the rotate passwords annotation of the cluster the password of a credentials secret was generated for
+	passwordRotationAnnotation = "opster.io/rotation"
+	// Value of the rotate passwords annotation the pending password of a credentials secret was generated for
+	pendingRotationAnnotation = "opster.io/pending-rotation"
+	pendingPasswordKey        = "pending-password"
+	pendingHashKey            = "pending-hash"
+	generatedPasswordLength   = 32
+	passwordCharacters        = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	passwordHashCost          = 12
+)
+
+// builtinUsers are the users of the securityconfig the operator generates passwords for
+var builtinUsers = []struct {
+	name         string
+	backendRoles []string
+	description  string
+}{
+	{"admin", []string{"admin"}, "Admin user, the password is generated by the operator"},
+	{"kibanaserver", nil, "Dashboards server user, the password is generated by the operator"},
+}
+
+// reconcileGeneratedPasswords creates the secrets with the generated passwords of the built-in users and rotates the
+// passwords when the rotate passwords annotation of the cluster changes. It returns the password hashes by user.
+// A rotated password is staged in the secret next to the current one, the current password keeps working until the
+// securityconfig with the new hash has been applied and activatePendingPasswords swaps them.
+func (r *SecurityconfigReconciler) reconcileGeneratedPasswords() (map[string]string, error) {
+	rotation := r.instance.Annotations[opsterv1.RotatePasswordsAnnotation]
+	hashes := make(map[string]string, len(builtinUsers))
+	for _, user := range builtinUsers {
+		secret := &corev1.Secret{}
+		err := r.Get(r.ctx, client.ObjectKey{Name: helpers.GeneratedCredentialsSecretName(r.instance, user.name), Namespace: r.instance.Namespace}, secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, err
+		}
+
+		if apierrors.IsNotFound(err) {
+			// There is no password to keep working, the secret is created with the new password right away
+			password, hash, err := generatePassword()
+			if err != nil {
+				return nil, err
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        helpers.GeneratedCredentialsSecretName(r.instance, user.name),
+					Namespace:   r.instance.Namespace,
+					Annotations: map[string]string{passwordRotationAnnotation: rotation},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{
+					"username": []byte(user.name),
+					"password": []byte(password),
+					"hash":     []byte(hash),
+				},
+			}
+			if err := ctrl.SetControllerReference(r.instance, secret, r.Client.Scheme()); err != nil {
+				return nil, err
+			}
+			if err := r.Create(r.ctx, secret); err != nil {
+				return nil, err
+			}
+			hashes[user.name] = hash
+			continue
+		}
+
+		if secret.Annotations[passwordRotationAnnotation] == rotation && len(secret.Data["hash"]) > 0 {
+			if _, ok := secret.Data[pendingPasswordKey]; ok {
+				// The rotation was reverted before the pending password was applied
+				clearPendingPassword(secret)
+				if err := r.Update(r.ctx, secret); err != nil {
+					return nil, err
+				}
+			}
+			hashes[user.name] = string(secret.Data["hash"])
+			continue
+		}
+		if secret.Annotations[pendingRotationAnnotation] == rotation && len(secret.Data[pendingHashKey]) > 0 {
+			hashes[user.name] = string(secret.Data[pendingHashKey])
+			continue
+		}
+
+		password, hash, err := generatePassword()
+		if err != nil {
+			return nil, err
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Annotations[pendingRotationAnnotation] = rotation
+		secret.Data[pendingPasswordKey] = []byte(password)
+		secret.Data[pendingHashKey] = []byte(hash)
+		if err := r.Update(r.ctx, secret); err != nil {
+			return nil, err
+		}
+		r.logger.Info("Generated new password", "user", user.name)
+		hashes[user.name] = hash
+	}
+	return hashes, nil
+}
+
+// activatePendingPasswords replaces the passwords of the credentials secrets with their pending passwords. It must
+// only be called once the securityconfig with the hashes of the pending passwords has been applied.
+func (r *SecurityconfigReconciler) activatePendingPasswords() error {
+	for _, user := range builtinUsers {
+		secret := &corev1.Secret{}
+		if err := r.Get(r.ctx, client.ObjectKey{Name: helpers.GeneratedCredentialsSecretName(r.instance, user.name), Namespace: r.instance.Namespace}, secret); err != nil {
+			return err
+		}
+		password, ok := secret.Data[pendingPasswordKey]
+		if !ok {
+			continue
+		}
+		secret.Data["password"] = password
+		secret.Data["hash"] = secret.Data[pendingHashKey]
+		secret.Annotations[passwordRotationAnnotation] = secret.Annotations[pendingRotationAnnotation]
+		clearPendingPassword(secret)
+		if err := r.Update(r.ctx, secret); err != nil {
+			return err
+		}
+		r.logger.Info("Rotated generated password", "user", user.name)
+		r.recorder.AnnotatedEventf(r.instance, map[string]string{"cluster-name": r.instance.GetName()}, "Normal", "Security", "Rotated the password of the %s user", user.name)
+	}
+	return nil
+}
+
+func clearPendingPassword(secret *corev1.Secret) {
+	delete(secret.Data, pendingPasswordKey)
+	delete(secret.Data, pendingHashKey)
+	delete(secret.Annotations, pendingRotationAnnotation)
+}
+
+// generatePassword returns a random password and its bcrypt hash as used by the internal users of the security plugin
+func generatePassword() (string, string, error) {
+	password := make([]byte, generatedPasswordLength)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordCharacters))))
+		if err != nil {
+			return "", "", err
+		}
+		password[i] = passwordCharacters[n.Int64()]
+	}
+	hash, err := bcrypt.GenerateFromPassword(password, passwordHashCost)
+	if err != nil {
+		return "", "", err
+	}
+	return string(password), string(hash), nil
+}
+
+// mergeInternalUsers sets the password hashes of the built-in users in the internal_users.yml of the securityconfig.
+// Other users and the other fields of the built-in users are kept.
+func mergeInternalUsers(existing []byte, hashes map[string]string) ([]byte, error) {
+	users := map[string]interface{}{}
+	if err := yaml.Unmarshal(existing, &users); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", internalUsersYml, err)
+	}
+	if users == nil {
+		users = map[string]interface{}{}
+	}
+	if _, ok := users["_meta"]; !ok {
+		users["_meta"] = map[string]interface{}{"type": "internalusers", "config_version": 2}
+	}
+	for _, user := range builtinUsers {
+		hash, ok := hashes[user.name]
+		if !ok {
+			continue
+		}
+		entry, ok := users[user.name].(map[string]interface{})
+		if !ok {
+			entry = map[string]interface{}{"reserved": true, "description": user.description}
+			if len(user.backendRoles) > 0 {
+				entry["backend_roles"] = user.backendRoles
+			}
+		}
+		entry["hash"] = hash
+		users[user.name] = entry
+	}
+	return yaml.Marshal(users)
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"time"
 
+	"golang.org/x/crypto/bcrypt"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"opensearch.opster.io/pkg/helpers"
@@ -156,4 +158,372 @@ var _ = Describe("Securityconfig Reconciler", func() {
 
 		})
 	})
+
+	When("When Reconciling the securityconfig reconciler with authentication configured", func() {
+		It("should merge the authentication backends into the config.yml", func() {
+			var clusterName = "securityconfig-authentication"
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			configSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "securityconfig", Namespace: clusterName},
+				StringData: map[string]string{
+					"config.yml":  "config:\n  dynamic:\n    http:\n      anonymous_auth_enabled: false\n",
+					"tenants.yml": "foobar",
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), &configSecret)).To(Succeed())
+
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName},
+				Spec: opsterv1.ClusterSpec{
+					General:   opsterv1.GeneralConfig{ServiceName: clusterName},
+					NodePools: []opsterv1.NodePool{{Component: "masters", Replicas: 3, Roles: []string{"master"}}},
+					Security: &opsterv1.Security{
+						Config: &opsterv1.SecurityConfig{
+							SecurityconfigSecret: corev1.LocalObjectReference{Name: "securityconfig"},
+							AdminSecret:          corev1.LocalObjectReference{Name: "admin-cert"},
+						},
+						Authentication: &opsterv1.AuthenticationConfig{
+							OIDC: &opsterv1.OIDCAuthConfig{
+								ConnectURL: "https://idp.example.com/.well-known/openid-configuration",
+								ClientID:   "dashboards",
+								RolesKey:   "groups",
+							},
+						},
+					},
+				}}
+			// The reconciler reports the progress in the status of the cluster
+			Expect(k8sClient.Create(context.Background(), &spec)).To(Succeed())
+
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+			_, err := underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			generated := corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-generated", Namespace: clusterName}, &generated)).To(Succeed())
+			Expect(string(generated.Data["tenants.yml"])).To(Equal("foobar"))
+			config := string(generated.Data["config.yml"])
+			Expect(config).To(ContainSubstring("anonymous_auth_enabled: false"))
+			Expect(config).To(ContainSubstring("basic_internal_auth_domain:"))
+			Expect(config).To(ContainSubstring("openid_connect_url: https://idp.example.com/.well-known/openid-configuration"))
+			Expect(config).To(ContainSubstring("roles_key: groups"))
+			Expect(helpers.CheckVolumeExists(reconcilerContext.Volumes, reconcilerContext.VolumeMounts, generated.Name, "securityconfig")).To(BeTrue())
+		})
+	})
+
+	When("When Reconciling the securityconfig reconciler and the update fails", func() {
+		It("should report the failure and retry the update after a backoff", func() {
+			var clusterName = "securityconfig-failedupdate"
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			adminCertSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "admin-cert", Namespace: clusterName},
+				StringData: map[string]string{"tls.crt": "foobar", "tls.key": "foobar"},
+			}
+			Expect(k8sClient.Create(context.Background(), &adminCertSecret)).To(Succeed())
+			configSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "securityconfig", Namespace: clusterName},
+				StringData: map[string]string{"config.yml": "config:\n  dynamic: {}\n"},
+			}
+			Expect(k8sClient.Create(context.Background(), &configSecret)).To(Succeed())
+
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName},
+				Spec: opsterv1.ClusterSpec{
+					General:   opsterv1.GeneralConfig{ServiceName: clusterName},
+					NodePools: []opsterv1.NodePool{{Component: "masters", Replicas: 3, Roles: []string{"master"}}},
+					Security: &opsterv1.Security{
+						Config: &opsterv1.SecurityConfig{
+							SecurityconfigSecret: corev1.LocalObjectReference{Name: "securityconfig"},
+							AdminSecret:          corev1.LocalObjectReference{Name: "admin-cert"},
+						},
+					},
+				}}
+			Expect(k8sClient.Create(context.Background(), &spec)).To(Succeed())
+			spec.Status.Initialized = true
+			Expect(k8sClient.Status().Update(context.Background(), &spec)).To(Succeed())
+
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+			result, err := underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			// A failed update must not block the other reconcilers
+			Expect(result.IsZero()).To(BeTrue())
+
+			condition := meta.FindStatusCondition(spec.Status.Conditions, opsterv1.ConditionSecurityconfigApplied)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("Failed"))
+
+			state := corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-applied", Namespace: clusterName}, &state)).To(Succeed())
+			Expect(state.Annotations).To(HaveKeyWithValue(attemptAnnotation, "1"))
+
+			// The next attempt waits for the backoff
+			_, err = underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-applied", Namespace: clusterName}, &state)).To(Succeed())
+			Expect(state.Annotations).To(HaveKeyWithValue(attemptAnnotation, "1"))
+		})
+
+		It("should increase the backoff with every attempt", func() {
+			Expect(securityconfigBackoff(0)).To(Equal(30 * time.Second))
+			Expect(securityconfigBackoff(2)).To(Equal(2 * time.Minute))
+			Expect(securityconfigBackoff(20)).To(Equal(30 * time.Minute))
+		})
+
+		It("should convert the securityconfig files to the documents of the security index", func() {
+			configs, err := securityconfigDocuments(map[string][]byte{
+				"config.yml":         []byte("config:\n  dynamic: {}\n"),
+				"internal_users.yml": []byte("admin:\n  hash: foo\n"),
+				"README.md":          []byte("ignored"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(configs).To(HaveLen(2))
+			Expect(string(configs["internalusers"])).To(Equal(`{"admin":{"hash":"foo"}}`))
+		})
+	})
+
+	When("When Reconciling the securityconfig reconciler after an upgrade of the operator", func() {
+		It("should record the securityconfig applied by the old update job instead of uploading it again", func() {
+			var clusterName = "securityconfig-migratejob"
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			configData := map[string][]byte{"config.yml": []byte("config:\n  dynamic: {}\n")}
+			checksumval, err := checksum(configData)
+			Expect(err).ToNot(HaveOccurred())
+			configSecret := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "securityconfig", Namespace: clusterName},
+				Data:       configData,
+			}
+			Expect(k8sClient.Create(context.Background(), &configSecret)).To(Succeed())
+			job := batchv1.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        clusterName + "-securityconfig-update",
+					Namespace:   clusterName,
+					Annotations: map[string]string{checksumAnnotation: checksumval},
+				},
+				Spec: batchv1.JobSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy: corev1.RestartPolicyNever,
+							Containers:    []corev1.Container{{Name: "updater", Image: "opensearch"}},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(context.Background(), &job)).To(Succeed())
+			job.Status.Succeeded = 1
+			Expect(k8sClient.Status().Update(context.Background(), &job)).To(Succeed())
+			Eventually(func() int32 {
+				current := batchv1.Job{}
+				if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&job), &current); err != nil {
+					return 0
+				}
+				return current.Status.Succeeded
+			}, timeout, interval).Should(Equal(int32(1)))
+
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName},
+				Spec: opsterv1.ClusterSpec{
+					General:   opsterv1.GeneralConfig{ServiceName: clusterName},
+					NodePools: []opsterv1.NodePool{{Component: "masters", Replicas: 3, Roles: []string{"master"}}},
+					Security: &opsterv1.Security{
+						Config: &opsterv1.SecurityConfig{
+							SecurityconfigSecret: corev1.LocalObjectReference{Name: "securityconfig"},
+							AdminSecret:          corev1.LocalObjectReference{Name: "admin-cert"},
+						},
+					},
+				}}
+			Expect(k8sClient.Create(context.Background(), &spec)).To(Succeed())
+
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+			// Uploading would fail, the admin certificate doesn't exist
+			_, err = underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			condition := meta.FindStatusCondition(spec.Status.Conditions, opsterv1.ConditionSecurityconfigApplied)
+			Expect(condition).ToNot(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+
+			state := corev1.Secret{}
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-securityconfig-applied", Namespace: clusterName}, &state)
+			}, timeout, interval).Should(Succeed())
+			Expect(state.Annotations).To(HaveKeyWithValue(checksumAnnotation, checksumval))
+			Expect(state.Data).To(Equal(configData))
+		})
+	})
+
+	When("When rendering the authentication config with basic auth disabled", func() {
+		It("should keep the internal users domain for the operator without a login prompt", func() {
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "securityconfig-nobasic", Namespace: "securityconfig-nobasic"},
+				Spec: opsterv1.ClusterSpec{
+					Security: &opsterv1.Security{
+						Authentication: &opsterv1.AuthenticationConfig{
+							Basic: &opsterv1.BasicAuthConfig{Enabled: false},
+							OIDC:  &opsterv1.OIDCAuthConfig{ConnectURL: "https://idp.example.com/.well-known/openid-configuration"},
+						},
+					},
+				}}
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigReconciler(
+				k8sClient,
+				context.Background(),
+				&helpers.MockEventRecorder{},
+				&reconcilerContext,
+				&spec,
+			)
+			config, err := underTest.authenticationConfig()
+			Expect(err).ToNot(HaveOccurred())
+			authc := config["config"].(map[string]interface{})["dynamic"].(map[string]interface{})["authc"].(map[string]interface{})
+			Expect(authc).To(HaveKey("basic_internal_auth_domain"))
+			basic := authc["basic_internal_auth_domain"].(map[string]interface{})
+			Expect(basic["http_enabled"]).To(BeTrue())
+			Expect(basic["http_authenticator"]).To(HaveKeyWithValue("challenge", false))
+		})
+	})
+
+	When("When generating the passwords of the built-in users", func() {
+		It("should generate a random password with a matching hash", func() {
+			password, hash, err := generatePassword()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(password).To(HaveLen(generatedPasswordLength))
+			Expect(bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))).To(Succeed())
+			other, _, err := generatePassword()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(other).ToNot(Equal(password))
+		})
+
+		It("should only hand out a rotated password after its hash was applied", func() {
+			var clusterName = "securityconfig-passwords"
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "passwords", Namespace: clusterName, UID: "dummyuid"},
+				Spec: opsterv1.ClusterSpec{
+					Security: &opsterv1.Security{Config: &opsterv1.SecurityConfig{GeneratePasswords: true}},
+				},
+			}
+			reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+			underTest := NewSecurityconfigReconciler(k8sClient, context.Background(), &helpers.MockEventRecorder{}, &reconcilerContext, &spec)
+			key := client.ObjectKey{Name: helpers.GeneratedCredentialsSecretName(&spec, "admin"), Namespace: clusterName}
+
+			hashes, err := underTest.reconcileGeneratedPasswords()
+			Expect(err).ToNot(HaveOccurred())
+			initial := corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), key, &initial)).To(Succeed())
+			Expect(string(initial.Data["hash"])).To(Equal(hashes["admin"]))
+
+			spec.Annotations = map[string]string{opsterv1.RotatePasswordsAnnotation: "1"}
+			hashes, err = underTest.reconcileGeneratedPasswords()
+			Expect(err).ToNot(HaveOccurred())
+			rotated := corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), key, &rotated)).To(Succeed())
+			Expect(rotated.Data["password"]).To(Equal(initial.Data["password"]))
+			Expect(string(rotated.Data[pendingHashKey])).To(Equal(hashes["admin"]))
+
+			Expect(underTest.activatePendingPasswords()).To(Succeed())
+			Expect(k8sClient.Get(context.Background(), key, &rotated)).To(Succeed())
+			Expect(rotated.Data["password"]).ToNot(Equal(initial.Data["password"]))
+			Expect(string(rotated.Data["hash"])).To(Equal(hashes["admin"]))
+			Expect(rotated.Data).ToNot(HaveKey(pendingPasswordKey))
+			Expect(rotated.Annotations).To(HaveKeyWithValue(passwordRotationAnnotation, "1"))
+		})
+
+		It("should merge the hashes into the internal users", func() {
+			existing := []byte("_meta:\n  type: internalusers\n  config_version: 2\nadmin:\n  hash: foo\n  reserved: true\n  backend_roles: [admin]\nreader:\n  hash: bar\n")
+			merged, err := mergeInternalUsers(existing, map[string]string{"admin": "newadmin", "kibanaserver": "newkibanaserver"})
+			Expect(err).ToNot(HaveOccurred())
+			users, err := securityconfigEntries(merged)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(users).To(HaveKeyWithValue("admin", map[string]interface{}{"hash": "newadmin", "backend_roles": []interface{}{"admin"}}))
+			Expect(users).To(HaveKeyWithValue("reader", map[string]interface{}{"hash": "bar"}))
+			Expect(users).To(HaveKey("kibanaserver"))
+			Expect(users["kibanaserver"]).To(HaveKeyWithValue("hash", "newkibanaserver"))
+		})
+
+		It("should only initialize the security index with a complete securityconfig", func() {
+			Expect(completeSecurityconfig(map[string][]byte{"internal_users.yml": nil})).To(BeFalse())
+			Expect(completeSecurityconfig(map[string][]byte{
+				"config.yml": nil, "internal_users.yml": nil, "roles.yml": nil, "roles_mapping.yml": nil, "action_groups.yml": nil, "tenants.yml": nil,
+			})).To(BeTrue())
+		})
+	})
+
+	When("When calculating the incremental changes of the securityconfig", func() {
+		applied := map[string][]byte{
+			"config.yml":         []byte("config:\n  dynamic:\n    http:\n      anonymous_auth_enabled: false\n"),
+			"internal_users.yml": []byte("_meta:\n  type: internalusers\n  config_version: 2\nadmin:\n  hash: foo\n  reserved: true\n"),
+			"roles.yml":          []byte("_meta:\n  type: roles\n  config_version: 2\nreader:\n  cluster_permissions: [cluster_monitor]\nwriter:\n  cluster_permissions: [cluster_all]\n"),
+		}
+
+		It("should only apply the changed entries", func() {
+			current := map[string][]byte{
+				"config.yml":         applied["config.yml"],
+				"internal_users.yml": applied["internal_users.yml"],
+				"roles.yml":          []byte("_meta:\n  type: roles\n  config_version: 2\nreader:\n  cluster_permissions: [cluster_monitor]\nadmin:\n  cluster_permissions: [cluster_all]\n"),
+			}
+			changes, uploads, err := securityconfigChanges(applied, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+			Expect(changes).To(Equal([]securityconfigChange{
+				{configType: "roles", name: "admin", entry: map[string]interface{}{"cluster_permissions": []interface{}{"cluster_all"}}},
+				{configType: "roles", name: "writer"},
+			}))
+		})
+
+		It("should apply the config section of config.yml", func() {
+			current := map[string][]byte{
+				"config.yml":         []byte("config:\n  dynamic:\n    http:\n      anonymous_auth_enabled: true\n"),
+				"internal_users.yml": applied["internal_users.yml"],
+				"roles.yml":          applied["roles.yml"],
+			}
+			changes, uploads, err := securityconfigChanges(applied, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+			Expect(changes).To(HaveLen(1))
+			Expect(changes[0].configType).To(Equal("securityconfig"))
+		})
+
+		It("should upload an added audit.yml without touching the internal users", func() {
+			current := map[string][]byte{
+				"config.yml":         applied["config.yml"],
+				"internal_users.yml": applied["internal_users.yml"],
+				"roles.yml":          []byte("_meta:\n  type: roles\n  config_version: 2\nreader:\n  cluster_permissions: [cluster_monitor]\n"),
+				"audit.yml":          []byte("_meta:\n  type: audit\n"),
+			}
+			changes, uploads, err := securityconfigChanges(applied, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(Equal(map[string][]byte{"audit.yml": current["audit.yml"]}))
+			Expect(changes).To(Equal([]securityconfigChange{{configType: "roles", name: "writer"}}))
+		})
+
+		It("should leave the documents of removed files untouched", func() {
+			current := map[string][]byte{
+				"config.yml":         applied["config.yml"],
+				"internal_users.yml": applied["internal_users.yml"],
+			}
+			changes, uploads, err := securityconfigChanges(applied, current)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(uploads).To(BeEmpty())
+			Expect(changes).To(BeEmpty())
+		})
+	})
 })
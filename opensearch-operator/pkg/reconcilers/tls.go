@@ -1,9 +1,11 @@
 package reconcilers
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/client-go/tools/record"
@@ -11,7 +13,9 @@ import (
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"opensearch.opster.io/pkg/builders"
 	"opensearch.opster.io/pkg/reconcilers/util"
@@ -193,11 +197,15 @@ func (r *TLSReconciler) handleTransportGenerateGlobal() error {
 	r.reconcilerContext.Volumes = append(r.reconcilerContext.Volumes, volume)
 	mount := corev1.VolumeMount{Name: "transport-cert", MountPath: "/usr/share/opensearch/config/tls-transport"}
 	r.reconcilerContext.VolumeMounts = append(r.reconcilerContext.VolumeMounts, mount)
+	nodesDn, trustedCas, err := r.handleTransportTrust(ca)
+	if err != nil {
+		return err
+	}
 	// Extend opensearch.yml
-	r.reconcilerContext.AddConfig("plugins.security.nodes_dn", fmt.Sprintf("[\"CN=%s,OU=%s\"]", clusterName, clusterName))
+	r.reconcilerContext.AddConfig("plugins.security.nodes_dn", nodesDn)
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemcert_filepath", fmt.Sprintf("tls-transport/%s", corev1.TLSCertKey))
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemkey_filepath", fmt.Sprintf("tls-transport/%s", corev1.TLSPrivateKeyKey))
-	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemtrustedcas_filepath", fmt.Sprintf("tls-transport/%s", CaCertKey))
+	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemtrustedcas_filepath", trustedCas)
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.enforce_hostname_verification", "false")
 	return nil
 }
@@ -240,6 +248,7 @@ func (r *TLSReconciler) handleTransportGeneratePerNode() error {
 			bootstrapPodName,
 			clusterName,
 			builders.DiscoveryServiceName(r.instance),
+			fmt.Sprintf("%s.%s.svc", builders.DiscoveryServiceName(r.instance), namespace),
 			fmt.Sprintf("%s.%s", bootstrapPodName, clusterName),
 			fmt.Sprintf("%s.%s", clusterName, namespace),
 			fmt.Sprintf("%s.%s.%s", bootstrapPodName, clusterName, namespace),
@@ -274,6 +283,7 @@ func (r *TLSReconciler) handleTransportGeneratePerNode() error {
 				podName,
 				clusterName,
 				builders.DiscoveryServiceName(r.instance),
+				fmt.Sprintf("%s.%s.svc", builders.DiscoveryServiceName(r.instance), namespace),
 				fmt.Sprintf("%s.%s", podName, clusterName),
 				fmt.Sprintf("%s.%s", clusterName, namespace),
 				fmt.Sprintf("%s.%s.%s", podName, clusterName, namespace),
@@ -311,15 +321,197 @@ func (r *TLSReconciler) handleTransportGeneratePerNode() error {
 	mount := corev1.VolumeMount{Name: "transport-cert", MountPath: "/usr/share/opensearch/config/tls-transport"}
 	r.reconcilerContext.VolumeMounts = append(r.reconcilerContext.VolumeMounts, mount)
 
+	nodesDn, trustedCas, err := r.handleTransportTrust(ca)
+	if err != nil {
+		return err
+	}
 	// Extend opensearch.yml
-	r.reconcilerContext.AddConfig("plugins.security.nodes_dn", fmt.Sprintf("[\"CN=%s-*,OU=%s\"]", clusterName, clusterName))
+	r.reconcilerContext.AddConfig("plugins.security.nodes_dn", nodesDn)
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemcert_filepath", "tls-transport/${HOSTNAME}.crt")
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemkey_filepath", "tls-transport/${HOSTNAME}.key")
-	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemtrustedcas_filepath", fmt.Sprintf("tls-transport/%s", CaCertKey))
+	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.pemtrustedcas_filepath", trustedCas)
 	r.reconcilerContext.AddConfig("plugins.security.ssl.transport.enforce_hostname_verification", "true")
 	return nil
 }
 
+// handleTransportTrust returns the nodes_dn and the trusted CAs file of the transport layer. Clusters replicating
+// from or to each other or connected as remote clusters have to trust the transport certificates of the other
+// cluster. If both use an operator generated CA the CAs of the peers are added to a trust bundle and their node
+// certificates to the nodes_dn.
+func (r *TLSReconciler) handleTransportTrust(ca tls.Cert) (string, string, error) {
+	nodesDn := []string{transportNodesDn(r.instance)}
+	trustedCas := fmt.Sprintf("tls-transport/%s", CaCertKey)
+	if r.instance.Spec.Security.Tls.Transport.TlsCertificateConfig.CaSecret.Name != "" {
+		return formatDnList(nodesDn), trustedCas, nil
+	}
+
+	peers, err := r.transportPeers()
+	if err != nil || len(peers) == 0 {
+		return formatDnList(nodesDn), trustedCas, err
+	}
+
+	bundle := append([]byte{}, ca.CertData()...)
+	for _, peer := range peers {
+		caSecret := corev1.Secret{}
+		if err := r.Get(r.ctx, client.ObjectKey{Name: peer.Name + "-ca", Namespace: peer.Namespace}, &caSecret); err != nil {
+			if k8serrors.IsNotFound(err) {
+				r.logger.Info("CA of transport peer does not exist yet", "peer", peer.Name)
+				continue
+			}
+			return "", "", err
+		}
+		bundle = append(bundle, caSecret.Data[CaCertKey]...)
+		nodesDn = append(nodesDn, transportNodesDn(peer))
+	}
+
+	trustSecretName := r.instance.Name + "-transport-trust"
+	trustSecret := corev1.Secret{}
+	if err := r.Get(r.ctx, client.ObjectKey{Name: trustSecretName, Namespace: r.instance.Namespace}, &trustSecret); err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return "", "", err
+		}
+		trustSecret = corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: trustSecretName, Namespace: r.instance.Namespace}, Data: map[string][]byte{CaCertKey: bundle}}
+		if err := ctrl.SetControllerReference(r.instance, &trustSecret, r.Client.Scheme()); err != nil {
+			return "", "", err
+		}
+		if err := r.Create(r.ctx, &trustSecret); err != nil {
+			r.logger.Error(err, "Failed to store transport trust bundle in secret")
+			return "", "", err
+		}
+	} else if !bytes.Equal(trustSecret.Data[CaCertKey], bundle) {
+		trustSecret.Data = map[string][]byte{CaCertKey: bundle}
+		if err := r.Update(r.ctx, &trustSecret); err != nil {
+			r.logger.Error(err, "Failed to store transport trust bundle in secret")
+			return "", "", err
+		}
+	}
+
+	volume := corev1.Volume{Name: "transport-trust", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: trustSecretName}}}
+	r.reconcilerContext.Volumes = append(r.reconcilerContext.Volumes, volume)
+	mount := corev1.VolumeMount{Name: "transport-trust", MountPath: "/usr/share/opensearch/config/tls-transport-trust"}
+	r.reconcilerContext.VolumeMounts = append(r.reconcilerContext.VolumeMounts, mount)
+	return formatDnList(nodesDn), fmt.Sprintf("tls-transport-trust/%s", CaCertKey), nil
+}
+
+// transportPeers returns the operator managed clusters this cluster replicates from or to or that are connected
+// to it as remote clusters in either direction, whose transport certificates are signed by an operator generated CA.
+// Remote clusters in other namespaces are only peers if the referenced cluster allows references from the namespace
+// of the other cluster, so clusters can't gain transport trust into clusters of other tenants.
+func (r *TLSReconciler) transportPeers() ([]*opsterv1.OpenSearchCluster, error) {
+	var names []types.NamespacedName
+	addPeer := func(name types.NamespacedName) {
+		if name == client.ObjectKeyFromObject(r.instance) {
+			return
+		}
+		for _, existing := range names {
+			if existing == name {
+				return
+			}
+		}
+		names = append(names, name)
+	}
+
+	rules := opsterv1.OpensearchReplicationRuleList{}
+	if err := r.List(r.ctx, &rules, client.InNamespace(r.instance.Namespace)); err != nil {
+		return nil, err
+	}
+	for _, rule := range rules.Items {
+		if rule.Spec.Leader.OpensearchRef == nil {
+			continue
+		}
+		follower := rule.Spec.OpensearchRef.Name
+		leader := rule.Spec.Leader.OpensearchRef.Name
+		switch r.instance.Name {
+		case follower:
+			addPeer(types.NamespacedName{Name: leader, Namespace: rule.Namespace})
+		case leader:
+			addPeer(types.NamespacedName{Name: follower, Namespace: rule.Namespace})
+		}
+	}
+
+	for _, remote := range r.instance.Spec.RemoteClusters {
+		if remote.OpensearchRef == nil {
+			continue
+		}
+		name := remote.OpensearchRef.NamespacedName(r.instance.Namespace)
+		if name.Namespace != r.instance.Namespace {
+			remoteCluster, err := util.FetchOpensearchCluster(r.ctx, r.Client, name)
+			if err != nil {
+				return nil, err
+			}
+			if remoteCluster == nil {
+				continue
+			}
+			allowed, err := util.ReferenceAllowed(r.ctx, r.Client, remoteCluster, r.instance.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				r.logger.Info("Remote cluster does not allow references from this namespace, not trusting it", "remote", name)
+				continue
+			}
+		}
+		addPeer(name)
+	}
+	clusters := opsterv1.OpenSearchClusterList{}
+	if err := r.List(r.ctx, &clusters); err != nil {
+		return nil, err
+	}
+	for _, cluster := range clusters.Items {
+		for _, remote := range cluster.Spec.RemoteClusters {
+			if remote.OpensearchRef == nil || remote.OpensearchRef.NamespacedName(cluster.Namespace) != client.ObjectKeyFromObject(r.instance) {
+				continue
+			}
+			allowed, err := util.ReferenceAllowed(r.ctx, r.Client, r.instance, cluster.Namespace)
+			if err != nil {
+				return nil, err
+			}
+			if !allowed {
+				r.logger.Info("Cluster references this cluster as remote cluster from a namespace that is not allowed, not trusting it", "cluster", client.ObjectKeyFromObject(&cluster))
+				continue
+			}
+			addPeer(client.ObjectKeyFromObject(&cluster))
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i].String() < names[j].String()
+	})
+
+	var peers []*opsterv1.OpenSearchCluster
+	for _, name := range names {
+		peer, err := util.FetchOpensearchCluster(r.ctx, r.Client, name)
+		if err != nil {
+			return nil, err
+		}
+		if peer == nil || !usesGeneratedTransportCa(peer) {
+			continue
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
+
+func usesGeneratedTransportCa(cluster *opsterv1.OpenSearchCluster) bool {
+	security := cluster.Spec.Security
+	if security == nil || security.Tls == nil || security.Tls.Transport == nil {
+		return false
+	}
+	return security.Tls.Transport.Generate && security.Tls.Transport.TlsCertificateConfig.CaSecret.Name == ""
+}
+
+// transportNodesDn returns the DN of the generated transport certificates of the cluster
+func transportNodesDn(cluster *opsterv1.OpenSearchCluster) string {
+	if cluster.Spec.Security.Tls.Transport.PerNode {
+		return fmt.Sprintf("CN=%s-*,OU=%s", cluster.Name, cluster.Name)
+	}
+	return fmt.Sprintf("CN=%s,OU=%s", cluster.Name, cluster.Name)
+}
+
+func formatDnList(dns []string) string {
+	return fmt.Sprintf("[\"%s\"]", strings.Join(dns, "\",\""))
+}
+
 func (r *TLSReconciler) handleTransportExistingCerts() error {
 	tlsConfig := r.instance.Spec.Security.Tls.Transport
 	if tlsConfig.PerNode {
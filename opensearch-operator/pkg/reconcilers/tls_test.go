@@ -304,4 +304,133 @@ var _ = Describe("TLS Controller", func() {
 		})
 	})
 
+	Context("When Reconciling the TLS configuration of a cluster with a replication peer", func() {
+		It("Should trust the transport certificates of the peer", func() {
+			clusterName := "tls-replication"
+			peerName := clusterName + "-leader"
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName, UID: "dummyuid"},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{},
+					Security: &opsterv1.Security{Tls: &opsterv1.TlsConfig{
+						Transport: &opsterv1.TlsConfigTransport{Generate: true},
+					}},
+				}}
+			peer := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: peerName, Namespace: clusterName},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{ServiceName: peerName},
+					NodePools: []opsterv1.NodePool{
+						{Component: "masters", Replicas: 3, Roles: []string{"master", "data"}},
+					},
+					Security: &opsterv1.Security{Tls: &opsterv1.TlsConfig{
+						Transport: &opsterv1.TlsConfigTransport{Generate: true, PerNode: true},
+					}},
+				}}
+			peerCa := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: peerName + "-ca", Namespace: clusterName},
+				Data: map[string][]byte{
+					"ca.crt": []byte("peer-ca.crt"),
+					"ca.key": []byte("peer-ca.key"),
+				},
+			}
+			rule := opsterv1.OpensearchReplicationRule{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName},
+				Spec: opsterv1.OpensearchReplicationRuleSpec{
+					OpensearchRef: corev1.LocalObjectReference{Name: clusterName},
+					Leader: opsterv1.ReplicationLeader{
+						Alias:         "leader",
+						OpensearchRef: &corev1.LocalObjectReference{Name: peerName},
+					},
+					Indices: []string{"movies"},
+				},
+			}
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			Expect(k8sClient.Create(context.Background(), &peer)).To(Succeed())
+			Expect(k8sClient.Create(context.Background(), &peerCa)).To(Succeed())
+			Expect(k8sClient.Create(context.Background(), &rule)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&rule), &opsterv1.OpensearchReplicationRule{})
+			}, timeout, interval).Should(Succeed())
+
+			reconcilerContext, underTest := newTLSReconciler(&spec)
+			_, err := underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(reconcilerContext.Volumes).Should(HaveLen(2))
+			Expect(reconcilerContext.VolumeMounts).Should(HaveLen(2))
+			Expect(helpers.CheckVolumeExists(reconcilerContext.Volumes, reconcilerContext.VolumeMounts, clusterName+"-transport-trust", "transport-trust")).Should((BeTrue()))
+
+			value, exists := reconcilerContext.OpenSearchConfig["plugins.security.nodes_dn"]
+			Expect(exists).To(BeTrue())
+			Expect(value).To(Equal("[\"CN=tls-replication,OU=tls-replication\",\"CN=tls-replication-leader-*,OU=tls-replication-leader\"]"))
+			value, exists = reconcilerContext.OpenSearchConfig["plugins.security.ssl.transport.pemtrustedcas_filepath"]
+			Expect(exists).To(BeTrue())
+			Expect(value).To(Equal("tls-transport-trust/ca.crt"))
+
+			trustSecret := corev1.Secret{}
+			Expect(k8sClient.Get(context.Background(), client.ObjectKey{Name: clusterName + "-transport-trust", Namespace: clusterName}, &trustSecret)).To(Succeed())
+			Expect(string(trustSecret.Data["ca.crt"])).To(HaveSuffix("peer-ca.crt"))
+		})
+	})
+
+	Context("When Reconciling the TLS configuration of a cluster referenced as remote cluster from another namespace", func() {
+		It("Should not trust the referencing cluster unless the namespace is allowed", func() {
+			clusterName := "tls-remote-refused"
+			otherName := clusterName + "-other"
+			spec := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: clusterName, UID: "dummyuid"},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{},
+					Security: &opsterv1.Security{Tls: &opsterv1.TlsConfig{
+						Transport: &opsterv1.TlsConfigTransport{Generate: true},
+					}},
+				}}
+			other := opsterv1.OpenSearchCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: otherName, Namespace: otherName},
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{ServiceName: otherName},
+					NodePools: []opsterv1.NodePool{
+						{Component: "masters", Replicas: 3, Roles: []string{"master", "data"}},
+					},
+					Security: &opsterv1.Security{Tls: &opsterv1.TlsConfig{
+						Transport: &opsterv1.TlsConfigTransport{Generate: true},
+					}},
+					RemoteClusters: []opsterv1.RemoteCluster{
+						{Alias: "victim", OpensearchRef: &opsterv1.ClusterReference{Name: clusterName, Namespace: clusterName}},
+					},
+				}}
+			otherCa := corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: otherName + "-ca", Namespace: otherName},
+				Data: map[string][]byte{
+					"ca.crt": []byte("other-ca.crt"),
+					"ca.key": []byte("other-ca.key"),
+				},
+			}
+			Expect(CreateNamespace(k8sClient, clusterName)).Should(Succeed())
+			Expect(CreateNamespace(k8sClient, otherName)).Should(Succeed())
+			Expect(k8sClient.Create(context.Background(), &other)).To(Succeed())
+			Expect(k8sClient.Create(context.Background(), &otherCa)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(context.Background(), client.ObjectKeyFromObject(&other), &opsterv1.OpenSearchCluster{})
+			}, timeout, interval).Should(Succeed())
+
+			reconcilerContext, underTest := newTLSReconciler(&spec)
+			_, err := underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			value, exists := reconcilerContext.OpenSearchConfig["plugins.security.nodes_dn"]
+			Expect(exists).To(BeTrue())
+			Expect(value).To(Equal("[\"CN=tls-remote-refused,OU=tls-remote-refused\"]"))
+			Expect(reconcilerContext.OpenSearchConfig["plugins.security.ssl.transport.pemtrustedcas_filepath"]).To(Equal("tls-transport/ca.crt"))
+
+			// Trusted once the cluster allows references from the namespace
+			spec.Spec.CrossNamespaceReferences = &opsterv1.CrossNamespaceReferences{Namespaces: []string{otherName}}
+			reconcilerContext, underTest = newTLSReconciler(&spec)
+			_, err = underTest.Reconcile()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(reconcilerContext.OpenSearchConfig["plugins.security.nodes_dn"]).To(ContainSubstring("CN=tls-remote-refused-other,OU=tls-remote-refused-other"))
+			Expect(reconcilerContext.OpenSearchConfig["plugins.security.ssl.transport.pemtrustedcas_filepath"]).To(Equal("tls-transport-trust/ca.crt"))
+		})
+	})
+
 })
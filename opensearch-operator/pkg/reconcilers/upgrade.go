@@ -4,16 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Masterminds/semver"
 	"github.com/banzaicloud/operator-tools/pkg/reconciler"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
+	"k8s.io/utils/pointer"
 	opsterv1 "opensearch.opster.io/api/v1"
 	"opensearch.opster.io/opensearch-gateway/services"
 	"opensearch.opster.io/pkg/builders"
@@ -24,6 +28,12 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+const (
+	allocationAwarenessSetting = "cluster.routing.allocation.awareness.attributes"
+	defaultUpgradeTimeout      = 15 * time.Minute
+	maxUpgradeHistory          = 10
+)
+
 var (
 	ErrVersionDowngrade = errors.New("version requested is downgrade")
 	ErrMajorVersionJump = errors.New("version request is more than 1 major version ahead")
@@ -62,7 +72,11 @@ func NewUpgradeReconciler(
 func (r *UpgradeReconciler) Reconcile() (ctrl.Result, error) {
 	// If versions are in sync do nothing
 	if r.instance.Spec.General.Version == r.instance.Status.Version {
-		return ctrl.Result{}, nil
+		// If an upgrade was started the version has been reverted
+		if upgradeStarted(r.instance) {
+			return r.reconcileRollback()
+		}
+		return r.reconcileVersionUpdate()
 	}
 	annotations := map[string]string{"cluster-name": r.instance.GetName()}
 
@@ -75,6 +89,45 @@ func (r *UpgradeReconciler) Reconcile() (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
+	// Don't continue a failed upgrade unless a different version is requested
+	if failedPool, found := r.findFailedPool(); found {
+		latest := latestUpgrade(r.instance.Status)
+		if latest != nil && latest.ToVersion == r.instance.Spec.General.Version {
+			lg.V(1).Info("upgrade failed", "nodePool", failedPool, "requestedVersion", r.instance.Spec.General.Version)
+			return ctrl.Result{}, nil
+		}
+		if err := r.retryFailedPool(failedPool); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Do nothing while the upgrade is paused
+	if r.instance.Annotations[opsterv1.UpgradePauseAnnotation] == "true" {
+		lg.V(1).Info("upgrade paused", "requestedVersion", r.instance.Spec.General.Version)
+		err := UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgrading,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Paused",
+			Message: fmt.Sprintf("Upgrade to %s is paused", r.instance.Spec.General.Version),
+		})
+		return ctrl.Result{}, err
+	}
+
+	// The node pools are not rolled out while their plugins don't match the new version
+	if plugins := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionPluginsCompatible); plugins != nil && plugins.Status == metav1.ConditionFalse {
+		lg.V(1).Info("waiting for compatible plugins", "requestedVersion", r.instance.Spec.General.Version)
+		err := UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgrading,
+			Status:  metav1.ConditionTrue,
+			Reason:  "IncompatiblePlugins",
+			Message: fmt.Sprintf("Upgrade to %s is waiting for compatible plugins: %s", r.instance.Spec.General.Version, plugins.Message),
+		})
+		return ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 30 * time.Second,
+		}, err
+	}
+
 	var err error
 
 	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.instance, nil)
@@ -82,6 +135,29 @@ func (r *UpgradeReconciler) Reconcile() (ctrl.Result, error) {
 		return ctrl.Result{}, err
 	}
 
+	// Run the pre-flight checks before the first node pool is upgraded
+	if !upgradeStarted(r.instance) {
+		passed, err := r.reconcilePreflightChecks()
+		if err != nil || !passed {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 30 * time.Second,
+			}, err
+		}
+		if err := r.startUpgradeHistory(); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err := UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+		Type:    opsterv1.ConditionUpgrading,
+		Status:  metav1.ConditionTrue,
+		Reason:  "InProgress",
+		Message: fmt.Sprintf("Upgrading to %s", r.instance.Spec.General.Version),
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	//Fetch the working nodepool
 	nodePool, currentStatus := r.findWorkingNodePool()
 
@@ -97,28 +173,13 @@ func (r *UpgradeReconciler) Reconcile() (ctrl.Result, error) {
 			r.instance.Status.ComponentsStatus = append(r.instance.Status.ComponentsStatus, currentStatus)
 			return r.Status().Update(r.ctx, r.instance)
 		})
-		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Start to upgrade of data node pool %s", currentStatus.Component)
-		return ctrl.Result{
-			Requeue:      true,
-			RequeueAfter: 15 * time.Second,
-		}, err
-	case "NonDataPending":
-		// Set it to upgrading and requeue
-		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-			if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
-				return err
-			}
-			currentStatus.Status = "UntrackedUpgrade"
-			r.instance.Status.ComponentsStatus = append(r.instance.Status.ComponentsStatus, currentStatus)
-			return r.Status().Update(r.ctx, r.instance)
-		})
-		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Start to rollout of non data node pool %s", currentStatus.Component)
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Start to upgrade of node pool %s", currentStatus.Description)
 		return ctrl.Result{
 			Requeue:      true,
 			RequeueAfter: 15 * time.Second,
 		}, err
 	case "Upgrading":
-		err := r.doDataNodeUpgrade(nodePool)
+		err := r.doNodeUpgrade(nodePool)
 		return ctrl.Result{
 			Requeue:      true,
 			RequeueAfter: 30 * time.Second,
@@ -140,6 +201,15 @@ func (r *UpgradeReconciler) Reconcile() (ctrl.Result, error) {
 					r.instance.Status.ComponentsStatus = helpers.RemoveIt(currentStatus, r.instance.Status.ComponentsStatus)
 				}
 			}
+			finishLatestUpgrade(&r.instance.Status, opsterv1.UpgradeSucceeded, "")
+			removeDeferredActions(&r.instance.Status, deferredActionType(opsterv1.DeferredUpgrade))
+			meta.RemoveStatusCondition(&r.instance.Status.Conditions, opsterv1.ConditionUpgradeCanary)
+			meta.SetStatusCondition(&r.instance.Status.Conditions, metav1.Condition{
+				Type:    opsterv1.ConditionUpgrading,
+				Status:  metav1.ConditionFalse,
+				Reason:  "Completed",
+				Message: fmt.Sprintf("Upgraded to %s", r.instance.Spec.General.Version),
+			})
 			return r.Status().Update(r.ctx, r.instance)
 		})
 		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Finished to upgrade - NewVersion: %s", r.instance.Status.Version)
@@ -186,71 +256,105 @@ func (r *UpgradeReconciler) validateUpgrade() error {
 	return nil
 }
 
+// upgradeStarted returns true if any node pool of the cluster is being or has been upgraded
+func upgradeStarted(instance *opsterv1.OpenSearchCluster) bool {
+	for _, status := range instance.Status.ComponentsStatus {
+		if status.Component == "Upgrader" {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcilePreflightChecks runs the pre-flight checks and reports the result in the
+// UpgradePreflight condition
+func (r *UpgradeReconciler) reconcilePreflightChecks() (bool, error) {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	failures, err := r.preflightChecks(r.instance.Spec.General.Version)
+	if err != nil {
+		return false, err
+	}
+
+	if len(failures) > 0 {
+		message := fmt.Sprintf("Upgrade to %s blocked: %s", r.instance.Spec.General.Version, strings.Join(failures, "; "))
+		existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionUpgradePreflight)
+		if existing == nil || existing.Message != message {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Upgrade", "%s", message)
+		}
+		return false, UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgradePreflight,
+			Status:  metav1.ConditionFalse,
+			Reason:  "ChecksFailed",
+			Message: message,
+		})
+	}
+
+	return true, UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+		Type:    opsterv1.ConditionUpgradePreflight,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ChecksPassed",
+		Message: fmt.Sprintf("Pre-flight checks passed for version %s", r.instance.Spec.General.Version),
+	})
+}
+
 // Find which nodepool to work on
 func (r *UpgradeReconciler) findWorkingNodePool() (opsterv1.NodePool, opsterv1.ComponentStatus) {
 	// First sort node pools
-	var dataNodes, dataAndMasterNodes, otherNodes []opsterv1.NodePool
-	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	var orderedNodes, dataNodes, dataAndMasterNodes, masterNodes, otherNodes []opsterv1.NodePool
+	var poolOrder []string
+	if r.instance.Spec.UpgradeStrategy != nil {
+		poolOrder = r.instance.Spec.UpgradeStrategy.PoolOrder
+	}
+	for _, component := range poolOrder {
+		for _, nodePool := range r.instance.Spec.NodePools {
+			if nodePool.Component == component {
+				orderedNodes = append(orderedNodes, nodePool)
+			}
+		}
+	}
 	for _, nodePool := range r.instance.Spec.NodePools {
+		if helpers.ContainsString(poolOrder, nodePool.Component) {
+			continue
+		}
+		master := helpers.ContainsString(nodePool.Roles, "master") || helpers.ContainsString(nodePool.Roles, "cluster_manager")
 		if helpers.ContainsString(nodePool.Roles, "data") {
-			if helpers.ContainsString(nodePool.Roles, "master") {
+			if master {
 				dataAndMasterNodes = append(dataAndMasterNodes, nodePool)
 			} else {
 				dataNodes = append(dataNodes, nodePool)
 			}
+		} else if master {
+			masterNodes = append(masterNodes, nodePool)
 		} else {
 			otherNodes = append(otherNodes, nodePool)
 		}
 	}
 
-	// First work on data only nodes
-	// Complete the in progress node first
-	pool, found := r.findInProgress(dataNodes)
-	if found {
-		return pool, opsterv1.ComponentStatus{
-			Component:   "Upgrader",
-			Description: pool.Component,
-			Status:      "Upgrading",
-		}
-	}
-	// Pick the first unworked on node next
-	pool, found = r.findNextPool(dataNodes)
-	if found {
-		return pool, opsterv1.ComponentStatus{
-			Component:   "Upgrader",
-			Description: pool.Component,
-			Status:      "Pending",
-		}
-	}
-	// Next do the same for any nodes that are data and master
-	pool, found = r.findInProgress(dataAndMasterNodes)
-	if found {
-		return pool, opsterv1.ComponentStatus{
-			Component:   "Upgrader",
-			Description: pool.Component,
-			Status:      "Upgrading",
-		}
-	}
-	pool, found = r.findNextPool(dataAndMasterNodes)
-	if found {
-		return pool, opsterv1.ComponentStatus{
-			Component:   "Upgrader",
-			Description: pool.Component,
-			Status:      "Pending",
+	// Work on the explicitly ordered node pools first, then on data only nodes,
+	// then on coordinating and ingest nodes and finally on cluster manager nodes
+	for _, pools := range [][]opsterv1.NodePool{orderedNodes, dataNodes, otherNodes, dataAndMasterNodes, masterNodes} {
+		// Complete the in progress node first
+		pool, found := r.findInProgress(pools)
+		if found {
+			return pool, opsterv1.ComponentStatus{
+				Component:   "Upgrader",
+				Description: pool.Component,
+				Status:      "Upgrading",
+			}
 		}
-	}
-
-	// Finally do the non data nodes.  We can let the kubernetes rollout logic do the work here
-	pool, found = r.findNextPool(otherNodes)
-	if found {
-		return pool, opsterv1.ComponentStatus{
-			Component:   "Upgrader",
-			Description: pool.Component,
-			Status:      "NonDataPending",
+		// Pick the first unworked on node next
+		pool, found = r.findNextPool(pools)
+		if found {
+			return pool, opsterv1.ComponentStatus{
+				Component:   "Upgrader",
+				Description: pool.Component,
+				Status:      "Pending",
+			}
 		}
 	}
 
 	// If we get here all nodes should be upgraded
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
 	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Finished to upgrade - NewVersion: %s", r.instance.Status.Version)
 	return opsterv1.NodePool{}, opsterv1.ComponentStatus{
 		Component: "Upgrade",
@@ -286,7 +390,7 @@ func (r *UpgradeReconciler) findNextPool(pools []opsterv1.NodePool) (opsterv1.No
 	return opsterv1.NodePool{}, false
 }
 
-func (r *UpgradeReconciler) doDataNodeUpgrade(pool opsterv1.NodePool) error {
+func (r *UpgradeReconciler) doNodeUpgrade(pool opsterv1.NodePool) error {
 	// Fetch the STS
 	lg := log.FromContext(r.ctx).WithValues("reconciler", "upgrader")
 	stsName := builders.StsName(r.instance, &pool)
@@ -298,9 +402,26 @@ func (r *UpgradeReconciler) doDataNodeUpgrade(pool opsterv1.NodePool) error {
 	}, sts); err != nil {
 		return err
 	}
+	dataNode := helpers.ContainsString(pool.Roles, "data")
+
+	// Wait for the previously upgraded node to be ready and to rejoin the cluster
+	if sts.Status.ReadyReplicas != pointer.Int32Deref(sts.Spec.Replicas, 1) {
+		return r.checkUpgradeTimeout(pool, sts, nil)
+	}
+	var nodeNames []string
+	for i := int32(0); i < pointer.Int32Deref(sts.Spec.Replicas, 1); i++ {
+		nodeNames = append(nodeNames, builders.ReplicaHostName(*sts, i))
+	}
+	missingNodes, err := services.MissingNodes(r.osClient, nodeNames)
+	if err != nil {
+		return err
+	}
+	if len(missingNodes) > 0 {
+		return r.checkUpgradeTimeout(pool, sts, missingNodes)
+	}
 
 	dataCount := builders.DataNodesCount(r.ctx, r.Client, r.instance)
-	if dataCount == 2 && r.instance.Spec.General.DrainDataNodes {
+	if dataNode && dataCount == 2 && r.instance.Spec.General.DrainDataNodes {
 		lg.Info("only 2 data nodes and drain is set, some shards may not drain")
 	}
 
@@ -312,9 +433,21 @@ func (r *UpgradeReconciler) doDataNodeUpgrade(pool opsterv1.NodePool) error {
 		return nil
 	}
 
+	// Don't continue until the canary step has passed
+	canary := r.canaryEnabled()
+	canaryCondition := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionUpgradeCanary)
+	if canary && canaryCondition != nil && canaryCondition.Status != metav1.ConditionTrue {
+		return r.reconcileCanary(canaryCondition)
+	}
+
+	pendingPods, err := builders.PodsPendingUpdate(r.ctx, r.Client, sts)
+	if err != nil {
+		return err
+	}
+
 	// Work around for https://github.com/kubernetes/kubernetes/issues/73492
 	// If upgrade on this node pool is complete update status and return
-	if sts.Status.UpdatedReplicas == sts.Status.Replicas {
+	if sts.Status.UpdatedReplicas == sts.Status.Replicas || len(pendingPods) == 0 {
 		return retry.RetryOnConflict(retry.DefaultRetry, func() error {
 			if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
 				return err
@@ -330,36 +463,479 @@ func (r *UpgradeReconciler) doDataNodeUpgrade(pool opsterv1.NodePool) error {
 				Description: pool.Component,
 			}
 			r.instance.Status.ComponentsStatus = helpers.Replace(currentStatus, componentStatus, r.instance.Status.ComponentsStatus)
-			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Finished to upgrade data node pool %s", currentStatus.Component)
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Finished to upgrade node pool %s", pool.Component)
 			return r.Status().Update(r.ctx, r.instance)
 		})
 	}
 
-	workingPod := builders.WorkingPodForRollingRestart(sts)
-
-	ready, err = services.PreparePodForDelete(r.osClient, workingPod, r.instance.Spec.General.DrainDataNodes, dataCount)
-	if err != nil {
+	// Pods are only upgraded within a maintenance window, pods that are already upgrading finish first
+	deferred, err := deferToMaintenanceWindow(r.ctx, r.Client, r.instance, opsterv1.DeferredAction{
+		Action:   opsterv1.DeferredUpgrade,
+		NodePool: pool.Component,
+	})
+	if err != nil || deferred {
 		return err
 	}
-	if !ready {
+
+	// The elected cluster manager is upgraded last
+	if builders.IsClusterManagerSts(*sts) && len(pendingPods) > 1 {
+		electedClusterManager, err := services.GetElectedClusterManager(r.osClient)
+		if err != nil {
+			return err
+		}
+		if pendingPods[0] == electedClusterManager {
+			pendingPods = append(pendingPods[1:], pendingPods[0])
+		}
+	}
+
+	// Only a single pod is upgraded in the canary step
+	maxPods := r.maxConcurrentPods(pool, sts)
+	if canary && canaryCondition == nil {
+		maxPods = 1
+	}
+	workingPods := pendingPods[:1]
+	if maxPods > 1 {
+		zones, err := r.podZones(pool, pendingPods, sts.Namespace)
+		if err != nil {
+			return err
+		}
+		workingPods = zoneBatch(pendingPods, zones, maxPods)
+	}
+
+	if dataNode {
+		for _, workingPod := range workingPods {
+			ready, err = services.PreparePodForDelete(r.osClient, workingPod, r.instance.Spec.General.DrainDataNodes, dataCount)
+			if err != nil {
+				return err
+			}
+			if !ready {
+				return nil
+			}
+		}
+	}
+
+	for _, workingPod := range workingPods {
+		err = r.Delete(r.ctx, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      workingPod,
+				Namespace: sts.Namespace,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// If we are draining nodes remove the exclusion after the pod is deleted
+		if dataNode && r.instance.Spec.General.DrainDataNodes {
+			if _, err = services.RemoveExcludeNodeHost(r.osClient, workingPod); err != nil {
+				return err
+			}
+		}
+	}
+
+	if canary && canaryCondition == nil {
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Upgrading canary pod %s to %s", workingPods[0], r.instance.Spec.General.Version)
+		return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgradeCanary,
+			Status:  metav1.ConditionUnknown,
+			Reason:  "CanaryUpgrading",
+			Message: fmt.Sprintf("Upgrading canary pod %s", workingPods[0]),
+		})
+	}
+
+	return nil
+}
+
+func (r *UpgradeReconciler) canaryEnabled() bool {
+	strategy := r.instance.Spec.UpgradeStrategy
+	return strategy != nil && strategy.Canary != nil && strategy.Canary.Enable
+}
+
+// reconcileCanary moves the canary step forward. It is only called when the cluster is
+// healthy and all nodes of the working node pool are ready.
+func (r *UpgradeReconciler) reconcileCanary(condition *metav1.Condition) error {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	soakTime := r.instance.Spec.UpgradeStrategy.Canary.SoakTime
+
+	if condition.Status == metav1.ConditionUnknown {
+		// The canary pod is upgraded and has rejoined the cluster
+		reason := "CanaryWaitingForApproval"
+		message := fmt.Sprintf("Waiting for the %s annotation to be set to %s", opsterv1.UpgradeCanaryApprovalAnnotation, r.instance.Spec.General.Version)
+		if soakTime != nil {
+			reason = "CanarySoaking"
+			message = fmt.Sprintf("Waiting %s before continuing the upgrade", soakTime.Duration)
+		}
+		r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Canary pod upgraded to %s, %s", r.instance.Spec.General.Version, message)
+		return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgradeCanary,
+			Status:  metav1.ConditionFalse,
+			Reason:  reason,
+			Message: message,
+		})
+	}
+
+	approved := r.instance.Annotations[opsterv1.UpgradeCanaryApprovalAnnotation] == r.instance.Spec.General.Version
+	soaked := soakTime != nil && time.Since(condition.LastTransitionTime.Time) >= soakTime.Duration
+	if !approved && !soaked {
 		return nil
 	}
 
-	err = r.Delete(r.ctx, &corev1.Pod{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      workingPod,
-			Namespace: sts.Namespace,
-		},
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Canary step passed, continuing upgrade to %s", r.instance.Spec.General.Version)
+	return UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+		Type:    opsterv1.ConditionUpgradeCanary,
+		Status:  metav1.ConditionTrue,
+		Reason:  "CanaryPassed",
+		Message: "Canary step passed",
 	})
-	if err != nil {
-		return err
+}
+
+// maxConcurrentPods returns how many pods of the node pool can be upgraded at the same time.
+// Several pods are only upgraded at once if shard allocation awareness is configured, and only
+// if they are in the same zone, see zoneBatch.
+func (r *UpgradeReconciler) maxConcurrentPods(pool opsterv1.NodePool, sts *appsv1.StatefulSet) int {
+	strategy := r.instance.Spec.UpgradeStrategy
+	if strategy == nil || strategy.MaxConcurrentPods <= 1 || builders.IsClusterManagerSts(*sts) {
+		return 1
 	}
+	_, generalAwareness := r.instance.Spec.General.AdditionalConfig[allocationAwarenessSetting]
+	_, poolAwareness := pool.AdditionalConfig[allocationAwarenessSetting]
+	if !generalAwareness && !poolAwareness {
+		return 1
+	}
+	return int(strategy.MaxConcurrentPods)
+}
 
-	// If we are draining nodes remove the exclusion after the pod is deleted
-	if r.instance.Spec.General.DrainDataNodes {
-		_, err = services.RemoveExcludeNodeHost(r.osClient, workingPod)
-		return err
+// awarenessAttribute returns the first shard allocation awareness attribute of the node pool
+func (r *UpgradeReconciler) awarenessAttribute(pool opsterv1.NodePool) string {
+	attributes, ok := pool.AdditionalConfig[allocationAwarenessSetting]
+	if !ok {
+		attributes = r.instance.Spec.General.AdditionalConfig[allocationAwarenessSetting]
+	}
+	attribute := strings.Split(strings.Trim(attributes, "[] "), ",")[0]
+	return strings.Trim(attribute, "\"' ")
+}
+
+// podZones returns the value of the allocation awareness attribute of the pods. The value is taken from the
+// node.attr setting of the node pool if it is a constant, otherwise from the labels of the kubernetes node the pod
+// runs on, the attribute itself or the well-known zone label. Pods whose value can't be resolved are left out.
+func (r *UpgradeReconciler) podZones(pool opsterv1.NodePool, pods []string, namespace string) (map[string]string, error) {
+	zones := map[string]string{}
+	attribute := r.awarenessAttribute(pool)
+	if attribute == "" {
+		return zones, nil
+	}
+	nodeAttr := "node.attr." + attribute
+	value, ok := pool.AdditionalConfig[nodeAttr]
+	if !ok {
+		value = r.instance.Spec.General.AdditionalConfig[nodeAttr]
+	}
+	if value != "" && !strings.Contains(value, "${") {
+		for _, pod := range pods {
+			zones[pod] = value
+		}
+		return zones, nil
+	}
+
+	for _, podName := range pods {
+		pod := corev1.Pod{}
+		if err := r.Get(r.ctx, types.NamespacedName{Name: podName, Namespace: namespace}, &pod); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		node := corev1.Node{}
+		if err := r.Get(r.ctx, types.NamespacedName{Name: pod.Spec.NodeName}, &node); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if zone, ok := node.Labels[attribute]; ok {
+			zones[podName] = zone
+		} else if zone, ok := node.Labels[corev1.LabelTopologyZone]; ok {
+			zones[podName] = zone
+		}
+	}
+	return zones, nil
+}
+
+// zoneBatch returns the pods upgraded at the same time: the first pending pod and up to max-1 further pods in the
+// same zone. Replicas of a shard are allocated to different zones, so a batch never takes all copies of a shard
+// offline. If the zone of the first pod is unknown only that pod is upgraded.
+func zoneBatch(pendingPods []string, zones map[string]string, max int) []string {
+	batch := []string{pendingPods[0]}
+	zone := zones[pendingPods[0]]
+	if zone == "" {
+		return batch
+	}
+	for _, pod := range pendingPods[1:] {
+		if len(batch) >= max {
+			break
+		}
+		if zones[pod] == zone {
+			batch = append(batch, pod)
+		}
+	}
+	return batch
+}
+
+func (r *UpgradeReconciler) findFailedPool() (string, bool) {
+	for _, status := range r.instance.Status.ComponentsStatus {
+		if status.Component == "Upgrader" && status.Status == "Failed" {
+			return status.Description, true
+		}
+	}
+	return "", false
+}
+
+// retryFailedPool continues a failed upgrade with the newly requested version
+func (r *UpgradeReconciler) retryFailedPool(pool string) error {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Retrying failed upgrade of node pool %s with version %s", pool, r.instance.Spec.General.Version)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		failedStatus := opsterv1.ComponentStatus{
+			Component:   "Upgrader",
+			Status:      "Failed",
+			Description: pool,
+		}
+		componentStatus := opsterv1.ComponentStatus{
+			Component:   "Upgrader",
+			Status:      "Upgrading",
+			Description: pool,
+		}
+		r.instance.Status.ComponentsStatus = helpers.Replace(failedStatus, componentStatus, r.instance.Status.ComponentsStatus)
+		addUpgradeHistory(&r.instance.Status, r.instance.Spec.General.Version)
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+// startUpgradeHistory records the start of the upgrade in the upgrade history
+func (r *UpgradeReconciler) startUpgradeHistory() error {
+	latest := latestUpgrade(r.instance.Status)
+	if latest != nil && latest.Result == opsterv1.UpgradeInProgress && latest.ToVersion == r.instance.Spec.General.Version {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		addUpgradeHistory(&r.instance.Status, r.instance.Spec.General.Version)
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+func addUpgradeHistory(status *opsterv1.ClusterStatus, version string) {
+	status.UpgradeHistory = append(status.UpgradeHistory, opsterv1.UpgradeHistoryEntry{
+		FromVersion: status.Version,
+		ToVersion:   version,
+		StartTime:   metav1.Now(),
+		Result:      opsterv1.UpgradeInProgress,
+	})
+	if len(status.UpgradeHistory) > maxUpgradeHistory {
+		status.UpgradeHistory = status.UpgradeHistory[len(status.UpgradeHistory)-maxUpgradeHistory:]
+	}
+}
+
+func latestUpgrade(status opsterv1.ClusterStatus) *opsterv1.UpgradeHistoryEntry {
+	if len(status.UpgradeHistory) == 0 {
+		return nil
 	}
+	return &status.UpgradeHistory[len(status.UpgradeHistory)-1]
+}
 
+// finishLatestUpgrade sets the result of the latest upgrade, the caller has to update the status
+func finishLatestUpgrade(status *opsterv1.ClusterStatus, result string, reason string) {
+	latest := latestUpgrade(*status)
+	if latest == nil {
+		return
+	}
+	now := metav1.Now()
+	latest.Result = result
+	latest.Reason = reason
+	latest.EndTime = &now
+}
+
+// checkUpgradeTimeout marks the upgrade as failed if an upgraded pod didn't become ready
+// or didn't join the cluster within the upgrade timeout
+func (r *UpgradeReconciler) checkUpgradeTimeout(pool opsterv1.NodePool, sts *appsv1.StatefulSet, missingNodes []string) error {
+	timeout := defaultUpgradeTimeout
+	if r.instance.Spec.UpgradeStrategy != nil && r.instance.Spec.UpgradeStrategy.Timeout != nil {
+		timeout = r.instance.Spec.UpgradeStrategy.Timeout.Duration
+	}
+
+	for ordinal := int32(0); ordinal < pointer.Int32Deref(sts.Spec.Replicas, 1); ordinal++ {
+		pod := &corev1.Pod{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      builders.ReplicaHostName(*sts, ordinal),
+			Namespace: sts.Namespace,
+		}, pod); err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision ||
+			time.Since(pod.CreationTimestamp.Time) < timeout {
+			continue
+		}
+		if !isPodReady(pod) {
+			return r.markUpgradeFailed(pool, fmt.Sprintf("pod %s did not become ready within %s", pod.Name, timeout))
+		}
+		if helpers.ContainsString(missingNodes, pod.Name) {
+			return r.markUpgradeFailed(pool, fmt.Sprintf("node %s did not join the cluster within %s", pod.Name, timeout))
+		}
+	}
 	return nil
 }
+
+func (r *UpgradeReconciler) markUpgradeFailed(pool opsterv1.NodePool, reason string) error {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Upgrade", "Upgrade of node pool %s to %s failed: %s", pool.Component, r.instance.Spec.General.Version, reason)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		currentStatus := opsterv1.ComponentStatus{
+			Component:   "Upgrader",
+			Status:      "Upgrading",
+			Description: pool.Component,
+		}
+		componentStatus := opsterv1.ComponentStatus{
+			Component:   "Upgrader",
+			Status:      "Failed",
+			Description: pool.Component,
+		}
+		r.instance.Status.ComponentsStatus = helpers.Replace(currentStatus, componentStatus, r.instance.Status.ComponentsStatus)
+		finishLatestUpgrade(&r.instance.Status, opsterv1.UpgradeFailed, reason)
+		meta.SetStatusCondition(&r.instance.Status.Conditions, metav1.Condition{
+			Type:    opsterv1.ConditionUpgrading,
+			Status:  metav1.ConditionFalse,
+			Reason:  "Failed",
+			Message: fmt.Sprintf("Upgrade of node pool %s failed: %s", pool.Component, reason),
+		})
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+// reconcileRollback rolls back a failed or in progress upgrade after the version has been reverted.
+// Upgraded pods that are not ready are replaced right away, the remaining pods are rolled back
+// by the rolling restart once the upgrade status is cleared.
+func (r *UpgradeReconciler) reconcileRollback() (ctrl.Result, error) {
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+	version := r.instance.Spec.General.Version
+
+	latest := latestUpgrade(r.instance.Status)
+	if latest == nil || !rollbackAllowed(latest.ToVersion, version) {
+		attempted := "an unknown version"
+		if latest != nil {
+			attempted = latest.ToVersion
+		}
+		message := fmt.Sprintf("Rolling back from %s to %s is not supported, set the version to %s to continue the upgrade", attempted, version, attempted)
+		existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionUpgrading)
+		if existing == nil || existing.Reason != "RollbackNotSupported" {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "Upgrade", "%s", message)
+		}
+		return ctrl.Result{}, UpdateOpensearchCondition(r.ctx, r.Client, r.instance, metav1.Condition{
+			Type:    opsterv1.ConditionUpgrading,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RollbackNotSupported",
+			Message: message,
+		})
+	}
+
+	deleted := false
+	for _, nodePool := range r.instance.Spec.NodePools {
+		sts := &appsv1.StatefulSet{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      builders.StsName(r.instance, &nodePool),
+			Namespace: r.instance.Namespace,
+		}, sts); err != nil {
+			return ctrl.Result{}, err
+		}
+		// Wait for the statefulset controller to pick up the reverted version
+		if sts.Status.ObservedGeneration != sts.Generation {
+			return ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}, nil
+		}
+		for ordinal := int32(0); ordinal < pointer.Int32Deref(sts.Spec.Replicas, 1); ordinal++ {
+			pod := &corev1.Pod{}
+			if err := r.Get(r.ctx, types.NamespacedName{
+				Name:      builders.ReplicaHostName(*sts, ordinal),
+				Namespace: sts.Namespace,
+			}, pod); err != nil {
+				if k8serrors.IsNotFound(err) {
+					continue
+				}
+				return ctrl.Result{}, err
+			}
+			if pod.Labels[appsv1.ControllerRevisionHashLabelKey] != sts.Status.UpdateRevision && !isPodReady(pod) {
+				if err := r.Delete(r.ctx, pod); err != nil {
+					return ctrl.Result{}, err
+				}
+				deleted = true
+			}
+		}
+	}
+	if deleted {
+		return ctrl.Result{
+			Requeue:      true,
+			RequeueAfter: 15 * time.Second,
+		}, nil
+	}
+
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "Upgrade", "Rolling back upgrade from %s to %s", latest.ToVersion, version)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		var componentsStatus []opsterv1.ComponentStatus
+		for _, status := range r.instance.Status.ComponentsStatus {
+			if status.Component != "Upgrader" {
+				componentsStatus = append(componentsStatus, status)
+			}
+		}
+		r.instance.Status.ComponentsStatus = componentsStatus
+		finishLatestUpgrade(&r.instance.Status, opsterv1.UpgradeRolledBack, fmt.Sprintf("Rolled back to %s", version))
+		removeDeferredActions(&r.instance.Status, deferredActionType(opsterv1.DeferredUpgrade))
+		meta.RemoveStatusCondition(&r.instance.Status.Conditions, opsterv1.ConditionUpgradeCanary)
+		meta.SetStatusCondition(&r.instance.Status.Conditions, metav1.Condition{
+			Type:    opsterv1.ConditionUpgrading,
+			Status:  metav1.ConditionFalse,
+			Reason:  "RolledBack",
+			Message: fmt.Sprintf("Rolled back to %s", version),
+		})
+		return r.Status().Update(r.ctx, r.instance)
+	})
+	return ctrl.Result{}, err
+}
+
+// rollbackAllowed checks that the on-disk format of both versions is the same
+func rollbackAllowed(from string, to string) bool {
+	fromVersion, err := semver.NewVersion(from)
+	if err != nil {
+		return false
+	}
+	toVersion, err := semver.NewVersion(to)
+	if err != nil {
+		return false
+	}
+	return fromVersion.Major() == toVersion.Major() && fromVersion.Minor() == toVersion.Minor()
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
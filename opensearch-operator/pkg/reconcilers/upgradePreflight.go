@@ -0,0 +1,177 @@
+package reconcilers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/opensearch-gateway/services"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/helpers"
+)
+
+// deprecatedSettings maps the settings deprecated or removed in a major version to their replacement.
+// Keys ending with a dot match all settings with that prefix, an empty replacement means the setting
+// was removed without a replacement.
+var deprecatedSettings = map[int64]map[string]string{
+	1: {
+		"opendistro_security.": "plugins.security.",
+	},
+	2: {
+		"cluster.initial_master_nodes":       "cluster.initial_cluster_manager_nodes",
+		"cluster.no_master_block":            "cluster.no_cluster_manager_block",
+		"discovery.zen.hosts_provider":       "discovery.seed_providers",
+		"discovery.zen.minimum_master_nodes": "",
+		"discovery.zen.no_master_block":      "cluster.no_cluster_manager_block",
+		"discovery.zen.ping.unicast.hosts":   "discovery.seed_hosts",
+		"node.master":                        "node.roles",
+		"node.data":                          "node.roles",
+		"node.ingest":                        "node.roles",
+		"node.remote_cluster_client":         "node.roles",
+	},
+}
+
+// preflightChecks checks whether the cluster can be upgraded to the given version and
+// returns the reasons why it can't
+func (r *UpgradeReconciler) preflightChecks(version string) ([]string, error) {
+	target, err := semver.NewVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	var failures []string
+
+	if !r.skipPreflightCheck(opsterv1.PreflightCheckClusterHealth) {
+		health, err := r.osClient.GetHealth()
+		if err != nil {
+			return nil, err
+		}
+		if health.Status != "green" {
+			failures = append(failures, fmt.Sprintf("cluster health is %s", health.Status))
+		}
+	}
+
+	if !r.skipPreflightCheck(opsterv1.PreflightCheckIndexCompatibility) {
+		versions, err := services.GetIndicesCreatedVersion(r.osClient)
+		if err != nil {
+			return nil, err
+		}
+		var indices []string
+		for index := range versions {
+			indices = append(indices, index)
+		}
+		sort.Strings(indices)
+		for _, index := range indices {
+			if !indexReadable(versions[index], target) {
+				failures = append(failures, fmt.Sprintf("index %s was created with version %s and can't be read by version %s", index, versions[index], target))
+			}
+		}
+	}
+
+	if !r.skipPreflightCheck(opsterv1.PreflightCheckPlugins) {
+		var plugins []string
+		for _, nodePool := range r.instance.Spec.NodePools {
+			for _, plugin := range incompatiblePlugins(r.instance, &nodePool, version) {
+				if !helpers.ContainsString(plugins, plugin) {
+					plugins = append(plugins, plugin)
+				}
+			}
+		}
+		for _, plugin := range plugins {
+			failures = append(failures, fmt.Sprintf("plugin %s does not match version %s", plugin, version))
+		}
+	}
+
+	if !r.skipPreflightCheck(opsterv1.PreflightCheckDeprecatedSettings) {
+		configs := []map[string]string{r.instance.Spec.General.AdditionalConfig}
+		for _, nodePool := range r.instance.Spec.NodePools {
+			configs = append(configs, nodePool.AdditionalConfig)
+		}
+		found := map[string]string{}
+		var settings []string
+		for _, config := range configs {
+			for setting := range config {
+				if replacement, ok := deprecatedSetting(setting, target); ok {
+					if _, exists := found[setting]; !exists {
+						settings = append(settings, setting)
+					}
+					found[setting] = replacement
+				}
+			}
+		}
+		sort.Strings(settings)
+		for _, setting := range settings {
+			if found[setting] == "" {
+				failures = append(failures, fmt.Sprintf("setting %s is removed in version %s", setting, target))
+				continue
+			}
+			failures = append(failures, fmt.Sprintf("setting %s is deprecated in version %s, use %s instead", setting, target, found[setting]))
+		}
+	}
+
+	return failures, nil
+}
+
+func (r *UpgradeReconciler) skipPreflightCheck(check opsterv1.PreflightCheck) bool {
+	if r.instance.Spec.UpgradeStrategy == nil {
+		return false
+	}
+	for _, skipped := range r.instance.Spec.UpgradeStrategy.SkipPreflightChecks {
+		if skipped == check {
+			return true
+		}
+	}
+	return false
+}
+
+// indexReadable checks if an index created with the given version can be read by the target version.
+// OpenSearch can read indices created by the previous major version, indices created
+// by Elasticsearch 7.x are treated like indices created by OpenSearch 1.x.
+func indexReadable(created string, target *semver.Version) bool {
+	createdVersion, err := semver.NewVersion(created)
+	if err != nil {
+		// Don't block the upgrade on versions we don't understand
+		return true
+	}
+	major := createdVersion.Major()
+	if major >= 6 {
+		major = major - 6
+	}
+	return major >= target.Major()-1
+}
+
+// incompatiblePlugins returns the plugins installed on the node pool that are not built for the version
+func incompatiblePlugins(cr *opsterv1.OpenSearchCluster, nodePool *opsterv1.NodePool, version string) []string {
+	install, _ := builders.NodePoolPlugins(cr, nodePool)
+	var plugins []string
+	for _, plugin := range install {
+		if !pluginMatchesVersion(plugin, version) {
+			plugins = append(plugins, plugin)
+		}
+	}
+	return plugins
+}
+
+// pluginMatchesVersion checks that plugins installed from a URL or file are built for the version.
+// Plugins installed by name are resolved for the installed version.
+func pluginMatchesVersion(plugin string, version string) bool {
+	if !strings.Contains(plugin, "://") && !strings.HasSuffix(plugin, ".zip") {
+		return true
+	}
+	return strings.Contains(plugin, version)
+}
+
+func deprecatedSetting(setting string, target *semver.Version) (string, bool) {
+	for major, settings := range deprecatedSettings {
+		if major > target.Major() {
+			continue
+		}
+		for deprecated, replacement := range settings {
+			if setting == deprecated || (strings.HasSuffix(deprecated, ".") && strings.HasPrefix(setting, deprecated)) {
+				return replacement, true
+			}
+		}
+	}
+	return "", false
+}
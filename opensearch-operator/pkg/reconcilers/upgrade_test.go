@@ -0,0 +1,346 @@
+package reconcilers
+
+import (
+	"context"
+	"time"
+
+	"github.com/Masterminds/semver"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/builders"
+	"opensearch.opster.io/pkg/helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Upgrade Reconciler", func() {
+	newReconciler := func(spec *opsterv1.OpenSearchCluster) *UpgradeReconciler {
+		reconcilerContext := NewReconcilerContext(spec.Spec.NodePools)
+		return NewUpgradeReconciler(
+			k8sClient,
+			context.Background(),
+			&helpers.MockEventRecorder{},
+			&reconcilerContext,
+			spec,
+		)
+	}
+
+	newCluster := func() *opsterv1.OpenSearchCluster {
+		return &opsterv1.OpenSearchCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "upgrade-test",
+				Namespace: "upgrade-test",
+			},
+			Spec: opsterv1.ClusterSpec{
+				General: opsterv1.GeneralConfig{
+					Version: "2.0.0",
+				},
+				NodePools: []opsterv1.NodePool{
+					{
+						Component: "masters",
+						Roles:     []string{"cluster_manager"},
+					},
+					{
+						Component: "ingest",
+						Roles:     []string{"ingest"},
+					},
+					{
+						Component: "data",
+						Roles:     []string{"data"},
+					},
+				},
+			},
+			Status: opsterv1.ClusterStatus{
+				Version: "1.3.0",
+			},
+		}
+	}
+
+	Context("When finding the node pool to upgrade", func() {
+		It("should upgrade data nodes first and cluster manager nodes last", func() {
+			spec := newCluster()
+			underTest := newReconciler(spec)
+
+			var order []string
+			for {
+				pool, status := underTest.findWorkingNodePool()
+				if status.Status == "Finished" {
+					break
+				}
+				Expect(status.Status).To(Equal("Pending"))
+				order = append(order, pool.Component)
+				spec.Status.ComponentsStatus = append(spec.Status.ComponentsStatus, opsterv1.ComponentStatus{
+					Component:   "Upgrader",
+					Status:      "Upgraded",
+					Description: pool.Component,
+				})
+			}
+			Expect(order).To(Equal([]string{"data", "ingest", "masters"}))
+		})
+
+		It("should follow the configured pool order", func() {
+			spec := newCluster()
+			spec.Spec.UpgradeStrategy = &opsterv1.UpgradeStrategy{
+				PoolOrder: []string{"masters", "data"},
+			}
+			underTest := newReconciler(spec)
+
+			var order []string
+			for {
+				pool, status := underTest.findWorkingNodePool()
+				if status.Status == "Finished" {
+					break
+				}
+				order = append(order, pool.Component)
+				spec.Status.ComponentsStatus = append(spec.Status.ComponentsStatus, opsterv1.ComponentStatus{
+					Component:   "Upgrader",
+					Status:      "Upgraded",
+					Description: pool.Component,
+				})
+			}
+			Expect(order).To(Equal([]string{"masters", "data", "ingest"}))
+		})
+
+		It("should continue the in progress node pool", func() {
+			spec := newCluster()
+			spec.Status.ComponentsStatus = []opsterv1.ComponentStatus{
+				{
+					Component:   "Upgrader",
+					Status:      "Upgrading",
+					Description: "data",
+				},
+			}
+			underTest := newReconciler(spec)
+
+			pool, status := underTest.findWorkingNodePool()
+			Expect(pool.Component).To(Equal("data"))
+			Expect(status.Status).To(Equal("Upgrading"))
+		})
+	})
+
+	Context("When calculating the concurrent pods", func() {
+		It("should only upgrade multiple pods if allocation awareness is configured", func() {
+			spec := newCluster()
+			spec.Spec.UpgradeStrategy = &opsterv1.UpgradeStrategy{
+				MaxConcurrentPods: 3,
+			}
+			underTest := newReconciler(spec)
+			sts := &appsv1.StatefulSet{}
+
+			Expect(underTest.maxConcurrentPods(spec.Spec.NodePools[2], sts)).To(Equal(1))
+
+			spec.Spec.General.AdditionalConfig = map[string]string{
+				allocationAwarenessSetting: "zone",
+			}
+			Expect(underTest.maxConcurrentPods(spec.Spec.NodePools[2], sts)).To(Equal(3))
+		})
+
+		It("should upgrade cluster manager nodes one at a time", func() {
+			spec := newCluster()
+			spec.Spec.UpgradeStrategy = &opsterv1.UpgradeStrategy{
+				MaxConcurrentPods: 3,
+			}
+			spec.Spec.General.AdditionalConfig = map[string]string{
+				allocationAwarenessSetting: "zone",
+			}
+			underTest := newReconciler(spec)
+			sts := &appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						builders.NodeRoleLabel: "cluster_manager",
+					},
+				},
+			}
+
+			Expect(underTest.maxConcurrentPods(spec.Spec.NodePools[0], sts)).To(Equal(1))
+		})
+
+		It("should only upgrade pods of the same zone at once", func() {
+			pending := []string{"data-0", "data-1", "data-2", "data-3", "data-4"}
+			zones := map[string]string{
+				"data-0": "a",
+				"data-1": "b",
+				"data-2": "a",
+				"data-3": "b",
+				"data-4": "a",
+			}
+			Expect(zoneBatch(pending, zones, 2)).To(Equal([]string{"data-0", "data-2"}))
+			Expect(zoneBatch(pending, zones, 5)).To(Equal([]string{"data-0", "data-2", "data-4"}))
+			Expect(zoneBatch(pending[1:], zones, 5)).To(Equal([]string{"data-1", "data-3"}))
+			Expect(pending).To(Equal([]string{"data-0", "data-1", "data-2", "data-3", "data-4"}))
+		})
+
+		It("should upgrade a single pod if its zone is unknown", func() {
+			pending := []string{"data-0", "data-1", "data-2"}
+			Expect(zoneBatch(pending, map[string]string{"data-1": "a", "data-2": "a"}, 3)).To(Equal([]string{"data-0"}))
+			Expect(zoneBatch(pending, map[string]string{}, 3)).To(Equal([]string{"data-0"}))
+		})
+
+		It("should resolve the zone from the node attribute of the node pool", func() {
+			spec := newCluster()
+			spec.Spec.NodePools[2].AdditionalConfig = map[string]string{
+				allocationAwarenessSetting: "zone",
+				"node.attr.zone":           "a",
+			}
+			underTest := newReconciler(spec)
+			zones, err := underTest.podZones(spec.Spec.NodePools[2], []string{"data-0", "data-1"}, "upgrade-test")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(zones).To(Equal(map[string]string{"data-0": "a", "data-1": "a"}))
+		})
+	})
+
+	Context("When running the pre-flight checks", func() {
+		It("should detect indices the target version can't read", func() {
+			target := semver.MustParse("2.0.0")
+			Expect(indexReadable("1.3.0", target)).To(BeTrue())
+			Expect(indexReadable("7.10.2", target)).To(BeTrue())
+			Expect(indexReadable("6.8.0", target)).To(BeFalse())
+			Expect(indexReadable("1.3.0", semver.MustParse("3.0.0"))).To(BeFalse())
+		})
+
+		It("should detect plugins that don't match the target version", func() {
+			Expect(pluginMatchesVersion("repository-s3", "2.0.0")).To(BeTrue())
+			Expect(pluginMatchesVersion("https://example.com/plugin-2.0.0.zip", "2.0.0")).To(BeTrue())
+			Expect(pluginMatchesVersion("https://example.com/plugin-1.3.0.zip", "2.0.0")).To(BeFalse())
+		})
+
+		It("should detect pinned plugins of node pools that don't match the target version", func() {
+			spec := opsterv1.OpenSearchCluster{
+				Spec: opsterv1.ClusterSpec{
+					General: opsterv1.GeneralConfig{
+						Plugins: []opsterv1.Plugin{
+							{Name: "repository-s3", Version: "1.3.0"},
+							{Name: "analysis-icu"},
+						},
+					},
+					NodePools: []opsterv1.NodePool{
+						{
+							Component:     "masters",
+							RemovePlugins: []string{"repository-s3"},
+						},
+						{
+							Component: "data",
+							Plugins: []opsterv1.Plugin{
+								{URL: "https://example.com/plugin-2.0.0.zip"},
+							},
+						},
+					},
+				},
+			}
+			Expect(incompatiblePlugins(&spec, &spec.Spec.NodePools[0], "2.0.0")).To(BeEmpty())
+			Expect(incompatiblePlugins(&spec, &spec.Spec.NodePools[1], "2.0.0")).To(Equal([]string{
+				"https://artifacts.opensearch.org/releases/plugins/repository-s3/1.3.0/repository-s3-1.3.0.zip",
+			}))
+			Expect(incompatiblePlugins(&spec, &spec.Spec.NodePools[1], "1.3.0")).To(Equal([]string{
+				"https://example.com/plugin-2.0.0.zip",
+			}))
+		})
+
+		It("should detect deprecated settings", func() {
+			replacement, found := deprecatedSetting("discovery.zen.ping.unicast.hosts", semver.MustParse("2.0.0"))
+			Expect(found).To(BeTrue())
+			Expect(replacement).To(Equal("discovery.seed_hosts"))
+			replacement, found = deprecatedSetting("discovery.zen.minimum_master_nodes", semver.MustParse("2.0.0"))
+			Expect(found).To(BeTrue())
+			Expect(replacement).To(BeEmpty())
+			replacement, found = deprecatedSetting("opendistro_security.audit.type", semver.MustParse("2.0.0"))
+			Expect(found).To(BeTrue())
+			Expect(replacement).To(Equal("plugins.security."))
+			_, found = deprecatedSetting("node.master", semver.MustParse("1.3.0"))
+			Expect(found).To(BeFalse())
+			_, found = deprecatedSetting("cluster.routing.allocation.awareness.attributes", semver.MustParse("2.0.0"))
+			Expect(found).To(BeFalse())
+		})
+	})
+
+	Context("When handling a failed upgrade", func() {
+		It("should only allow rolling back within the same minor version", func() {
+			Expect(rollbackAllowed("2.1.1", "2.1.0")).To(BeTrue())
+			Expect(rollbackAllowed("2.1.0", "2.0.1")).To(BeFalse())
+			Expect(rollbackAllowed("2.0.0", "1.3.0")).To(BeFalse())
+			Expect(rollbackAllowed("invalid", "1.3.0")).To(BeFalse())
+		})
+
+		It("should keep a limited upgrade history", func() {
+			status := opsterv1.ClusterStatus{Version: "1.3.0"}
+			for i := 0; i < maxUpgradeHistory+2; i++ {
+				addUpgradeHistory(&status, "2.0.0")
+				finishLatestUpgrade(&status, opsterv1.UpgradeFailed, "pod did not become ready")
+			}
+			Expect(status.UpgradeHistory).To(HaveLen(maxUpgradeHistory))
+
+			addUpgradeHistory(&status, "2.0.1")
+			latest := latestUpgrade(status)
+			Expect(latest.FromVersion).To(Equal("1.3.0"))
+			Expect(latest.ToVersion).To(Equal("2.0.1"))
+			Expect(latest.Result).To(Equal(opsterv1.UpgradeInProgress))
+			Expect(latest.EndTime).To(BeNil())
+		})
+	})
+
+	Context("When following a version channel", func() {
+		versions := []string{"2.0.0", "2.0.1", "2.1.0", "2.1", "2.2.0-rc1", "latest", "3.0.0"}
+
+		It("should pick the newest version of the channel", func() {
+			Expect(channelVersion(versions, "2.0.x", "2.0.0", nil)).To(Equal("2.0.1"))
+			Expect(channelVersion(versions, "2.x", "2.0.0", nil)).To(Equal("2.1.0"))
+			Expect(channelVersion(versions, "2.x", "2.1.0", nil)).To(Equal(""))
+		})
+
+		It("should skip versions that failed to upgrade", func() {
+			Expect(channelVersion(versions, "2.x", "2.0.0", []string{"2.1.0"})).To(Equal("2.0.1"))
+		})
+
+		It("should reject an invalid channel", func() {
+			_, err := channelVersion(versions, "not a channel", "2.0.0", nil)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("When checking maintenance windows", func() {
+		windows := []opsterv1.MaintenanceWindow{
+			{
+				Schedule: "0 2 * * 6",
+				Duration: metav1.Duration{Duration: 2 * time.Hour},
+				Timezone: "Europe/Berlin",
+			},
+		}
+		// Saturday 3:30 in Berlin
+		saturday := time.Date(2022, 8, 6, 1, 30, 0, 0, time.UTC)
+
+		It("should allow all times without maintenance windows", func() {
+			Expect(inMaintenanceWindow(nil, saturday)).To(BeTrue())
+		})
+
+		It("should detect open maintenance windows", func() {
+			Expect(inMaintenanceWindow(windows, saturday)).To(BeTrue())
+			Expect(inMaintenanceWindow(windows, saturday.Add(time.Hour))).To(BeFalse())
+		})
+
+		It("should find the next maintenance window", func() {
+			next, err := nextMaintenanceWindow(windows, saturday)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(next.UTC()).To(Equal(time.Date(2022, 8, 13, 0, 0, 0, 0, time.UTC)))
+		})
+
+		It("should only remove the matching deferred actions", func() {
+			status := opsterv1.ClusterStatus{
+				Maintenance: &opsterv1.MaintenanceStatus{
+					DeferredActions: []opsterv1.DeferredAction{
+						{Action: opsterv1.DeferredRestart, NodePool: "data"},
+						{Action: opsterv1.DeferredScaleDown, NodePool: "data"},
+						{Action: opsterv1.DeferredScaleDown, NodePool: "ingest"},
+					},
+				},
+			}
+			removeDeferredActions(&status, deferredNodePoolAction(opsterv1.DeferredScaleDown, "data"))
+			Expect(status.Maintenance.DeferredActions).To(HaveLen(2))
+			removeDeferredActions(&status, deferredActionType(opsterv1.DeferredScaleDown))
+			Expect(status.Maintenance.DeferredActions).To(Equal([]opsterv1.DeferredAction{
+				{Action: opsterv1.DeferredRestart, NodePool: "data"},
+			}))
+		})
+	})
+})
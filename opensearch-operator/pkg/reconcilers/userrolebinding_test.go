@@ -43,7 +43,7 @@ var _ = Describe("userrolebinding reconciler", func() {
 				UID:       types.UID("testuid"),
 			},
 			Spec: opsterv1.OpensearchUserRoleBindingSpec{
-				OpensearchRef: corev1.LocalObjectReference{
+				OpensearchRef: &opsterv1.ClusterReference{
 					Name: "test-cluster",
 				},
 				Users: []string{
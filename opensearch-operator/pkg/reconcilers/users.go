@@ -23,12 +23,13 @@ import (
 type UserReconciler struct {
 	client.Client
 	ReconcilerOptions
-	ctx      context.Context
-	osClient *services.OsClusterClient
-	recorder record.EventRecorder
-	instance *opsterv1.OpensearchUser
-	cluster  *opsterv1.OpenSearchCluster
-	logger   logr.Logger
+	ctx        context.Context
+	osClient   *services.OsClusterClient
+	recorder   record.EventRecorder
+	instance   *opsterv1.OpensearchUser
+	cluster    *opsterv1.OpenSearchCluster
+	connection *opsterv1.OpensearchConnection
+	logger     logr.Logger
 }
 
 func NewUserReconciler(
@@ -82,30 +83,86 @@ func (r *UserReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
 		}
 	}()
 
-	r.cluster, retErr = util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
-		Name:      r.instance.Spec.OpensearchRef.Name,
-		Namespace: r.instance.Namespace,
-	})
-	if retErr != nil {
-		reason = "error fetching opensearch cluster"
-		r.logger.Error(retErr, "failed to fetch opensearch cluster")
+	var managedCluster types.UID
+	switch {
+	case (r.instance.Spec.OpensearchRef == nil) == (r.instance.Spec.OpensearchConnection == nil):
+		reason = "exactly one of opensearchCluster and opensearchConnection must be set"
+		retErr = fmt.Errorf("%s", reason)
 		r.recorder.Event(r.instance, "Warning", opensearchError, reason)
 		return
-	}
-	if r.cluster == nil {
-		r.logger.Info("opensearch cluster does not exist, requeueing")
-		reason = "waiting for opensearch cluster to exist"
-		r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
-		retResult = ctrl.Result{
-			Requeue:      true,
-			RequeueAfter: 10 * time.Second,
+	case r.instance.Spec.OpensearchConnection != nil:
+		r.connection, retErr = util.FetchOpensearchConnection(r.ctx, r.Client, types.NamespacedName{
+			Name:      r.instance.Spec.OpensearchConnection.Name,
+			Namespace: r.instance.Namespace,
+		})
+		if retErr != nil {
+			reason = "error fetching opensearch connection"
+			r.logger.Error(retErr, "failed to fetch opensearch connection")
+			r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+			return
 		}
-		return
+		if r.connection == nil {
+			r.logger.Info("opensearch connection does not exist, requeueing")
+			reason = "waiting for opensearch connection to exist"
+			r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
+			retResult = ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}
+			return
+		}
+		managedCluster = r.connection.UID
+	default:
+		r.cluster, retErr = util.FetchOpensearchCluster(r.ctx, r.Client, r.instance.Spec.OpensearchRef.NamespacedName(r.instance.Namespace))
+		if retErr != nil {
+			reason = "error fetching opensearch cluster"
+			r.logger.Error(retErr, "failed to fetch opensearch cluster")
+			r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+			return
+		}
+		if r.cluster == nil {
+			r.logger.Info("opensearch cluster does not exist, requeueing")
+			reason = "waiting for opensearch cluster to exist"
+			r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
+			retResult = ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 10 * time.Second,
+			}
+			return
+		}
+
+		// Clusters in other namespaces have to allow references from the namespace of the user
+		var allowed bool
+		allowed, retErr = util.ReferenceAllowed(r.ctx, r.Client, r.cluster, r.instance.Namespace)
+		if retErr != nil {
+			reason = "error checking the namespaces allowed to reference the opensearch cluster"
+			r.logger.Error(retErr, reason)
+			r.recorder.Event(r.instance, "Warning", opensearchError, reason)
+			return
+		}
+		if !allowed {
+			reason = fmt.Sprintf("opensearch cluster %s does not allow references from namespace %s", client.ObjectKeyFromObject(r.cluster), r.instance.Namespace)
+			retErr = fmt.Errorf("%s", reason)
+			r.recorder.Event(r.instance, "Warning", opensearchRefNotAllowed, reason)
+			return
+		}
+
+		// Don't touch the cluster while reconciliation is paused
+		if r.cluster.ReconcilePaused(opsterv1.PauseSecurity) {
+			r.logger.Info("opensearch cluster is paused, requeueing")
+			reason = ErrClusterPaused.Error()
+			retResult = ctrl.Result{
+				Requeue:      true,
+				RequeueAfter: 30 * time.Second,
+			}
+			return
+		}
+		managedCluster = r.cluster.UID
 	}
 
 	// Check cluster ref has not changed
 	if r.instance.Status.ManagedCluster != nil {
-		if *r.instance.Status.ManagedCluster != r.cluster.UID {
+		if *r.instance.Status.ManagedCluster != managedCluster {
 			reason = "cannot change the cluster a user refers to"
 			retErr = fmt.Errorf("%s", reason)
 			r.recorder.Event(r.instance, "Warning", opensearchRefMismatch, reason)
@@ -117,7 +174,7 @@ func (r *UserReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
 				if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
 					return err
 				}
-				r.instance.Status.ManagedCluster = &r.cluster.UID
+				r.instance.Status.ManagedCluster = &managedCluster
 				return r.Status().Update(r.ctx, r.instance)
 			})
 			if retErr != nil {
@@ -129,7 +186,7 @@ func (r *UserReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
 	}
 
 	// Check cluster is ready
-	if r.cluster.Status.Phase != opsterv1.PhaseRunning {
+	if r.cluster != nil && r.cluster.Status.Phase != opsterv1.PhaseRunning {
 		r.logger.Info("opensearch cluster is not running, requeueing")
 		reason = "waiting for opensearch cluster status to be running"
 		r.recorder.Event(r.instance, "Normal", opensearchPending, reason)
@@ -140,7 +197,11 @@ func (r *UserReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
 		return
 	}
 
-	r.osClient, retErr = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	if r.connection != nil {
+		r.osClient, retErr = util.CreateClientForConnection(r.ctx, r.Client, r.connection, r.osClientTransport)
+	} else {
+		r.osClient, retErr = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	}
 	if retErr != nil {
 		reason = "error creating opensearch client"
 		r.recorder.Event(r.instance, "Warning", opensearchError, reason)
@@ -194,20 +255,43 @@ func (r *UserReconciler) Reconcile() (retResult ctrl.Result, retErr error) {
 
 func (r *UserReconciler) Delete() error {
 	var err error
-	r.cluster, err = util.FetchOpensearchCluster(r.ctx, r.Client, types.NamespacedName{
-		Name:      r.instance.Spec.OpensearchRef.Name,
-		Namespace: r.instance.Namespace,
-	})
-	if err != nil {
-		return err
-	}
+	if r.instance.Spec.OpensearchConnection != nil {
+		r.connection, err = util.FetchOpensearchConnection(r.ctx, r.Client, types.NamespacedName{
+			Name:      r.instance.Spec.OpensearchConnection.Name,
+			Namespace: r.instance.Namespace,
+		})
+		if err != nil || r.connection == nil {
+			// Without the opensearch connection there is nothing we can delete
+			return err
+		}
+		r.osClient, err = util.CreateClientForConnection(r.ctx, r.Client, r.connection, r.osClientTransport)
+	} else if r.instance.Spec.OpensearchRef != nil {
+		r.cluster, err = util.FetchOpensearchCluster(r.ctx, r.Client, r.instance.Spec.OpensearchRef.NamespacedName(r.instance.Namespace))
+		if err != nil {
+			return err
+		}
 
-	if r.cluster == nil || !r.cluster.DeletionTimestamp.IsZero() {
-		// If the opensearch cluster doesn't exist, we don't need to delete anything
+		if r.cluster == nil || !r.cluster.DeletionTimestamp.IsZero() {
+			// If the opensearch cluster doesn't exist, we don't need to delete anything
+			return nil
+		}
+		// A user that was reconciled against the cluster is removed even if the cluster no longer allows
+		// references from its namespace
+		if r.instance.Status.ManagedCluster != nil {
+			if *r.instance.Status.ManagedCluster != r.cluster.UID {
+				return nil
+			}
+		} else if allowed, err := util.ReferenceAllowed(r.ctx, r.Client, r.cluster, r.instance.Namespace); err != nil || !allowed {
+			return err
+		}
+		if r.cluster.ReconcilePaused(opsterv1.PauseSecurity) {
+			return ErrClusterPaused
+		}
+
+		r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
+	} else {
 		return nil
 	}
-
-	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.cluster, r.osClientTransport)
 	if err != nil {
 		return err
 	}
@@ -44,7 +44,7 @@ var _ = Describe("users reconciler", func() {
 				UID:       types.UID("testuid"),
 			},
 			Spec: opsterv1.OpensearchUserSpec{
-				OpensearchRef: corev1.LocalObjectReference{
+				OpensearchRef: &opsterv1.ClusterReference{
 					Name: "test-cluster",
 				},
 				PasswordFrom: corev1.SecretKeySelector{
@@ -157,6 +157,28 @@ var _ = Describe("users reconciler", func() {
 			Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s waiting for opensearch cluster to exist", opensearchPending)))
 		})
 	})
+	When("cluster doesn't allow references from the namespace", func() {
+		BeforeEach(func() {
+			instance.Namespace = "test-user-other"
+			instance.Spec.OpensearchRef.Namespace = "test-user"
+			recorder = record.NewFakeRecorder(1)
+		})
+		It("should refuse the reference", func() {
+			go func() {
+				defer GinkgoRecover()
+				defer close(recorder.Events)
+				_, err := reconciler.Reconcile()
+				Expect(err).To(HaveOccurred())
+				Expect(transport.GetTotalCallCount()).To(Equal(0))
+			}()
+			var events []string
+			for msg := range recorder.Events {
+				events = append(events, msg)
+			}
+			Expect(len(events)).To(Equal(1))
+			Expect(events[0]).To(Equal(fmt.Sprintf("Warning %s opensearch cluster test-user/test-cluster does not allow references from namespace test-user-other", opensearchRefNotAllowed)))
+		})
+	})
 	When("cluster is not ready", func() {
 		BeforeEach(func() {
 			recorder = record.NewFakeRecorder(1)
@@ -177,6 +199,35 @@ var _ = Describe("users reconciler", func() {
 			Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s waiting for opensearch cluster status to be running", opensearchPending)))
 		})
 	})
+	When("cluster is paused", func() {
+		BeforeEach(func() {
+			recorder = record.NewFakeRecorder(1)
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			cluster.Annotations = map[string]string{
+				opsterv1.PauseReconcileAnnotation: opsterv1.PauseSecurity,
+			}
+			Expect(k8sClient.Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				return err == nil && cluster.ReconcilePaused(opsterv1.PauseSecurity)
+			}).Should(BeTrue())
+		})
+		AfterEach(func() {
+			Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			delete(cluster.Annotations, opsterv1.PauseReconcileAnnotation)
+			Expect(k8sClient.Update(context.Background(), cluster)).To(Succeed())
+			Eventually(func() bool {
+				err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)
+				return err == nil && !cluster.ReconcilePaused(opsterv1.PauseSecurity)
+			}).Should(BeTrue())
+		})
+		It("should not touch the cluster", func() {
+			result, err := reconciler.Reconcile()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeTrue())
+			Expect(transport.GetTotalCallCount()).To(Equal(0))
+		})
+	})
 	Context("cluster is ready", func() {
 		extraContextCalls := 1
 		BeforeEach(func() {
@@ -411,6 +462,110 @@ var _ = Describe("users reconciler", func() {
 			})
 		})
 	})
+	Context("external cluster", func() {
+		connectionURL := "https://opensearch.example.com:9200"
+		BeforeEach(func() {
+			instance.Spec.OpensearchRef = nil
+			instance.Spec.OpensearchConnection = &corev1.LocalObjectReference{
+				Name: "test-connection",
+			}
+		})
+
+		When("connection doesn't exist", func() {
+			BeforeEach(func() {
+				instance.Spec.OpensearchConnection.Name = "doesnotexist"
+				recorder = record.NewFakeRecorder(1)
+			})
+			It("should wait for the connection to exist", func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(recorder.Events)
+					result, err := reconciler.Reconcile()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result.Requeue).To(BeTrue())
+				}()
+				var events []string
+				for msg := range recorder.Events {
+					events = append(events, msg)
+				}
+				Expect(len(events)).To(Equal(1))
+				Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s waiting for opensearch connection to exist", opensearchPending)))
+			})
+		})
+		When("user does not exist", func() {
+			BeforeEach(func() {
+				recorder = record.NewFakeRecorder(1)
+				credentials := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-connection-credentials",
+						Namespace: "test-user",
+					},
+					StringData: map[string]string{
+						"username": "operator",
+						"password": "operatorpassword",
+					},
+				}
+				connection := &opsterv1.OpensearchConnection{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-connection",
+						Namespace: "test-user",
+					},
+					Spec: opsterv1.OpensearchConnectionSpec{
+						URL: connectionURL,
+						CredentialsSecret: &corev1.LocalObjectReference{
+							Name: "test-connection-credentials",
+						},
+					},
+				}
+				for _, obj := range []client.Object{credentials, connection} {
+					Expect(func() error {
+						err := k8sClient.Create(context.Background(), obj)
+						if k8serrors.IsAlreadyExists(err) {
+							return nil
+						}
+						return err
+					}()).To(Succeed())
+				}
+
+				transport.RegisterResponder(
+					http.MethodGet,
+					connectionURL+"/",
+					httpmock.NewStringResponder(200, "OK").Times(2, failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodHead,
+					connectionURL+"/",
+					httpmock.NewStringResponder(200, "OK").Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodGet,
+					fmt.Sprintf("%s/_plugins/_security/api/internalusers/%s", connectionURL, instance.Name),
+					httpmock.NewStringResponder(404, "does not exist").Once(failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodPut,
+					fmt.Sprintf("%s/_plugins/_security/api/internalusers/%s", connectionURL, instance.Name),
+					httpmock.NewStringResponder(200, "OK").Once(failMessage),
+				)
+			})
+			It("should create the user in the external cluster", func() {
+				go func() {
+					defer GinkgoRecover()
+					defer close(recorder.Events)
+					_, err := reconciler.Reconcile()
+					Expect(err).ToNot(HaveOccurred())
+					// Confirm all responders have been called
+					Expect(transport.GetTotalCallCount()).To(Equal(transport.NumResponders() + 1))
+				}()
+				var events []string
+				for msg := range recorder.Events {
+					events = append(events, msg)
+				}
+				Expect(len(events)).To(Equal(1))
+				Expect(events[0]).To(Equal(fmt.Sprintf("Normal %s user updated in opensearch", opensearchAPIUpdated)))
+			})
+		})
+	})
 	Context("deletions", func() {
 		extraContextCalls := 1
 
@@ -485,6 +640,46 @@ var _ = Describe("users reconciler", func() {
 				Expect(transport.GetTotalCallCount()).To(Equal(transport.NumResponders() + extraContextCalls + 1))
 			})
 		})
+		When("the cluster no longer allows references from the namespace of the user", func() {
+			BeforeEach(func() {
+				Expect(k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+				instance.Namespace = "test-user-other"
+				instance.Spec.OpensearchRef.Namespace = "test-user"
+				instance.Status.ManagedCluster = &cluster.UID
+				userRequest := requests.User{
+					Attributes: map[string]string{
+						services.K8sAttributeField: "testuid",
+					},
+				}
+				transport.RegisterResponder(
+					http.MethodGet,
+					fmt.Sprintf(
+						"https://%s.%s.svc.cluster.local:9200/_plugins/_security/api/internalusers/%s",
+						cluster.Spec.General.ServiceName,
+						cluster.Namespace,
+						instance.Name,
+					),
+					httpmock.NewJsonResponderOrPanic(200, responses.GetUserResponse{
+						instance.Name: userRequest,
+					}).Times(2, failMessage),
+				)
+				transport.RegisterResponder(
+					http.MethodDelete,
+					fmt.Sprintf(
+						"https://%s.%s.svc.cluster.local:9200/_plugins/_security/api/internalusers/%s",
+						cluster.Spec.General.ServiceName,
+						cluster.Namespace,
+						instance.Name,
+					),
+					httpmock.NewStringResponder(200, "OK").Once(failMessage),
+				)
+			})
+			It("should still delete the user it created", func() {
+				Expect(reconciler.Delete()).To(Succeed())
+				// Confirm all responders have been called
+				Expect(transport.GetTotalCallCount()).To(Equal(transport.NumResponders() + extraContextCalls + 1))
+			})
+		})
 		When("the user exists with correct UID", func() {
 			BeforeEach(func() {
 				userRequest := requests.User{
@@ -2,6 +2,8 @@ package util
 
 import (
 	"context"
+	cryptotls "crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
 	"sort"
@@ -10,6 +12,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kube-openapi/pkg/validation/errors"
 	opsterv1 "opensearch.opster.io/api/v1"
@@ -187,6 +190,17 @@ func OpensearchClusterURL(cluster *opsterv1.OpenSearchCluster) string {
 	)
 }
 
+// OpensearchDiscoveryURL returns the URL of the cluster through its headless discovery service. Unlike the cluster
+// service it also reaches nodes that are not ready yet, e.g. because the security index is not initialized
+func OpensearchDiscoveryURL(cluster *opsterv1.OpenSearchCluster) string {
+	return fmt.Sprintf(
+		"https://%s-discovery.%s.svc.cluster.local:%v",
+		cluster.Name,
+		cluster.Namespace,
+		cluster.Spec.General.HttpPort,
+	)
+}
+
 func CreateClientForCluster(
 	ctx context.Context,
 	k8sClient client.Client,
@@ -223,6 +237,143 @@ func CreateClientForCluster(
 	return osClient, err
 }
 
+// CreateAdminClientForCluster creates a client for a cluster that authenticates with the admin certificate of the
+// given secret. Unlike the credentials of CreateClientForCluster the certificate can modify all of the security config.
+// It connects through the discovery service to also reach nodes that are not ready yet.
+func CreateAdminClientForCluster(
+	ctx context.Context,
+	k8sClient client.Client,
+	cluster *opsterv1.OpenSearchCluster,
+	adminSecretName string,
+	transport http.RoundTripper,
+) (*services.OsClusterClient, error) {
+	lg := log.FromContext(ctx)
+	if transport == nil {
+		secret := &corev1.Secret{}
+		if err := k8sClient.Get(ctx, client.ObjectKey{Name: adminSecretName, Namespace: cluster.Namespace}, secret); err != nil {
+			return nil, err
+		}
+		cert, err := cryptotls.X509KeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("secret %s does not contain a valid admin certificate: %w", adminSecretName, err)
+		}
+		transport = &http.Transport{
+			TLSClientConfig: &cryptotls.Config{
+				InsecureSkipVerify: true,
+				Certificates:       []cryptotls.Certificate{cert},
+			},
+		}
+	}
+
+	osClient, err := services.NewOsClusterClient(
+		OpensearchDiscoveryURL(cluster),
+		"",
+		"",
+		services.WithTransport(transport),
+	)
+	if err != nil {
+		lg.Error(err, "failed to create client")
+	}
+	return osClient, err
+}
+
+// CreateClientForConnection creates a client for a cluster that is not managed by the operator
+func CreateClientForConnection(
+	ctx context.Context,
+	k8sClient client.Client,
+	connection *opsterv1.OpensearchConnection,
+	transport http.RoundTripper,
+) (*services.OsClusterClient, error) {
+	lg := log.FromContext(ctx)
+	spec := connection.Spec
+	if (spec.CredentialsSecret == nil) == (spec.ClientCertSecret == nil) {
+		return nil, fmt.Errorf("exactly one of credentialsSecret and clientCertSecret must be set")
+	}
+
+	var username, password string
+	if spec.CredentialsSecret != nil {
+		data, err := connectionSecretData(ctx, k8sClient, connection, spec.CredentialsSecret.Name, "username", "password")
+		if err != nil {
+			lg.Error(err, "failed to fetch opensearch credentials")
+			return nil, err
+		}
+		username, password = string(data["username"]), string(data["password"])
+	}
+
+	if transport == nil {
+		tlsConfig := &cryptotls.Config{}
+		if spec.CaSecret != nil {
+			data, err := connectionSecretData(ctx, k8sClient, connection, spec.CaSecret.Name, "ca.crt")
+			if err != nil {
+				return nil, err
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(data["ca.crt"]) {
+				return nil, fmt.Errorf("secret %s does not contain a valid CA certificate", spec.CaSecret.Name)
+			}
+		}
+		if spec.ClientCertSecret != nil {
+			data, err := connectionSecretData(ctx, k8sClient, connection, spec.ClientCertSecret.Name, corev1.TLSCertKey, corev1.TLSPrivateKeyKey)
+			if err != nil {
+				return nil, err
+			}
+			cert, err := cryptotls.X509KeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+			if err != nil {
+				return nil, fmt.Errorf("secret %s does not contain a valid client certificate: %w", spec.ClientCertSecret.Name, err)
+			}
+			tlsConfig.Certificates = []cryptotls.Certificate{cert}
+		}
+		transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+
+	osClient, err := services.NewOsClusterClient(
+		spec.URL,
+		username,
+		password,
+		services.WithTransport(transport),
+	)
+	if err != nil {
+		lg.Error(err, "failed to create client")
+	}
+	return osClient, err
+}
+
+// connectionSecretData returns the data of a secret referenced by the connection, all keys must exist
+func connectionSecretData(
+	ctx context.Context,
+	k8sClient client.Client,
+	connection *opsterv1.OpensearchConnection,
+	name string,
+	keys ...string,
+) (map[string][]byte, error) {
+	secret := &corev1.Secret{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: name, Namespace: connection.Namespace}, secret); err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; !ok {
+			return nil, fmt.Errorf("secret %s is missing the %s key", name, key)
+		}
+	}
+	return secret.Data, nil
+}
+
+func FetchOpensearchConnection(
+	ctx context.Context,
+	k8sClient client.Client,
+	ref types.NamespacedName,
+) (*opsterv1.OpensearchConnection, error) {
+	connection := &opsterv1.OpensearchConnection{}
+	err := k8sClient.Get(ctx, ref, connection)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return connection, nil
+}
+
 func FetchOpensearchCluster(
 	ctx context.Context,
 	k8sClient client.Client,
@@ -238,3 +389,35 @@ func FetchOpensearchCluster(
 	}
 	return cluster, nil
 }
+
+// ReferenceAllowed returns true if objects in the given namespace may reference the cluster. Objects in the
+// namespace of the cluster always may, other namespaces have to be allowed by spec.crossNamespaceReferences.
+func ReferenceAllowed(
+	ctx context.Context,
+	k8sClient client.Client,
+	cluster *opsterv1.OpenSearchCluster,
+	namespace string,
+) (bool, error) {
+	if namespace == cluster.Namespace {
+		return true, nil
+	}
+	refs := cluster.Spec.CrossNamespaceReferences
+	if refs == nil {
+		return false, nil
+	}
+	if helpers.ContainsString(refs.Namespaces, namespace) {
+		return true, nil
+	}
+	if refs.NamespaceSelector == nil {
+		return false, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(refs.NamespaceSelector)
+	if err != nil {
+		return false, err
+	}
+	ns := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(ns.Labels)), nil
+}
@@ -0,0 +1,267 @@
+package reconcilers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	opsterv1 "opensearch.opster.io/api/v1"
+	"opensearch.opster.io/pkg/helpers"
+	"opensearch.opster.io/pkg/reconcilers/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	versionCheckInterval = 10 * time.Minute
+	maxVersionUpdates    = 10
+)
+
+// reconcileVersionUpdate bumps the version of the cluster to the newest version of the version channel.
+// The upgrade itself is done by the upgrade reconciler once the version has been changed.
+func (r *UpgradeReconciler) reconcileVersionUpdate() (ctrl.Result, error) {
+	channel := r.instance.Spec.ConfMgmt.VersionChannel
+	if !r.instance.Spec.ConfMgmt.VerUpdate || channel == nil {
+		return ctrl.Result{}, nil
+	}
+	status := r.instance.Status.VersionUpdate
+	if status != nil && status.LastCheckTime != nil && time.Since(status.LastCheckTime.Time) < versionCheckInterval {
+		return ctrl.Result{}, nil
+	}
+	annotations := map[string]string{"cluster-name": r.instance.GetName()}
+
+	// Failures of the version check are only reported in the status, they must not block the other reconcilers
+	versions, err := r.availableVersions(channel)
+	if err != nil {
+		return ctrl.Result{}, r.updateVersionUpdateStatus("", metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "VersionsUnavailable",
+			Message: err.Error(),
+		})
+	}
+	version, err := channelVersion(versions, channel.Channel, r.instance.Spec.General.Version, r.failedVersions())
+	if err != nil {
+		return ctrl.Result{}, r.updateVersionUpdateStatus("", metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidChannel",
+			Message: err.Error(),
+		})
+	}
+	if version == "" {
+		return ctrl.Result{}, r.updateVersionUpdateStatus("", metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionTrue,
+			Reason:  "UpToDate",
+			Message: fmt.Sprintf("Version %s is the newest version of channel %s", r.instance.Spec.General.Version, channel.Channel),
+		})
+	}
+
+	now := time.Now()
+	inWindow, err := inMaintenanceWindow(r.instance.Spec.MaintenanceWindows, now)
+	if err != nil {
+		return ctrl.Result{}, r.updateVersionUpdateStatus(version, metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "InvalidMaintenanceWindow",
+			Message: err.Error(),
+		})
+	}
+	if !inWindow {
+		next, err := nextMaintenanceWindow(r.instance.Spec.MaintenanceWindows, now)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, r.updateVersionUpdateStatus(version, metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "WaitingForMaintenanceWindow",
+			Message: fmt.Sprintf("Version %s will be applied in the maintenance window starting at %s", version, next.UTC().Format(time.RFC3339)),
+		})
+	}
+
+	var failures []string
+	r.osClient, err = util.CreateClientForCluster(r.ctx, r.Client, r.instance, nil)
+	if err == nil {
+		failures, err = r.preflightChecks(version)
+	}
+	if err != nil {
+		return ctrl.Result{}, r.updateVersionUpdateStatus(version, metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "PreflightError",
+			Message: fmt.Sprintf("Failed to run the pre-flight checks for %s: %s", version, err),
+		})
+	}
+	if len(failures) > 0 {
+		message := fmt.Sprintf("Automatic update to %s blocked: %s", version, strings.Join(failures, "; "))
+		existing := meta.FindStatusCondition(r.instance.Status.Conditions, opsterv1.ConditionVersionUpdate)
+		if existing == nil || existing.Message != message {
+			r.recorder.AnnotatedEventf(r.instance, annotations, "Warning", "VersionUpdate", "%s", message)
+		}
+		return ctrl.Result{}, r.updateVersionUpdateStatus(version, metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionFalse,
+			Reason:  "PreflightFailed",
+			Message: message,
+		})
+	}
+
+	// Bump the version, the upgrade starts with the next reconcile
+	fromVersion := r.instance.Spec.General.Version
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		r.instance.Spec.General.Version = version
+		return r.Update(r.ctx, r.instance)
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.recorder.AnnotatedEventf(r.instance, annotations, "Normal", "VersionUpdate", "Automatically updating version from %s to %s", fromVersion, version)
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		setVersionUpdateStatus(&r.instance.Status, version, metav1.Condition{
+			Type:    opsterv1.ConditionVersionUpdate,
+			Status:  metav1.ConditionTrue,
+			Reason:  "Updated",
+			Message: fmt.Sprintf("Automatically updated version from %s to %s", fromVersion, version),
+		})
+		addVersionUpdate(r.instance.Status.VersionUpdate, fromVersion, version)
+		return r.Status().Update(r.ctx, r.instance)
+	})
+	return ctrl.Result{Requeue: true}, err
+}
+
+func (r *UpgradeReconciler) updateVersionUpdateStatus(version string, condition metav1.Condition) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		if err := r.Get(r.ctx, client.ObjectKeyFromObject(r.instance), r.instance); err != nil {
+			return err
+		}
+		setVersionUpdateStatus(&r.instance.Status, version, condition)
+		return r.Status().Update(r.ctx, r.instance)
+	})
+}
+
+func setVersionUpdateStatus(status *opsterv1.ClusterStatus, version string, condition metav1.Condition) {
+	if status.VersionUpdate == nil {
+		status.VersionUpdate = &opsterv1.VersionUpdateStatus{}
+	}
+	now := metav1.Now()
+	status.VersionUpdate.LastCheckTime = &now
+	status.VersionUpdate.AvailableVersion = version
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+func addVersionUpdate(status *opsterv1.VersionUpdateStatus, fromVersion string, toVersion string) {
+	status.Updates = append(status.Updates, opsterv1.VersionUpdateEntry{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Time:        metav1.Now(),
+	})
+	if len(status.Updates) > maxVersionUpdates {
+		status.Updates = status.Updates[len(status.Updates)-maxVersionUpdates:]
+	}
+}
+
+// failedVersions returns the versions that failed to upgrade so they are not retried automatically
+func (r *UpgradeReconciler) failedVersions() []string {
+	var versions []string
+	for _, entry := range r.instance.Status.UpgradeHistory {
+		if entry.Result == opsterv1.UpgradeFailed || entry.Result == opsterv1.UpgradeRolledBack {
+			versions = append(versions, entry.ToVersion)
+		}
+	}
+	return versions
+}
+
+// availableVersions collects the versions from the ConfigMap and the registry tag list of the channel
+func (r *UpgradeReconciler) availableVersions(channel *opsterv1.VersionChannel) ([]string, error) {
+	var versions []string
+	if channel.ConfigMap != nil {
+		configMap := &corev1.ConfigMap{}
+		if err := r.Get(r.ctx, types.NamespacedName{
+			Name:      channel.ConfigMap.Name,
+			Namespace: r.instance.Namespace,
+		}, configMap); err != nil {
+			return nil, err
+		}
+		data, ok := configMap.Data[channel.ConfigMap.Key]
+		if !ok {
+			return nil, fmt.Errorf("key %s not found in configmap %s", channel.ConfigMap.Key, channel.ConfigMap.Name)
+		}
+		versions = append(versions, strings.Fields(data)...)
+	}
+	if channel.TagsURL != "" {
+		tags, err := fetchRegistryTags(channel.TagsURL)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, tags...)
+	}
+	return versions, nil
+}
+
+func fetchRegistryTags(url string) ([]string, error) {
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	resp, err := httpClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch tags from %s: %s", url, resp.Status)
+	}
+	tagList := struct {
+		Tags []string `json:"tags"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&tagList); err != nil {
+		return nil, err
+	}
+	return tagList.Tags, nil
+}
+
+// channelVersion returns the newest released version of the channel that is newer than the current version.
+// Versions that are not fully qualified, like floating tags, are ignored.
+func channelVersion(versions []string, channel string, current string, excluded []string) (string, error) {
+	constraint, err := semver.NewConstraint(channel)
+	if err != nil {
+		return "", fmt.Errorf("invalid version channel %s: %w", channel, err)
+	}
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return "", err
+	}
+	var newest *semver.Version
+	for _, version := range versions {
+		if strings.Count(version, ".") != 2 {
+			continue
+		}
+		candidate, err := semver.NewVersion(version)
+		if err != nil || candidate.Prerelease() != "" {
+			continue
+		}
+		if !candidate.GreaterThan(currentVersion) || !constraint.Check(candidate) || helpers.ContainsString(excluded, version) {
+			continue
+		}
+		if newest == nil || candidate.GreaterThan(newest) {
+			newest = candidate
+		}
+	}
+	if newest == nil {
+		return "", nil
+	}
+	return newest.Original(), nil
+}